@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/services"
+)
+
+// adminContext is the context the admin commands run with: a trusted
+// caller named after the operator, which is what the audit log records
+func adminContext() context.Context {
+	name := "unknown"
+	if current, err := user.Current(); err == nil {
+		name = current.Username
+	}
+	return services.AsService(context.Background(), "cli:"+name)
+}
+
+// runAdmin runs a `user` or `followers` command. Passwords are read from
+// in, results are written to out.
+func runAdmin(ctx context.Context, svc *services.Services, args []string, in io.Reader, out io.Writer) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+
+	flags := flag.NewFlagSet(args[0]+" "+args[1], flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	jsonOutput := flags.Bool("json", false, "")
+	emit := func(v any, text string, a ...any) error {
+		if *jsonOutput {
+			return json.NewEncoder(out).Encode(v)
+		}
+		_, err := fmt.Fprintf(out, text+"\n", a...)
+		return err
+	}
+
+	switch args[0] + " " + args[1] {
+	case "user create":
+		var req models.RegisterRequest
+		flags.StringVar(&req.Username, "username", "", "")
+		flags.StringVar(&req.Email, "email", "", "")
+		flags.StringVar(&req.FirstName, "first-name", "", "")
+		flags.StringVar(&req.LastName, "last-name", "", "")
+		role := flags.String("role", models.RoleUser, "")
+		if err := parseArgs(flags, args[2:], 0); err != nil {
+			return err
+		}
+		password, err := readPassword(in)
+		if err != nil {
+			return err
+		}
+		req.Password = password
+
+		created, err := svc.Admin.CreateUser(ctx, req, *role)
+		if err != nil {
+			return err
+		}
+		return emit(newUserOutput(created), "Created user %d (%s) with role %s", created.ID, created.Username, created.Role)
+
+	case "user set-password":
+		if err := parseArgs(flags, args[2:], 1); err != nil {
+			return err
+		}
+		target, err := lookupUser(ctx, svc, flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		password, err := readPassword(in)
+		if err != nil {
+			return err
+		}
+		if err := svc.Admin.SetPassword(ctx, target.ID, password); err != nil {
+			return err
+		}
+		return emit(newUserOutput(target), "Set the password of user %d (%s)", target.ID, target.Username)
+
+	case "user set-role":
+		if err := parseArgs(flags, args[2:], 2); err != nil {
+			return err
+		}
+		target, err := lookupUser(ctx, svc, flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		updated, err := svc.Admin.SetRole(ctx, target.ID, flags.Arg(1))
+		if err != nil {
+			return err
+		}
+		return emit(newUserOutput(updated), "User %d (%s) now has role %s", updated.ID, updated.Username, updated.Role)
+
+	case "user disable":
+		if err := parseArgs(flags, args[2:], 1); err != nil {
+			return err
+		}
+		target, err := lookupUser(ctx, svc, flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		disabled, err := svc.Admin.DisableUser(ctx, target.ID)
+		if err != nil {
+			return err
+		}
+		return emit(newUserOutput(disabled), "Disabled user %d (%s)", disabled.ID, disabled.Username)
+
+	case "user restore":
+		if err := parseArgs(flags, args[2:], 1); err != nil {
+			return err
+		}
+		id, err := userID(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		restored, err := svc.Admin.RestoreUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		return emit(newUserOutput(restored), "Restored user %d (%s)", restored.ID, restored.Username)
+
+	case "user purge":
+		yes := flags.Bool("yes", false, "")
+		if err := parseArgs(flags, args[2:], 1); err != nil {
+			return err
+		}
+		id, err := userID(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		if !*yes {
+			return fmt.Errorf("purging user %d can't be undone, confirm with --yes", id)
+		}
+		if err := svc.Admin.PurgeUser(ctx, id); err != nil {
+			return err
+		}
+		return emit(map[string]uint{"purged": id}, "Purged user %d", id)
+
+	case "user erase":
+		yes := flags.Bool("yes", false, "")
+		if err := parseArgs(flags, args[2:], 1); err != nil {
+			return err
+		}
+		id, err := userID(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		if !*yes {
+			return fmt.Errorf("erasing the personal data of user %d can't be undone, confirm with --yes", id)
+		}
+		receipt, err := svc.Admin.EraseUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		return emit(receipt, "Erased user %d (receipt %d, %d follow edges removed)", receipt.UserID, receipt.ID, receipt.FollowEdges)
+
+	case "user purge-expired":
+		if err := parseArgs(flags, args[2:], 0); err != nil {
+			return err
+		}
+		purged, err := svc.Admin.PurgeDeletedUsers(ctx)
+		if err != nil {
+			return err
+		}
+		return emit(map[string]int{"purged": purged}, "Purged %d deleted users past the grace period", purged)
+
+	case "followers rebuild-counts":
+		if err := parseArgs(flags, args[2:], 0); err != nil {
+			return err
+		}
+		removed, err := svc.Admin.RebuildFollowerCounts(ctx)
+		if err != nil {
+			return err
+		}
+		return emit(map[string]int{"removedEdges": removed}, "Removed %d follow edges of deleted users", removed)
+
+	default:
+		return errUsage
+	}
+}
+
+// parseArgs parses flags placed anywhere among args and checks that there
+// are exactly n positional arguments, left in flags.Args()
+func parseArgs(flags *flag.FlagSet, args []string, n int) error {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return fmt.Errorf("%s: %w", flags.Name(), err)
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	if len(positional) != n {
+		return errUsage
+	}
+
+	// Leave the positional arguments where flags.Arg finds them
+	return flags.Parse(append([]string{"--"}, positional...))
+}
+
+// userID parses the ID of a user. Deleted users have no username to look
+// them up by.
+func userID(arg string) (uint, error) {
+	id, err := strconv.ParseUint(arg, 10, 0)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("invalid user ID %q", arg)
+	}
+	return uint(id), nil
+}
+
+// lookupUser finds a live user by ID or username
+func lookupUser(ctx context.Context, svc *services.Services, arg string) (*models.User, error) {
+	if id, err := strconv.ParseUint(arg, 10, 0); err == nil {
+		return svc.Users.GetUser(ctx, uint(id))
+	}
+	return svc.Users.GetUserByUsername(ctx, arg)
+}
+
+// readPassword reads a password from the first line of in, so it never
+// shows up in the shell history or the process list
+func readPassword(in io.Reader) (string, error) {
+	if f, ok := in.(*os.File); ok {
+		if stat, err := f.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 {
+			fmt.Fprint(os.Stderr, "Password: ")
+		}
+	}
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("no password on stdin")
+	}
+	return password, nil
+}
+
+// userOutput is a user as the admin commands print it
+type userOutput struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"firstName"`
+	LastName  string    `json:"lastName"`
+	Role      string    `json:"role"`
+	Disabled  bool      `json:"disabled"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func newUserOutput(user *models.User) userOutput {
+	return userOutput{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Role:      user.Role,
+		Disabled:  user.DisabledAt != nil,
+		CreatedAt: user.CreatedAt,
+	}
+}
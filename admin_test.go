@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antoniocfetngnu/users-api/config"
+	"github.com/antoniocfetngnu/users-api/middleware"
+	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/repository"
+	"github.com/antoniocfetngnu/users-api/services"
+	"github.com/antoniocfetngnu/users-api/utils"
+)
+
+func TestAdminCommands(t *testing.T) {
+	utils.InitJWT(&config.Config{JWTSecret: "admin-secret"})
+	for _, store := range testStores {
+		t.Run(store.name, func(t *testing.T) {
+			testAdminCommands(t, store.newStore(t))
+		})
+	}
+}
+
+func testAdminCommands(t *testing.T, store repository.Store) {
+	svc := services.New(store, services.Config{})
+	ctx := services.AsService(context.Background(), "cli:tests")
+
+	// run runs a command with stdin and returns what it printed
+	run := func(stdin string, args ...string) (string, error) {
+		var out bytes.Buffer
+		err := runAdmin(ctx, svc, args, strings.NewReader(stdin), &out)
+		return out.String(), err
+	}
+	mustRun := func(stdin string, args ...string) string {
+		t.Helper()
+		out, err := run(stdin, args...)
+		if err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		return out
+	}
+	login := func(username, password string) error {
+		_, _, err := svc.Users.Login(ctx, models.LoginRequest{Username: username, Password: password})
+		return err
+	}
+
+	out := mustRun("secret123\n", "user", "create", "--username", "root", "--email", "root@example.com", "--first-name", "Root", "--last-name", "Admin", "--role", "admin", "--json")
+	var created userOutput
+	if err := json.Unmarshal([]byte(out), &created); err != nil || created.ID == 0 || created.Role != models.RoleAdmin {
+		t.Fatalf("user create = %q, %v", out, err)
+	}
+	if err := login("root", "secret123"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run("secret123\n", "user", "create", "--username", "x", "--email", "x@example.com", "--first-name", "X", "--last-name", "X", "--role", "owner"); services.KindOf(err) != services.KindInvalid {
+		t.Fatalf("user create with an unknown role = %v", err)
+	}
+
+	// Passwords follow the same rule as in the API
+	if _, err := run("short\n", "user", "set-password", "root"); services.KindOf(err) != services.KindInvalid {
+		t.Fatalf("user set-password with a short password = %v", err)
+	}
+
+	// Flags go anywhere, users are found by ID or username
+	if out := mustRun("newsecret\n", "user", "set-password", "root"); !strings.Contains(out, "Set the password") {
+		t.Fatalf("user set-password = %q", out)
+	}
+	if err := login("root", "newsecret"); err != nil {
+		t.Fatal(err)
+	}
+	out = mustRun("", "user", "set-role", "--json", "1", "user")
+	if !strings.Contains(out, `"role":"user"`) {
+		t.Fatalf("user set-role = %q", out)
+	}
+
+	mustRun("", "user", "disable", "root")
+	if err := login("root", "newsecret"); !errors.Is(err, services.ErrAccountDisabled) {
+		t.Fatalf("login of a disabled user = %v", err)
+	}
+	mustRun("", "user", "restore", "1")
+	if err := login("root", "newsecret"); err != nil {
+		t.Fatalf("login of a restored user = %v", err)
+	}
+
+	// Deleted users are restored by ID
+	if err := svc.Users.DeleteUser(ctx, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run("", "user", "restore", "root"); err == nil {
+		t.Fatal("user restore by username succeeded")
+	}
+	mustRun("", "user", "restore", "1")
+	if _, err := svc.Users.GetUser(ctx, created.ID); err != nil {
+		t.Fatalf("GetUser of a restored user = %v", err)
+	}
+
+	// Users deleted before deletes removed their follow edges left them
+	// behind, rebuild-counts drops them. Purge drops the user's own.
+	other, err := svc.Admin.CreateUser(ctx, models.RegisterRequest{FirstName: "B", LastName: "B", Email: "b@example.com", Username: "bob", Password: "secret123"}, models.RoleUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := svc.Follows.Follow(ctx, other.ID, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Users().Delete(ctx, other.ID); err != nil {
+		t.Fatal(err)
+	}
+	if out := mustRun("", "followers", "rebuild-counts", "--json"); out != `{"removedEdges":1}`+"\n" {
+		t.Fatalf("followers rebuild-counts = %q", out)
+	}
+	if counts, _ := svc.Follows.CountFollowersByUsers(ctx, []uint{created.ID}); counts[created.ID] != 0 {
+		t.Fatalf("follower count after rebuild = %d", counts[created.ID])
+	}
+
+	if _, err := run("", "user", "purge", "1"); err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Fatalf("user purge without --yes = %v", err)
+	}
+	mustRun("", "user", "purge", "1", "--yes")
+	if _, err := store.Users().GetWithDeleted(ctx, created.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("purged user = %v", err)
+	}
+
+	// Erased users keep their ID, for good
+	if _, err := run("", "user", "erase", fmt.Sprint(other.ID)); err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Fatalf("user erase without --yes = %v", err)
+	}
+	out = mustRun("", "user", "erase", "--yes", "--json", fmt.Sprint(other.ID))
+	var receipt models.ErasureReceipt
+	if err := json.Unmarshal([]byte(out), &receipt); err != nil || receipt.UserID != other.ID || receipt.Actor != "service:cli:tests" {
+		t.Fatalf("user erase = %q, %v", out, err)
+	}
+	for _, args := range [][]string{{"erase", "--yes"}, {"restore"}, {"purge", "--yes"}} {
+		args = append([]string{"user", args[0], fmt.Sprint(other.ID)}, args[1:]...)
+		if _, err := run("", args...); !errors.Is(err, services.ErrAccountErased) {
+			t.Fatalf("%v of an erased user = %v", args, err)
+		}
+	}
+	if erased, err := store.Users().GetWithDeleted(ctx, other.ID); err != nil || erased.Username != models.TombstoneUsername(other.ID) || erased.Email != "" {
+		t.Fatalf("erased user = %+v, %v", erased, err)
+	}
+
+	// Nothing was deleted long enough ago
+	if out := mustRun("", "user", "purge-expired", "--json"); out != `{"purged":0}`+"\n" {
+		t.Fatalf("user purge-expired = %q", out)
+	}
+
+	// Every change was audited
+	entries, err := store.AuditLog().ListByUser(ctx, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, entry := range entries {
+		if entry.Actor != "service:cli:tests" {
+			t.Errorf("entry %d by %q", entry.ID, entry.Actor)
+		}
+		actions = append(actions, entry.Action)
+	}
+	want := []string{
+		models.AuditUserCreate, models.AuditUserSetPassword, models.AuditUserSetRole, models.AuditUserDisable,
+		models.AuditUserRestore, models.AuditUserRestore, models.AuditUserPurge,
+	}
+	if strings.Join(actions, " ") != strings.Join(want, " ") {
+		t.Fatalf("audit log = %v, want %v", actions, want)
+	}
+
+	if _, err := run("", "user", "frobnicate"); !errors.Is(err, errUsage) {
+		t.Fatalf("unknown command = %v", err)
+	}
+}
+
+func TestPurgeDeletedUsers(t *testing.T) {
+	for _, store := range testStores {
+		t.Run(store.name, func(t *testing.T) {
+			store := store.newStore(t)
+			svc := services.New(store, services.Config{DeletionGracePeriod: time.Hour})
+			ctx := services.AsService(context.Background(), "purger")
+
+			var ids []uint
+			for _, username := range []string{"alice", "bob", "carol"} {
+				user, err := svc.Admin.CreateUser(ctx, models.RegisterRequest{FirstName: "A", LastName: "A", Email: username + "@example.com", Username: username, Password: "secret123"}, models.RoleUser)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ids = append(ids, user.ID)
+			}
+			if _, _, err := svc.Follows.Follow(ctx, ids[2], ids[0]); err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range ids[:2] {
+				if err := svc.Users.DeleteUser(ctx, id); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Within the grace period nothing goes
+			if purged, err := svc.Admin.PurgeDeletedUsers(ctx); err != nil || purged != 0 {
+				t.Fatalf("PurgeDeletedUsers = %d, %v", purged, err)
+			}
+
+			later := services.New(store, services.Config{DeletionGracePeriod: -time.Minute})
+			if purged, err := later.Admin.PurgeDeletedUsers(ctx); err != nil || purged != 2 {
+				t.Fatalf("PurgeDeletedUsers after the grace period = %d, %v", purged, err)
+			}
+			for _, id := range ids[:2] {
+				if _, err := store.Users().GetWithDeleted(ctx, id); !errors.Is(err, repository.ErrNotFound) {
+					t.Fatalf("purged user %d = %v", id, err)
+				}
+			}
+			if _, err := store.Users().Get(ctx, ids[2]); err != nil {
+				t.Fatalf("live user = %v", err)
+			}
+			entries, err := store.AuditLog().ListByUser(ctx, ids[0])
+			if err != nil || len(entries) == 0 || entries[len(entries)-1].Action != models.AuditUserPurge || entries[len(entries)-1].Actor != "service:purger" {
+				t.Fatalf("audit log = %+v, %v", entries, err)
+			}
+		})
+	}
+}
+
+// racingStore purges the users it lists as deleted before returning them,
+// like another replica running PurgeDeletedUsers first
+type racingStore struct {
+	repository.Store
+}
+
+func (s racingStore) Users() repository.UserRepository {
+	return racingUsers{s.Store.Users()}
+}
+
+type racingUsers struct {
+	repository.UserRepository
+}
+
+func (r racingUsers) ListDeletedBefore(ctx context.Context, before time.Time, limit int) ([]*models.User, error) {
+	users, err := r.UserRepository.ListDeletedBefore(ctx, before, limit)
+	for _, user := range users {
+		if err := r.Purge(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+	return users, err
+}
+
+func TestPurgeDeletedUsersConcurrently(t *testing.T) {
+	for _, store := range testStores {
+		t.Run(store.name, func(t *testing.T) {
+			store := store.newStore(t)
+			svc := services.New(racingStore{store}, services.Config{DeletionGracePeriod: -time.Minute})
+			ctx := services.AsService(context.Background(), "purger")
+
+			user, err := svc.Admin.CreateUser(ctx, models.RegisterRequest{FirstName: "A", LastName: "A", Email: "alice@example.com", Username: "alice", Password: "secret123"}, models.RoleUser)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := svc.Users.DeleteUser(ctx, user.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			// The other replica purged it, this one leaves no trace
+			if purged, err := svc.Admin.PurgeDeletedUsers(ctx); err != nil || purged != 0 {
+				t.Fatalf("PurgeDeletedUsers = %d, %v", purged, err)
+			}
+			entries, err := store.AuditLog().ListByUser(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range entries {
+				if entry.Action == models.AuditUserPurge {
+					t.Fatalf("audit entry %+v for a user purged elsewhere", entry)
+				}
+			}
+		})
+	}
+}
+
+func TestAdminServiceRequiresAdmin(t *testing.T) {
+	svc := services.New(repository.NewMemoryStore(), services.Config{})
+	ctx := middleware.WithPrincipal(context.Background(), &middleware.Principal{UserID: 1, Role: models.RoleUser})
+
+	if _, err := svc.Admin.RebuildFollowerCounts(ctx); !errors.Is(err, services.ErrAdminOnly) {
+		t.Fatalf("RebuildFollowerCounts as a user = %v", err)
+	}
+	if _, err := svc.Admin.RebuildFollowerCounts(context.Background()); !errors.Is(err, services.ErrUnauthenticated) {
+		t.Fatalf("RebuildFollowerCounts without a caller = %v", err)
+	}
+}
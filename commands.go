@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"gorm.io/gorm/logger"
+
+	"github.com/antoniocfetngnu/users-api/config"
+	"github.com/antoniocfetngnu/users-api/database"
+)
+
+const usage = `usage: users-api [command]
+
+Without a command, runs the server.
+
+commands:
+  migrate up                apply every pending migration
+  migrate down [n]          revert the last n migrations (1)
+  migrate to <version>      apply or revert migrations up to version (0 reverts all)
+  migrate status            list migrations and when they were applied
+
+  user create --username U --email E --first-name F --last-name L [--role user|admin]
+  user set-password <id|username>
+  user set-role <id|username> <user|admin>
+  user disable <id|username>
+  user restore <id>         undelete or re-enable an account
+  user purge <id> --yes     remove an account and its follow edges for good
+  user erase <id> --yes     erase the personal data of an account for good, keeping its ID
+  user purge-expired        purge the accounts deleted longer than DELETION_GRACE_PERIOD ago
+  followers rebuild-counts  remove the follow edges of deleted users
+
+Passwords are read from stdin. The user and followers commands print JSON
+with --json, and are written to the audit log.`
+
+var errUsage = errors.New(usage)
+
+// runCommand runs one of the binary's commands other than the server
+func runCommand(cfg *config.Config, args []string) error {
+	switch args[0] {
+	case "migrate":
+		return runMigrate(cfg, args[1:])
+	case "user", "followers":
+		if err := database.Connect(cfg); err != nil {
+			return err
+		}
+		// GORM logs to stdout, where scripts read the --json output
+		database.DB.Logger = logger.Discard
+		svc := newServices(cfg)
+		return runAdmin(adminContext(), svc, args, os.Stdin, os.Stdout)
+	case "help", "-h", "--help":
+		fmt.Println(usage)
+		return nil
+	default:
+		return errUsage
+	}
+}
+
+func runMigrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	// Open, not Connect: the schema is what this command changes
+	if err := database.Open(cfg); err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		return database.MigrateUp(ctx, database.DB)
+
+	case args[0] == "down" && len(args) <= 2:
+		steps := 1
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("migrate down: invalid number of migrations %q", args[1])
+			}
+			steps = n
+		}
+		return database.MigrateDown(ctx, database.DB, steps)
+
+	case args[0] == "to" && len(args) == 2:
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("migrate to: invalid version %q", args[1])
+		}
+		return database.MigrateTo(ctx, database.DB, version)
+
+	case args[0] == "status" && len(args) == 1:
+		statuses, err := database.GetMigrationStatus(ctx, database.DB)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+
+	default:
+		return errUsage
+	}
+}
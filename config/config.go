@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,6 +14,66 @@ type Config struct {
 	JWTSecret   string
 	Port        string
 	Environment string
+
+	// What Connect does with pending schema migrations: "up" applies them,
+	// "verify" refuses to start, "off" ignores them
+	DatabaseMigrations string
+
+	// How long a deleted account can be restored before it is purged, and
+	// how often each replica looks for accounts to purge and expired
+	// idempotency keys to forget (0 disables it)
+	DeletionGracePeriod time.Duration
+	PurgeInterval       time.Duration
+
+	// How long in-flight requests get to finish on SIGTERM
+	ShutdownTimeout time.Duration
+
+	// Browser origins allowed by CORS and the GraphQL WebSocket handshake
+	AllowedOrigins []string
+
+	// GraphQL query limits (0 disables a limit)
+	GraphQLMaxDepth      int
+	GraphQLMaxComplexity int
+
+	// Query cost each user may spend per GraphQLCostWindow (0 disables it)
+	GraphQLCostBudget int
+	GraphQLCostWindow time.Duration
+
+	// Size of the automatic persisted query (APQ) cache
+	GraphQLAPQCacheSize int
+
+	// Manifest of approved GraphQL documents. When set, the server only
+	// runs these documents and the playground is disabled.
+	GraphQLPersistedQueries string
+
+	GRPCPort string
+
+	// Exposes the gRPC reflection service (for grpcurl and friends)
+	GRPCReflection bool
+
+	// Service tokens accepted by the gRPC server, by service name
+	GRPCServiceTokens map[string]string
+
+	// RPCs each caller may use ("*" for all), by service name or "user"
+	// for end users. Empty allows every authenticated caller.
+	GRPCServiceACL map[string][]string
+
+	// How long gRPC write responses are kept for retries with the same
+	// idempotency key, and how long a call holds its key before a retry may
+	// take it over
+	GRPCIdempotencyTTL   time.Duration
+	GRPCIdempotencyLease time.Duration
+
+	// gRPC TLS certificate and key. With GRPCClientCA, clients must present
+	// a certificate signed by it (mutual TLS).
+	GRPCTLSCert  string
+	GRPCTLSKey   string
+	GRPCClientCA string
+}
+
+// GraphQLAllowlistOnly reports whether ad-hoc GraphQL queries are rejected
+func (c *Config) GraphQLAllowlistOnly() bool {
+	return c.GraphQLPersistedQueries != ""
 }
 
 func LoadConfig() *Config {
@@ -22,6 +85,33 @@ func LoadConfig() *Config {
 		JWTSecret:   getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-in-production"),
 		Port:        getEnv("PORT", "3001"),
 		Environment: getEnv("ENVIRONMENT", "development"),
+
+		DatabaseMigrations: getEnv("DB_MIGRATIONS", "up"),
+
+		DeletionGracePeriod: getEnvDuration("DELETION_GRACE_PERIOD", 30*24*time.Hour),
+		PurgeInterval:       getEnvDuration("PURGE_INTERVAL", time.Hour),
+
+		ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+
+		AllowedOrigins: strings.Split(getEnv("ALLOWED_ORIGINS", "http://localhost:5173,http://localhost:8000"), ","),
+
+		GraphQLMaxDepth:      getEnvInt("GRAPHQL_MAX_DEPTH", 10),
+		GraphQLMaxComplexity: getEnvInt("GRAPHQL_MAX_COMPLEXITY", 10000),
+		GraphQLCostBudget:    getEnvInt("GRAPHQL_COST_BUDGET", 100000),
+		GraphQLCostWindow:    getEnvDuration("GRAPHQL_COST_WINDOW", time.Minute),
+
+		GraphQLAPQCacheSize:     getEnvInt("GRAPHQL_APQ_CACHE_SIZE", 1000),
+		GraphQLPersistedQueries: os.Getenv("GRAPHQL_PERSISTED_QUERIES"),
+
+		GRPCPort:             getEnv("GRPC_PORT", "50051"),
+		GRPCReflection:       getEnvBool("GRPC_REFLECTION", false),
+		GRPCServiceTokens:    getEnvMap("GRPC_SERVICE_TOKENS"),
+		GRPCServiceACL:       getEnvACL("GRPC_SERVICE_ACL"),
+		GRPCIdempotencyTTL:   getEnvDuration("GRPC_IDEMPOTENCY_TTL", 24*time.Hour),
+		GRPCIdempotencyLease: getEnvDuration("GRPC_IDEMPOTENCY_LEASE", time.Minute),
+		GRPCTLSCert:          os.Getenv("GRPC_TLS_CERT"),
+		GRPCTLSKey:           os.Getenv("GRPC_TLS_KEY"),
+		GRPCClientCA:         os.Getenv("GRPC_CLIENT_CA"),
 	}
 }
 
@@ -31,3 +121,45 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return n
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return d
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if b, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return b
+	}
+	return defaultValue
+}
+
+// getEnvMap parses "name:value,name:value"
+func getEnvMap(key string) map[string]string {
+	m := map[string]string{}
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if ok && name != "" {
+			m[name] = value
+		}
+	}
+	return m
+}
+
+// getEnvACL parses "name:Method1|Method2,name:*"
+func getEnvACL(key string) map[string][]string {
+	acl := map[string][]string{}
+	for name, methods := range getEnvMap(key) {
+		acl[name] = strings.Split(methods, "|")
+	}
+	return acl
+}
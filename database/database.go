@@ -1,10 +1,11 @@
 package database
 
 import (
+	"context"
+	"fmt"
 	"log"
 
 	"github.com/antoniocfetngnu/users-api/config"
-	"github.com/antoniocfetngnu/users-api/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -12,7 +13,41 @@ import (
 
 var DB *gorm.DB
 
+// Migration modes for Connect (config.DatabaseMigrations)
+const (
+	MigrationsUp     = "up"     // apply pending migrations on boot
+	MigrationsVerify = "verify" // refuse to start with pending migrations
+	MigrationsOff    = "off"
+)
+
+// Connect opens the database and migrates or checks its schema as
+// cfg.DatabaseMigrations says
 func Connect(cfg *config.Config) error {
+	if err := Open(cfg); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch cfg.DatabaseMigrations {
+	case MigrationsUp:
+		if err := MigrateUp(ctx, DB); err != nil {
+			return err
+		}
+		log.Println("✅ Database migrations completed")
+	case MigrationsVerify:
+		if err := CheckMigrations(ctx, DB); err != nil {
+			return fmt.Errorf("%w (run `users-api migrate up`)", err)
+		}
+		log.Println("✅ Database schema is up to date")
+	case MigrationsOff:
+	default:
+		return fmt.Errorf("unknown DB_MIGRATIONS mode %q (want %s, %s or %s)", cfg.DatabaseMigrations, MigrationsUp, MigrationsVerify, MigrationsOff)
+	}
+	return nil
+}
+
+// Open connects to the database without touching its schema
+func Open(cfg *config.Config) error {
 	var err error
 
 	// Configure GORM logger
@@ -27,16 +62,18 @@ func Connect(cfg *config.Config) error {
 	}
 
 	log.Println("✅ Database connected successfully")
-
-	// Auto-migrate models (creates tables if they don't exist)
-	if err := DB.AutoMigrate(&models.User{}, &models.Follower{}); err != nil {
-		return err
-	}
-
-	log.Println("✅ Database migrations completed")
 	return nil
 }
 
 func GetDB() *gorm.DB {
 	return DB
 }
+
+// Ping checks that the database is reachable
+func Ping(ctx context.Context) error {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
@@ -0,0 +1,316 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Versioned SQL migrations, NNNN_name.up.sql and NNNN_name.down.sql. The
+// applied versions are recorded in schema_migrations.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationsLock is the Postgres advisory lock held while migrating, so
+// replicas starting together apply each migration once
+const migrationsLock = 0x6d696772 // "migr"
+
+// ErrSchemaNotMigrated is returned by CheckMigrations when migrations are
+// pending
+var ErrSchemaNotMigrated = errors.New("database schema has pending migrations")
+
+// Migration is a schema change and its revert
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// MigrationStatus is a migration and when it was applied (nil if pending)
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migrations returns the embedded migrations, oldest first
+func Migrations() ([]Migration, error) {
+	fsys, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return loadMigrations(fsys)
+}
+
+// loadMigrations reads the migrations in fsys. Versions must start at 1
+// without gaps, and each one needs both an up and a down file.
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: name must be NNNN_name.up.sql or NNNN_name.down.sql", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %s: version %d is also named %s", entry.Name(), version, m.Name)
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for version := 1; version <= len(byVersion); version++ {
+		m, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("migration %04d is missing", version)
+		}
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %s needs both an up and a down file", m)
+		}
+		migrations = append(migrations, *m)
+	}
+	return migrations, nil
+}
+
+// MigrateUp applies every pending migration
+func MigrateUp(ctx context.Context, db *gorm.DB) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+	return migrateTo(ctx, db, migrations, func(map[int]MigrationStatus) int { return len(migrations) })
+}
+
+// MigrateDown reverts the last steps applied migrations
+func MigrateDown(ctx context.Context, db *gorm.DB, steps int) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+	return migrateTo(ctx, db, migrations, func(applied map[int]MigrationStatus) int {
+		last := 0
+		for version := range applied {
+			last = max(last, version)
+		}
+		return max(last-steps, 0)
+	})
+}
+
+// MigrateTo applies or reverts migrations until version is the last one
+// applied. Version 0 reverts everything.
+func MigrateTo(ctx context.Context, db *gorm.DB, version int) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+	if version < 0 || version > len(migrations) {
+		return fmt.Errorf("unknown migration version %d (latest is %d)", version, len(migrations))
+	}
+	return migrateTo(ctx, db, migrations, func(map[int]MigrationStatus) int { return version })
+}
+
+// GetMigrationStatus returns every migration the binary knows of, and any
+// applied one it doesn't (from a newer release), oldest first
+func GetMigrationStatus(ctx context.Context, db *gorm.DB) ([]MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	return migrationStatus(ctx, db, migrations)
+}
+
+// CheckMigrations returns ErrSchemaNotMigrated unless every migration has
+// been applied
+func CheckMigrations(ctx context.Context, db *gorm.DB) error {
+	statuses, err := GetMigrationStatus(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, status.String())
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %v", ErrSchemaNotMigrated, pending)
+	}
+	return nil
+}
+
+// migrateTo applies the migrations up to the target version and reverts
+// those above it, newest first, each in its own transaction. It holds
+// migrationsLock on a dedicated connection for the whole run; targetOf
+// picks the version from the migrations applied once it has the lock.
+func migrateTo(ctx context.Context, db *gorm.DB, migrations []Migration, targetOf func(applied map[int]MigrationStatus) int) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationsLock); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationsLock)
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version     bigint PRIMARY KEY,
+			name        text NOT NULL,
+			applied_at  timestamptz NOT NULL DEFAULT now()
+		)`); err != nil {
+		return err
+	}
+
+	// Read under the lock: another runner may have just migrated
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return err
+	}
+	target := targetOf(applied)
+	for version, status := range applied {
+		if version > target && version > len(migrations) {
+			return fmt.Errorf("can't revert migration %s: this binary doesn't know it", status)
+		}
+	}
+
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok || m.Version > target {
+			continue
+		}
+		err := runMigration(ctx, conn, m.Up, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Version, m.Name)
+		if err != nil {
+			return fmt.Errorf("migration %s: %w", m, err)
+		}
+		log.Printf("⬆️  Applied migration %s", m)
+	}
+
+	for _, m := range slices.Backward(migrations) {
+		if _, ok := applied[m.Version]; !ok || m.Version <= target {
+			continue
+		}
+		if err := runMigration(ctx, conn, m.Down, "DELETE FROM schema_migrations WHERE version = $1", m.Version); err != nil {
+			return fmt.Errorf("reverting migration %s: %w", m, err)
+		}
+		log.Printf("⬇️  Reverted migration %s", m)
+	}
+	return nil
+}
+
+// runMigration runs a migration script and records it in one transaction
+func runMigration(ctx context.Context, conn *sql.Conn, script, record string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// migrationStatus merges migrations with the versions recorded in the
+// database. It doesn't take migrationsLock, or create schema_migrations.
+func migrationStatus(ctx context.Context, db *gorm.DB, migrations []Migration) ([]MigrationStatus, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var exists bool
+	if err := sqlDB.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return nil, err
+	}
+	applied := map[int]MigrationStatus{}
+	if exists {
+		if applied, err = appliedMigrations(ctx, sqlDB); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]MigrationStatus, 0, max(len(migrations), len(applied)))
+	for _, m := range migrations {
+		status := MigrationStatus{Migration: m}
+		if a, ok := applied[m.Version]; ok {
+			status.AppliedAt = a.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	for version, status := range applied {
+		if version > len(migrations) {
+			statuses = append(statuses, status)
+		}
+	}
+	slices.SortFunc(statuses, func(a, b MigrationStatus) int { return a.Version - b.Version })
+	return statuses, nil
+}
+
+// appliedMigrations returns the rows of schema_migrations by version
+func appliedMigrations(ctx context.Context, db interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}) (map[int]MigrationStatus, error) {
+	rows, err := db.QueryContext(ctx, "SELECT version, name, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]MigrationStatus{}
+	for rows.Next() {
+		var status MigrationStatus
+		var appliedAt time.Time
+		if err := rows.Scan(&status.Version, &status.Name, &appliedAt); err != nil {
+			return nil, err
+		}
+		status.AppliedAt = &appliedAt
+		applied[status.Version] = status
+	}
+	return applied, rows.Err()
+}
@@ -0,0 +1,50 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/antoniocfetngnu/users-api/database"
+	"github.com/antoniocfetngnu/users-api/repository/repositorytest"
+)
+
+// TestMigrationsOnPostgres reverts every migration applied by
+// repositorytest.OpenPostgres and applies them again
+func TestMigrationsOnPostgres(t *testing.T) {
+	ctx := context.Background()
+	db := repositorytest.OpenPostgres(t)
+
+	migrations, err := database.Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := migrations[len(migrations)-1].Version
+
+	for _, step := range []struct {
+		name    string
+		migrate func() error
+		applied int
+	}{
+		{"up", func() error { return nil }, latest},
+		{"down one", func() error { return database.MigrateDown(ctx, db, 1) }, latest - 1},
+		{"to 0", func() error { return database.MigrateTo(ctx, db, 0) }, 0},
+		{"up again", func() error { return database.MigrateUp(ctx, db) }, latest},
+	} {
+		if err := step.migrate(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		statuses, err := database.GetMigrationStatus(ctx, db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, status := range statuses {
+			if applied := status.AppliedAt != nil; applied != (status.Version <= step.applied) {
+				t.Fatalf("%s: %s applied = %v", step.name, status.Migration, applied)
+			}
+		}
+	}
+
+	if err := database.CheckMigrations(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+}
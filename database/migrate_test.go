@@ -0,0 +1,100 @@
+package database
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"gorm.io/gorm/schema"
+
+	"github.com/antoniocfetngnu/users-api/models"
+)
+
+func TestMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 || migrations[0].String() != "0001_initial" {
+		t.Fatalf("migrations = %v", migrations)
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(content string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(content)} }
+
+	migrations, err := loadMigrations(fstest.MapFS{
+		"0002_second.up.sql":   file("up 2"),
+		"0002_second.down.sql": file("down 2"),
+		"0001_first.up.sql":    file("up 1"),
+		"0001_first.down.sql":  file("down 1"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 || migrations[0].Up != "up 1" || migrations[1].Down != "down 2" || migrations[1].Name != "second" {
+		t.Fatalf("migrations = %+v", migrations)
+	}
+
+	invalid := map[string]fstest.MapFS{
+		"bad name":      {"first.up.sql": file("up")},
+		"missing down":  {"0001_first.up.sql": file("up")},
+		"gap":           {"0002_second.up.sql": file("up"), "0002_second.down.sql": file("down")},
+		"renamed":       {"0001_first.up.sql": file("up"), "0001_other.down.sql": file("down")},
+		"empty version": {"0001_first.up.sql": file(""), "0001_first.down.sql": file("down")},
+	}
+	for name, fsys := range invalid {
+		if _, err := loadMigrations(fsys); err == nil || !strings.Contains(err.Error(), "migration") {
+			t.Errorf("%s: err = %v", name, err)
+		}
+	}
+}
+
+// TestMigrationsMatchModels checks that the up migrations create exactly the
+// columns of the models. Tests without Postgres build their tables from the
+// models, so they would miss a column the migrations forgot.
+func TestMigrationsMatchModels(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	createTable := regexp.MustCompile(`(?s)CREATE TABLE IF NOT EXISTS (\w+) \((.*?)\n\);`)
+	addColumn := regexp.MustCompile(`ALTER TABLE (\w+) ADD COLUMN IF NOT EXISTS (\w+)`)
+	dropColumn := regexp.MustCompile(`ALTER TABLE (\w+) DROP COLUMN (?:IF EXISTS )?(\w+)`)
+
+	tables := map[string]map[string]bool{}
+	for _, migration := range migrations {
+		for _, match := range createTable.FindAllStringSubmatch(migration.Up, -1) {
+			columns := map[string]bool{}
+			for _, line := range strings.Split(match[2], "\n") {
+				if fields := strings.Fields(line); len(fields) > 0 && fields[0] != "CONSTRAINT" {
+					columns[fields[0]] = true
+				}
+			}
+			tables[match[1]] = columns
+		}
+		for _, match := range addColumn.FindAllStringSubmatch(migration.Up, -1) {
+			tables[match[1]][match[2]] = true
+		}
+		for _, match := range dropColumn.FindAllStringSubmatch(migration.Up, -1) {
+			delete(tables[match[1]], match[2])
+		}
+	}
+
+	for _, model := range []any{&models.User{}, &models.Follower{}, &models.FollowEvent{}, &models.UserChange{}, &models.IdempotencyKey{}, &models.AuditEntry{}} {
+		parsed, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := slices.Sorted(slices.Values(parsed.DBNames))
+		got := slices.Sorted(maps.Keys(tables[parsed.Table]))
+		if !slices.Equal(got, want) {
+			t.Errorf("migrations create %s with %v, the model has %v", parsed.Table, got, want)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS user_changes;
+DROP TABLE IF EXISTS followers;
+DROP TABLE IF EXISTS follow_events;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,92 @@
+-- Baseline schema. It matches what GORM's AutoMigrate created before
+-- versioned migrations, so existing databases adopt it without changes.
+
+CREATE TABLE IF NOT EXISTS users (
+    id          bigserial PRIMARY KEY,
+    first_name  text NOT NULL,
+    last_name   text NOT NULL,
+    email       text NOT NULL,
+    username    text NOT NULL,
+    password    text NOT NULL,
+    role        varchar(16) NOT NULL DEFAULT 'user',
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS follow_events (
+    id           bigserial PRIMARY KEY,
+    follower_id  bigint NOT NULL,
+    followed_id  bigint NOT NULL,
+    action       varchar(16) NOT NULL,
+    created_at   timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_follow_events_pair ON follow_events (follower_id, followed_id);
+
+CREATE TABLE IF NOT EXISTS followers (
+    id              bigserial PRIMARY KEY,
+    follower_id     bigint NOT NULL,
+    followed_id     bigint NOT NULL,
+    followed_since  timestamptz NOT NULL,
+    created_at      timestamptz,
+    updated_at      timestamptz,
+    CONSTRAINT fk_followers_follower FOREIGN KEY (follower_id) REFERENCES users (id),
+    CONSTRAINT fk_followers_followed FOREIGN KEY (followed_id) REFERENCES users (id)
+);
+
+-- Databases from before the unique follow graph have soft-deleted,
+-- possibly duplicated edges:
+--   - duplicate live edges collapse onto the oldest row
+--   - every remaining edge is copied into follow_events as history
+--   - soft-deleted edges are hard-deleted and the deleted_at column dropped
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'followers' AND column_name = 'deleted_at'
+    ) THEN
+        DELETE FROM followers a
+        USING followers b
+        WHERE a.deleted_at IS NULL AND b.deleted_at IS NULL
+          AND a.follower_id = b.follower_id
+          AND a.followed_id = b.followed_id
+          AND a.id > b.id;
+
+        INSERT INTO follow_events (follower_id, followed_id, action, created_at)
+        SELECT follower_id, followed_id, 'follow', followed_since FROM followers
+        UNION ALL
+        SELECT follower_id, followed_id, 'unfollow', deleted_at FROM followers WHERE deleted_at IS NOT NULL;
+
+        DELETE FROM followers WHERE deleted_at IS NOT NULL;
+
+        -- The old composite index was not unique
+        DROP INDEX IF EXISTS idx_follower_followed;
+        ALTER TABLE followers DROP COLUMN deleted_at;
+    END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_followers_pair ON followers (follower_id, followed_id);
+CREATE INDEX IF NOT EXISTS idx_followers_followed_id ON followers (followed_id);
+
+CREATE TABLE IF NOT EXISTS user_changes (
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL,
+    action      varchar(16) NOT NULL,
+    created_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_user_changes_user_id ON user_changes (user_id);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id            bigserial PRIMARY KEY,
+    scope         varchar(255) NOT NULL,
+    key           varchar(255) NOT NULL,
+    method        varchar(255) NOT NULL,
+    request_hash  bytea NOT NULL,
+    response      bytea,
+    created_at    timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_idempotency_keys_scope_key ON idempotency_keys (scope, key);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys (created_at);
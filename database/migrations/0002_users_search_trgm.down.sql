@@ -0,0 +1,4 @@
+-- pg_trgm stays installed, other schemas in the database may use it
+DROP INDEX IF EXISTS idx_users_username_trgm;
+DROP INDEX IF EXISTS idx_users_last_name_trgm;
+DROP INDEX IF EXISTS idx_users_first_name_trgm;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS audit_entries;
+ALTER TABLE users DROP COLUMN IF EXISTS disabled_at;
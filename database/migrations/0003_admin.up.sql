@@ -0,0 +1,14 @@
+-- Operator tooling: disabled accounts and the audit log
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS disabled_at timestamptz;
+
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id          bigserial PRIMARY KEY,
+    actor       varchar(255) NOT NULL,
+    action      varchar(64) NOT NULL,
+    user_id     bigint,
+    details     text NOT NULL DEFAULT '{}',
+    created_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_user_id ON audit_entries (user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_created_at ON audit_entries (created_at);
@@ -0,0 +1,7 @@
+-- Usernames and emails only need to be unique among live users, so a
+-- deleted account doesn't block registering them again
+
+DROP INDEX IF EXISTS idx_users_email;
+DROP INDEX IF EXISTS idx_users_username;
+CREATE UNIQUE INDEX idx_users_email ON users (email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_users_username ON users (username) WHERE deleted_at IS NULL;
@@ -0,0 +1,2 @@
+-- The erased data is gone for good, the tombstones stay as deleted users
+ALTER TABLE users DROP COLUMN IF EXISTS erased_at;
@@ -0,0 +1,3 @@
+-- Erased accounts: tombstones left by a right-to-erasure request
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS erased_at timestamptz;
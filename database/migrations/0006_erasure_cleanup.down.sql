@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_idempotency_keys_user_id;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS user_id;
@@ -0,0 +1,5 @@
+-- Stored idempotent responses name the user they describe, so erasing the
+-- user can remove them
+
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS user_id bigint;
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_user_id ON idempotency_keys (user_id);
@@ -0,0 +1,5 @@
+-- A call holds its idempotency key for a lease from claimed_at; a claim
+-- that was never completed can be taken over once the lease is over
+
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS claimed_at timestamptz;
+UPDATE idempotency_keys SET claimed_at = created_at WHERE claimed_at IS NULL;
@@ -0,0 +1,743 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"github.com/antoniocfetngnu/users-api/config"
+	grpcServer "github.com/antoniocfetngnu/users-api/grpc"
+	"github.com/antoniocfetngnu/users-api/models"
+	pb "github.com/antoniocfetngnu/users-api/proto"
+	usersv2 "github.com/antoniocfetngnu/users-api/proto/v2"
+	"github.com/antoniocfetngnu/users-api/repository"
+	"github.com/antoniocfetngnu/users-api/repository/repositorytest"
+	"github.com/antoniocfetngnu/users-api/services"
+	"github.com/antoniocfetngnu/users-api/utils"
+)
+
+// The tests below run the whole service as main wires it, once over the
+// in-memory store and once over SQLite, without any external database.
+
+const e2eServiceToken = "e2e-service-token"
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+	os.Exit(m.Run())
+}
+
+// testEnv is a running service: HTTP (REST, GraphQL and the gateway) and
+// gRPC, both versions
+type testEnv struct {
+	url string
+	v1  pb.UsersServiceClient
+	v2  usersv2.UsersServiceClient
+}
+
+// testStores are the Store implementations the tests run against
+var testStores = []struct {
+	name     string
+	newStore func(t *testing.T) repository.Store
+}{
+	{"memory", func(*testing.T) repository.Store { return repository.NewMemoryStore() }},
+	{"sqlite", func(t *testing.T) repository.Store { return repositorytest.NewSQLiteStore(t) }},
+}
+
+// forEachStore runs test against a fresh service over every Store
+func forEachStore(t *testing.T, test func(t *testing.T, env *testEnv)) {
+	for _, store := range testStores {
+		t.Run(store.name, func(t *testing.T) {
+			test(t, newTestEnv(t, store.newStore(t)))
+		})
+	}
+}
+
+func newTestEnv(t *testing.T, store repository.Store) *testEnv {
+	t.Helper()
+
+	cfg := &config.Config{
+		JWTSecret:           "e2e-secret",
+		Environment:         "test",
+		AllowedOrigins:      []string{"http://localhost:5173"},
+		GraphQLAPQCacheSize: 100,
+		GRPCServiceTokens:   map[string]string{"e2e": e2eServiceToken},
+		GRPCIdempotencyTTL:  time.Hour,
+		DeletionGracePeriod: time.Hour,
+	}
+	utils.InitJWT(cfg)
+	svc := services.New(store, services.Config{DeletionGracePeriod: cfg.DeletionGracePeriod})
+
+	gateway, err := grpcServer.NewGateway(context.Background(), cfg, svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(gateway.Close)
+
+	router, err := newRouter(cfg, svc, gateway)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	grpcSrv, err := grpcServer.NewServer(cfg, svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis := bufconn.Listen(1 << 20)
+	go grpcSrv.Serve(lis)
+	t.Cleanup(grpcSrv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///e2e",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(serviceToken{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return &testEnv{url: srv.URL, v1: pb.NewUsersServiceClient(conn), v2: usersv2.NewUsersServiceClient(conn)}
+}
+
+// serviceToken authenticates gRPC calls as the e2e service
+type serviceToken struct{}
+
+func (serviceToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + e2eServiceToken}, nil
+}
+
+func (serviceToken) RequireTransportSecurity() bool { return false }
+
+// browser is an HTTP client with its own cookies, like a logged-in user
+type browser struct {
+	t      *testing.T
+	url    string
+	client *http.Client
+}
+
+func (env *testEnv) browser(t *testing.T) *browser {
+	jar, _ := cookiejar.New(nil)
+	return &browser{t: t, url: env.url, client: &http.Client{Jar: jar}}
+}
+
+// do sends body as JSON and decodes the response into out (if not nil)
+func (b *browser) do(method, path string, body, out any) int {
+	b.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			b.t.Fatal(err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequest(method, b.url+path, reader)
+	if err != nil {
+		b.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return b.send(req, out)
+}
+
+func (b *browser) send(req *http.Request, out any) int {
+	b.t.Helper()
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		b.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			b.t.Fatalf("%s %s: %v", req.Method, req.URL.Path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// expect fails the test unless the request answers with want
+func (b *browser) expect(want int, method, path string, body, out any) {
+	b.t.Helper()
+	if got := b.do(method, path, body, out); got != want {
+		b.t.Fatalf("%s %s = %d, want %d", method, path, got, want)
+	}
+}
+
+type restUser struct {
+	ID        uint   `json:"id"`
+	FirstName string `json:"firstName"`
+	Email     string `json:"email"`
+	Username  string `json:"username"`
+}
+
+type restFollower struct {
+	FollowerID uint     `json:"followerId"`
+	FollowedID uint     `json:"followedId"`
+	Follower   restUser `json:"follower"`
+	Followed   restUser `json:"followed"`
+}
+
+// register creates an account through REST and logs b in with it
+func (b *browser) register(username string) restUser {
+	b.t.Helper()
+
+	var registered struct{ User restUser }
+	b.expect(http.StatusCreated, "POST", "/api/auth/register", map[string]string{
+		"firstName": username, "lastName": "Doe", "email": username + "@example.com", "username": username, "password": "secret123",
+	}, &registered)
+	b.expect(http.StatusOK, "POST", "/api/auth/login", map[string]string{"username": username, "password": "secret123"}, nil)
+	return registered.User
+}
+
+// setToken makes b send token as its session, like the cookie set by login
+func (b *browser) setToken(token string) {
+	u, _ := url.Parse(b.url)
+	b.client.Jar.SetCookies(u, []*http.Cookie{{Name: "auth_token", Value: token}})
+}
+
+// forgedToken is a well-formed JWT for userID and role that isn't signed
+// with the service's secret, as anyone can make one
+func forgedToken(userID uint, role string) string {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload := enc.EncodeToString(fmt.Appendf(nil, `{"sub":"%d","role":%q}`, userID, role))
+	return header + "." + payload + "." + enc.EncodeToString([]byte("forged"))
+}
+
+func TestREST(t *testing.T) {
+	forEachStore(t, func(t *testing.T, env *testEnv) {
+		alice, bob, carol := env.browser(t), env.browser(t), env.browser(t)
+
+		var health map[string]string
+		alice.expect(http.StatusOK, "GET", "/api/users/health", nil, &health)
+		alice.expect(http.StatusOK, "HEAD", "/api/users/health", nil, nil)
+		if health["status"] != "OK" {
+			t.Fatalf("health = %v", health)
+		}
+
+		alice.expect(http.StatusUnauthorized, "GET", "/api/auth/me", nil, nil)
+		a, b, c := alice.register("alice"), bob.register("bob"), carol.register("carol")
+
+		alice.expect(http.StatusConflict, "POST", "/api/auth/register", map[string]string{
+			"firstName": "x", "lastName": "x", "email": "other@example.com", "username": "alice", "password": "secret123",
+		}, nil)
+		alice.expect(http.StatusUnauthorized, "POST", "/api/auth/login", map[string]string{"username": "alice", "password": "wrong"}, nil)
+
+		var me restUser
+		alice.expect(http.StatusOK, "GET", "/api/auth/me", nil, &me)
+		if me.ID != a.ID || me.Email != "alice@example.com" {
+			t.Fatalf("me = %+v", me)
+		}
+
+		// Lists are whole unless paginated, and only show the viewer's own
+		// email
+		var page []restUser
+		alice.expect(http.StatusOK, "GET", "/api/users", nil, &page)
+		if len(page) != 3 || page[0].Email != "alice@example.com" || page[2].Email != "" {
+			t.Fatalf("all users = %+v", page)
+		}
+		alice.expect(http.StatusOK, "GET", "/api/users?limit=2", nil, &page)
+		if len(page) != 2 || page[0].Email != "alice@example.com" || page[1].Email != "" {
+			t.Fatalf("first page = %+v", page)
+		}
+		alice.expect(http.StatusOK, "GET", fmt.Sprintf("/api/users?limit=2&after=%d", page[1].ID), nil, &page)
+		if len(page) != 1 || page[0].ID != c.ID {
+			t.Fatalf("second page = %+v", page)
+		}
+		alice.expect(http.StatusBadRequest, "GET", "/api/users?limit=many", nil, nil)
+
+		var user restUser
+		alice.expect(http.StatusOK, "GET", fmt.Sprintf("/api/users/%d", b.ID), nil, &user)
+		if user.Username != "bob" || user.Email != "" {
+			t.Fatalf("bob = %+v", user)
+		}
+		alice.expect(http.StatusNotFound, "GET", "/api/users/999", nil, nil)
+
+		alice.expect(http.StatusOK, "PUT", fmt.Sprintf("/api/users/%d", a.ID), map[string]string{"firstName": "Ada"}, &user)
+		if user.FirstName != "Ada" {
+			t.Fatalf("updated = %+v", user)
+		}
+		alice.expect(http.StatusForbidden, "PUT", fmt.Sprintf("/api/users/%d", b.ID), map[string]string{"firstName": "Eve"}, nil)
+		alice.expect(http.StatusForbidden, "DELETE", fmt.Sprintf("/api/users/%d", b.ID), nil, nil)
+
+		// Follow graph
+		var followed struct{ Follower restFollower }
+		alice.expect(http.StatusCreated, "POST", "/api/followers/follow", map[string]uint{"followedId": b.ID}, &followed)
+		if followed.Follower.Followed.Username != "bob" || followed.Follower.Follower.Username != "alice" {
+			t.Fatalf("follow = %+v", followed)
+		}
+		alice.expect(http.StatusOK, "POST", "/api/followers/follow", map[string]uint{"followedId": b.ID}, nil)
+		alice.expect(http.StatusBadRequest, "POST", "/api/followers/follow", map[string]uint{"followedId": a.ID}, nil)
+		alice.expect(http.StatusNotFound, "POST", "/api/followers/follow", map[string]uint{"followedId": 999}, nil)
+		carol.expect(http.StatusCreated, "POST", "/api/followers/follow", map[string]uint{"followedId": b.ID}, nil)
+
+		var edges []restFollower
+		bob.expect(http.StatusOK, "GET", "/api/followers/my-followers", nil, &edges)
+		if len(edges) != 2 || edges[0].Follower.Username != "alice" || edges[1].Follower.Username != "carol" {
+			t.Fatalf("bob's followers = %+v", edges)
+		}
+		alice.expect(http.StatusOK, "GET", "/api/followers/my-following", nil, &edges)
+		if len(edges) != 1 || edges[0].Followed.Username != "bob" {
+			t.Fatalf("alice's following = %+v", edges)
+		}
+
+		var message map[string]string
+		alice.expect(http.StatusOK, "DELETE", fmt.Sprintf("/api/followers/unfollow/%d", b.ID), nil, &message)
+		alice.expect(http.StatusOK, "DELETE", fmt.Sprintf("/api/followers/unfollow/%d", b.ID), nil, &message)
+		if message["message"] != "Not following this user" {
+			t.Fatalf("second unfollow = %v", message)
+		}
+
+		// Deleting an account removes its follows, restoring it doesn't
+		// bring them back
+		restore := fmt.Sprintf("/api/users/%d/restore", c.ID)
+		carol.expect(http.StatusOK, "DELETE", fmt.Sprintf("/api/users/%d", c.ID), nil, nil)
+		carol.expect(http.StatusNotFound, "GET", "/api/auth/me", nil, nil)
+		alice.expect(http.StatusNotFound, "GET", fmt.Sprintf("/api/users/%d", c.ID), nil, nil)
+		bob.expect(http.StatusOK, "GET", "/api/followers/my-followers", nil, &edges)
+		if len(edges) != 0 {
+			t.Fatalf("bob's followers after carol left = %+v", edges)
+		}
+		carol.expect(http.StatusNotFound, "POST", "/api/followers/follow", map[string]uint{"followedId": b.ID}, nil)
+
+		carol.expect(http.StatusOK, "POST", restore, nil, &user)
+		if user.Username != "carol" || user.Email != "carol@example.com" {
+			t.Fatalf("restored = %+v", user)
+		}
+		carol.expect(http.StatusConflict, "POST", restore, nil, nil)
+		bob.expect(http.StatusOK, "GET", "/api/followers/my-followers", nil, &edges)
+		if len(edges) != 0 {
+			t.Fatalf("bob's followers after carol came back = %+v", edges)
+		}
+
+		// Without a session, the password restores it
+		carol.expect(http.StatusOK, "DELETE", fmt.Sprintf("/api/users/%d", c.ID), nil, nil)
+		stranger := env.browser(t)
+		stranger.expect(http.StatusUnauthorized, "POST", restore, nil, nil)
+		stranger.expect(http.StatusUnauthorized, "POST", restore, map[string]string{"password": "wrong"}, nil)
+		stranger.expect(http.StatusUnauthorized, "POST", "/api/users/999/restore", map[string]string{"password": "secret123"}, nil)
+		alice.expect(http.StatusUnauthorized, "POST", restore, nil, nil)
+		// Kong doesn't authenticate this route, an unsigned token is no session
+		forger := env.browser(t)
+		forger.setToken(forgedToken(c.ID, models.RoleUser))
+		forger.expect(http.StatusUnauthorized, "POST", restore, nil, nil)
+		forger.setToken(forgedToken(999, models.RoleAdmin))
+		forger.expect(http.StatusUnauthorized, "POST", restore, nil, nil)
+		stranger.expect(http.StatusOK, "POST", restore, map[string]string{"password": "secret123"}, &user)
+		if user.ID != c.ID {
+			t.Fatalf("restored by password = %+v", user)
+		}
+
+		// The username of a deleted account is free again, which keeps the
+		// account from coming back
+		carol.expect(http.StatusOK, "DELETE", fmt.Sprintf("/api/users/%d", c.ID), nil, nil)
+		if again := env.browser(t).register("carol"); again.ID == c.ID {
+			t.Fatalf("carol registered again as %+v", again)
+		}
+		carol.expect(http.StatusConflict, "POST", restore, nil, nil)
+
+		// Erasing an account leaves a tombstone without personal data
+		dave := env.browser(t)
+		d := dave.register("dave")
+		alice.expect(http.StatusCreated, "POST", "/api/followers/follow", map[string]uint{"followedId": d.ID}, nil)
+		erase := fmt.Sprintf("/api/users/%d/erase", d.ID)
+		dave.expect(http.StatusBadRequest, "POST", erase, map[string]string{}, nil)
+		dave.expect(http.StatusBadRequest, "POST", erase, map[string]string{"password": "wrong"}, nil)
+		alice.expect(http.StatusForbidden, "POST", erase, map[string]string{"password": "secret123"}, nil)
+
+		var receipt struct {
+			ID          uint   `json:"id"`
+			UserID      uint   `json:"userId"`
+			Actor       string `json:"actor"`
+			FollowEdges int    `json:"followEdges"`
+		}
+		dave.expect(http.StatusOK, "POST", erase, map[string]string{"password": "secret123"}, &receipt)
+		if receipt.ID == 0 || receipt.UserID != d.ID || receipt.Actor != fmt.Sprintf("user:%d", d.ID) || receipt.FollowEdges != 1 {
+			t.Fatalf("receipt = %+v", receipt)
+		}
+		dave.expect(http.StatusConflict, "POST", erase, map[string]string{"password": "secret123"}, nil)
+		dave.expect(http.StatusConflict, "POST", fmt.Sprintf("/api/users/%d/restore", d.ID), nil, nil)
+		alice.expect(http.StatusNotFound, "GET", fmt.Sprintf("/api/users/%d", d.ID), nil, nil)
+		alice.expect(http.StatusOK, "GET", "/api/followers/my-following", nil, &edges)
+		if len(edges) != 0 {
+			t.Fatalf("alice's following after dave was erased = %+v", edges)
+		}
+		env.browser(t).register("dave")
+
+		// Tombstone usernames can't be taken, so no one passes as an
+		// erased account
+		tombstone := fmt.Sprintf("erased-%d", d.ID)
+		env.browser(t).expect(http.StatusBadRequest, "POST", "/api/auth/register", map[string]string{
+			"firstName": "x", "lastName": "x", "email": "x@example.com", "username": tombstone, "password": "secret123",
+		}, nil)
+		alice.expect(http.StatusBadRequest, "PUT", fmt.Sprintf("/api/users/%d", a.ID), map[string]string{"username": "Erased-1000"}, nil)
+
+		// Admins erase any account
+		token, err := utils.GenerateJWT(999, "root", "root@example.com", models.RoleAdmin)
+		if err != nil {
+			t.Fatal(err)
+		}
+		root := env.browser(t)
+		root.setToken(token)
+		alice.expect(http.StatusForbidden, "POST", fmt.Sprintf("/api/admin/users/%d/erase", b.ID), nil, nil)
+		root.expect(http.StatusOK, "POST", fmt.Sprintf("/api/admin/users/%d/erase", b.ID), nil, &receipt)
+		if receipt.UserID != b.ID || receipt.Actor != "user:999" {
+			t.Fatalf("admin receipt = %+v", receipt)
+		}
+		root.expect(http.StatusNotFound, "POST", "/api/admin/users/999/erase", nil, nil)
+
+		alice.expect(http.StatusOK, "POST", "/api/auth/logout", nil, nil)
+		alice.expect(http.StatusUnauthorized, "GET", "/api/auth/me", nil, nil)
+	})
+}
+
+// graphql runs an operation through /graphql and decodes its data into out
+func (b *browser) graphql(query string, variables map[string]any, out any) []string {
+	b.t.Helper()
+
+	var resp struct {
+		Data   json.RawMessage
+		Errors []struct{ Message string }
+	}
+	b.expect(http.StatusOK, "POST", "/graphql", map[string]any{"query": query, "variables": variables}, &resp)
+
+	var messages []string
+	for _, err := range resp.Errors {
+		messages = append(messages, err.Message)
+	}
+	if out != nil && len(resp.Data) > 0 && string(resp.Data) != "null" {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			b.t.Fatal(err)
+		}
+	}
+	return messages
+}
+
+// mustGraphQL is graphql for operations that must succeed
+func (b *browser) mustGraphQL(query string, variables map[string]any, out any) {
+	b.t.Helper()
+	if errs := b.graphql(query, variables, out); len(errs) > 0 {
+		b.t.Fatalf("%s: %v", query, errs)
+	}
+}
+
+type gqlUser struct {
+	ID             string
+	DatabaseID     string
+	Username       string
+	FirstName      string
+	Email          *string
+	FollowerCount  int
+	FollowingCount int
+}
+
+func TestGraphQL(t *testing.T) {
+	forEachStore(t, func(t *testing.T, env *testEnv) {
+		alice, bob := env.browser(t), env.browser(t)
+
+		if errs := alice.graphql(`{ viewer { id } }`, nil, nil); len(errs) == 0 {
+			t.Fatal("viewer without a session succeeded")
+		}
+
+		const register = `mutation($input: RegisterInput!) { register(input: $input) { databaseId username } }`
+		const login = `mutation($input: LoginInput!) { login(input: $input) { username } }`
+		var ids = map[string]string{}
+		for name, b := range map[string]*browser{"alice": alice, "bob": bob} {
+			var registered struct{ Register gqlUser }
+			b.mustGraphQL(register, map[string]any{"input": map[string]any{
+				"firstName": name, "lastName": "Doe", "email": name + "@example.com", "username": name, "password": "secret123",
+			}}, &registered)
+			ids[name] = registered.Register.DatabaseID
+			b.mustGraphQL(login, map[string]any{"input": map[string]any{"username": name, "password": "secret123"}}, nil)
+		}
+
+		// /graphql is public behind Kong, so an unsigned token is no session
+		mallory := env.browser(t)
+		mallory.setToken(forgedToken(1, models.RoleAdmin))
+		if errs := mallory.graphql(`{ viewer { id } }`, nil, nil); len(errs) == 0 {
+			t.Fatal("viewer with a forged token succeeded")
+		}
+
+		var viewer struct{ Viewer gqlUser }
+		alice.mustGraphQL(`{ viewer { id username email } }`, nil, &viewer)
+		if viewer.Viewer.Username != "alice" || viewer.Viewer.Email == nil {
+			t.Fatalf("viewer = %+v", viewer.Viewer)
+		}
+
+		var follow struct{ Follow struct{ Followed gqlUser } }
+		alice.mustGraphQL(`mutation($id: ID!) { follow(userId: $id) { followed { username } } }`, map[string]any{"id": ids["bob"]}, &follow)
+		if follow.Follow.Followed.Username != "bob" {
+			t.Fatalf("follow = %+v", follow)
+		}
+
+		var lookups struct {
+			User           gqlUser
+			UserByUsername gqlUser
+			UserByEmail    *gqlUser
+			Node           gqlUser
+			Users          struct{ TotalCount int }
+			SearchUsers    struct {
+				Edges []struct{ Node gqlUser }
+			}
+			Followers            struct{ TotalCount int }
+			Following            struct{ TotalCount int }
+			IsFollowing          bool
+			FollowerRelationship *struct{ Follower gqlUser }
+			FollowerCount        int
+			FollowingCount       int
+		}
+		alice.mustGraphQL(`query($bob: ID!, $alice: ID!, $node: ID!) {
+			user(id: $bob) { username email followerCount }
+			userByUsername(username: "bob") { databaseId }
+			userByEmail(email: "bob@example.com") { username }
+			node(id: $node) { ... on User { username } }
+			users(first: 10) { totalCount }
+			searchUsers(query: "BO") { edges { node { username } } }
+			followers(userId: $bob) { totalCount }
+			following(userId: $alice) { totalCount }
+			isFollowing(followerId: $alice, followedId: $bob)
+			followerRelationship(followerId: $alice, followedId: $bob) { follower { username } }
+			followerCount(userId: $bob)
+			followingCount(userId: $alice)
+		}`, map[string]any{"bob": ids["bob"], "alice": ids["alice"], "node": viewer.Viewer.ID}, &lookups)
+
+		switch {
+		case lookups.User.Username != "bob" || lookups.User.Email != nil || lookups.User.FollowerCount != 1:
+			t.Fatalf("user = %+v", lookups.User)
+		case lookups.UserByUsername.DatabaseID != ids["bob"]:
+			t.Fatalf("userByUsername = %+v", lookups.UserByUsername)
+		case lookups.UserByEmail != nil:
+			t.Fatalf("userByEmail answered a non-admin: %+v", lookups.UserByEmail)
+		case lookups.Node.Username != "alice":
+			t.Fatalf("node = %+v", lookups.Node)
+		case lookups.Users.TotalCount != 2:
+			t.Fatalf("users = %+v", lookups.Users)
+		case len(lookups.SearchUsers.Edges) != 1 || lookups.SearchUsers.Edges[0].Node.Username != "bob":
+			t.Fatalf("searchUsers = %+v", lookups.SearchUsers)
+		case lookups.Followers.TotalCount != 1 || lookups.Following.TotalCount != 1:
+			t.Fatalf("followers = %+v, following = %+v", lookups.Followers, lookups.Following)
+		case !lookups.IsFollowing || lookups.FollowerRelationship == nil || lookups.FollowerRelationship.Follower.Username != "alice":
+			t.Fatalf("isFollowing = %v, followerRelationship = %+v", lookups.IsFollowing, lookups.FollowerRelationship)
+		case lookups.FollowerCount != 1 || lookups.FollowingCount != 1:
+			t.Fatalf("followerCount = %d, followingCount = %d", lookups.FollowerCount, lookups.FollowingCount)
+		}
+
+		var updated struct{ UpdateProfile gqlUser }
+		alice.mustGraphQL(`mutation { updateProfile(input: { firstName: "Ada" }) { firstName } }`, nil, &updated)
+		if updated.UpdateProfile.FirstName != "Ada" {
+			t.Fatalf("updateProfile = %+v", updated)
+		}
+		alice.mustGraphQL(`mutation { changePassword(input: { currentPassword: "secret123", newPassword: "secret456" }) }`, nil, nil)
+		if errs := alice.graphql(login, map[string]any{"input": map[string]any{"username": "alice", "password": "secret123"}}, nil); len(errs) == 0 {
+			t.Fatal("logged in with the old password")
+		}
+
+		var unfollowed struct{ Unfollow bool }
+		alice.mustGraphQL(`mutation($id: ID!) { unfollow(userId: $id) }`, map[string]any{"id": ids["bob"]}, &unfollowed)
+		if !unfollowed.Unfollow {
+			t.Fatal("unfollow removed nothing")
+		}
+
+		bob.mustGraphQL(`mutation { deleteAccount }`, nil, nil)
+		var gone struct{ User *gqlUser }
+		if errs := alice.graphql(`query($id: ID!) { user(id: $id) { username } }`, map[string]any{"id": ids["bob"]}, &gone); len(errs) == 0 || gone.User != nil {
+			t.Fatalf("deleted user = %+v, %v", gone.User, errs)
+		}
+
+		alice.mustGraphQL(`mutation { logout }`, nil, nil)
+		if errs := alice.graphql(`{ viewer { id } }`, nil, nil); len(errs) == 0 {
+			t.Fatal("viewer after logout succeeded")
+		}
+	})
+}
+
+func TestGRPC(t *testing.T) {
+	forEachStore(t, func(t *testing.T, env *testEnv) {
+		ctx := context.Background()
+
+		var ids []uint64
+		for _, name := range []string{"alice", "bob", "carol"} {
+			user, err := env.v2.CreateUser(ctx, &usersv2.CreateUserRequest{
+				FirstName: name, LastName: "Doe", Email: name + "@example.com", Username: name, Password: "secret123",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, user.Id)
+		}
+		alice, bob, carol := ids[0], ids[1], ids[2]
+
+		// Writes (v2 only)
+		for _, follower := range []uint64{bob, carol} {
+			resp, err := env.v2.Follow(ctx, &usersv2.FollowRequest{FollowerId: follower, FollowedId: alice})
+			if err != nil || !resp.Created || resp.Follow.User.GetUsername() != "alice" {
+				t.Fatalf("Follow = %v, %v", resp, err)
+			}
+		}
+		name := "Ada"
+		if user, err := env.v2.UpdateUser(ctx, &usersv2.UpdateUserRequest{UserId: alice, FirstName: &name}); err != nil || user.FirstName != "Ada" {
+			t.Fatalf("UpdateUser = %v, %v", user, err)
+		}
+		if _, err := env.v2.CreateUser(ctx, &usersv2.CreateUserRequest{
+			FirstName: "x", LastName: "x", Email: "x@example.com", Username: "alice", Password: "secret123",
+		}); status.Code(err) != codes.AlreadyExists {
+			t.Fatalf("duplicate CreateUser = %v", err)
+		}
+
+		// Reads, both versions
+		if user, err := env.v1.GetUser(ctx, &pb.GetUserRequest{UserId: uint32(alice)}); err != nil || user.FirstName != "Ada" || user.Email != "alice@example.com" {
+			t.Fatalf("v1 GetUser = %v, %v", user, err)
+		}
+		if user, err := env.v2.GetUser(ctx, &usersv2.GetUserRequest{UserId: alice, ReadMask: &fieldmaskpb.FieldMask{Paths: []string{"username"}}}); err != nil || user.Username != "alice" || user.Email != "" {
+			t.Fatalf("v2 GetUser = %v, %v", user, err)
+		}
+		if _, err := env.v2.GetUser(ctx, &usersv2.GetUserRequest{UserId: 999}); status.Code(err) != codes.NotFound {
+			t.Fatalf("GetUser of a missing user = %v", err)
+		}
+		if resp, err := env.v1.GetUsers(ctx, &pb.GetUsersRequest{UserIds: []uint32{uint32(carol), 999, uint32(alice)}}); err != nil || len(resp.Users) != 2 || resp.Users[0].Username != "carol" || !slices.Equal(resp.MissingIds, []uint32{999}) {
+			t.Fatalf("v1 GetUsers = %v, %v", resp, err)
+		}
+		if resp, err := env.v2.GetUsers(ctx, &usersv2.GetUsersRequest{UserIds: []uint64{bob}}); err != nil || len(resp.Users) != 1 {
+			t.Fatalf("v2 GetUsers = %v, %v", resp, err)
+		}
+		if user, err := env.v1.GetUserByUsername(ctx, &pb.GetUserByUsernameRequest{Username: "bob"}); err != nil || uint64(user.Id) != bob {
+			t.Fatalf("v1 GetUserByUsername = %v, %v", user, err)
+		}
+		if user, err := env.v2.GetUserByUsername(ctx, &usersv2.GetUserByUsernameRequest{Username: "bob"}); err != nil || user.Id != bob {
+			t.Fatalf("v2 GetUserByUsername = %v, %v", user, err)
+		}
+		if resp, err := env.v1.GetUsersByUsernames(ctx, &pb.GetUsersByUsernamesRequest{Usernames: []string{"bob", "nobody"}}); err != nil || len(resp.Users) != 1 || !slices.Equal(resp.MissingUsernames, []string{"nobody"}) {
+			t.Fatalf("v1 GetUsersByUsernames = %v, %v", resp, err)
+		}
+		if resp, err := env.v2.GetUsersByUsernames(ctx, &usersv2.GetUsersByUsernamesRequest{Usernames: []string{"carol"}}); err != nil || len(resp.Users) != 1 {
+			t.Fatalf("v2 GetUsersByUsernames = %v, %v", resp, err)
+		}
+
+		if resp, err := env.v1.CheckFollows(ctx, &pb.CheckFollowsRequest{Pairs: []*pb.FollowPair{
+			{FollowerId: uint32(bob), FollowedId: uint32(alice)}, {FollowerId: uint32(alice), FollowedId: uint32(bob)},
+		}}); err != nil || !resp.Results[0].Follows || resp.Results[1].Follows {
+			t.Fatalf("v1 CheckFollows = %v, %v", resp, err)
+		}
+		if resp, err := env.v2.CheckFollows(ctx, &usersv2.CheckFollowsRequest{Pairs: []*usersv2.FollowPair{{FollowerId: carol, FollowedId: alice}}}); err != nil || !resp.Results[0].Follows {
+			t.Fatalf("v2 CheckFollows = %v, %v", resp, err)
+		}
+
+		page, err := env.v1.ListFollowers(ctx, &pb.ListFollowsRequest{UserId: uint32(alice), PageSize: 1})
+		if err != nil || page.TotalCount != 2 || len(page.Follows) != 1 || page.Follows[0].User.Username != "bob" || page.NextPageToken == "" {
+			t.Fatalf("v1 ListFollowers = %v, %v", page, err)
+		}
+		page, err = env.v1.ListFollowers(ctx, &pb.ListFollowsRequest{UserId: uint32(alice), PageSize: 1, PageToken: page.NextPageToken})
+		if err != nil || len(page.Follows) != 1 || page.Follows[0].User.Username != "carol" || page.NextPageToken != "" {
+			t.Fatalf("v1 ListFollowers second page = %v, %v", page, err)
+		}
+		if resp, err := env.v1.ListFollowing(ctx, &pb.ListFollowsRequest{UserId: uint32(bob)}); err != nil || len(resp.Follows) != 1 || resp.Follows[0].User.Username != "alice" {
+			t.Fatalf("v1 ListFollowing = %v, %v", resp, err)
+		}
+		if resp, err := env.v2.ListFollowers(ctx, &usersv2.ListFollowsRequest{UserId: alice}); err != nil || resp.TotalCount != 2 {
+			t.Fatalf("v2 ListFollowers = %v, %v", resp, err)
+		}
+		if resp, err := env.v2.ListFollowing(ctx, &usersv2.ListFollowsRequest{UserId: carol}); err != nil || len(resp.Follows) != 1 {
+			t.Fatalf("v2 ListFollowing = %v, %v", resp, err)
+		}
+
+		if resp, err := env.v1.GetFollowerIDs(ctx, &pb.GetFollowerIDsRequest{UserId: uint32(alice)}); err != nil || !slices.Equal(resp.FollowerIds, []uint32{uint32(bob), uint32(carol)}) {
+			t.Fatalf("v1 GetFollowerIDs = %v, %v", resp, err)
+		}
+		if resp, err := env.v2.GetFollowerIDs(ctx, &usersv2.GetFollowerIDsRequest{UserId: alice}); err != nil || !slices.Equal(resp.FollowerIds, []uint64{bob, carol}) {
+			t.Fatalf("v2 GetFollowerIDs = %v, %v", resp, err)
+		}
+		v1Stream, err := env.v1.StreamFollowerIDs(ctx, &pb.StreamFollowerIDsRequest{UserId: uint32(alice), ChunkSize: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if chunks := recvAll(t, v1Stream.Recv); chunks != 2 {
+			t.Fatalf("v1 StreamFollowerIDs sent %d chunks, want 2", chunks)
+		}
+		v2Stream, err := env.v2.StreamFollowerIDs(ctx, &usersv2.StreamFollowerIDsRequest{UserId: alice})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if chunks := recvAll(t, v2Stream.Recv); chunks != 1 {
+			t.Fatalf("v2 StreamFollowerIDs sent %d chunks, want 1", chunks)
+		}
+
+		if resp, err := env.v2.Unfollow(ctx, &usersv2.UnfollowRequest{FollowerId: carol, FollowedId: alice}); err != nil || !resp.Removed {
+			t.Fatalf("Unfollow = %v, %v", resp, err)
+		}
+		if _, err := env.v2.DeleteUser(ctx, &usersv2.DeleteUserRequest{UserId: carol}); err != nil {
+			t.Fatal(err)
+		}
+
+		// Three creates, two follows' worth of nothing, an update and a
+		// delete: the log replays everything after the first create
+		watchCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		watch, err := env.v2.WatchUsers(watchCtx, &usersv2.WatchUsersRequest{StartAfter: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var types []usersv2.UserChange_Type
+		for range 4 {
+			change, err := watch.Recv()
+			if err != nil {
+				t.Fatal(err)
+			}
+			types = append(types, change.Type)
+		}
+		want := []usersv2.UserChange_Type{usersv2.UserChange_CREATED, usersv2.UserChange_CREATED, usersv2.UserChange_UPDATED, usersv2.UserChange_DELETED}
+		if !slices.Equal(types, want) {
+			t.Fatalf("WatchUsers = %v, want %v", types, want)
+		}
+
+		// The gateway serves the same RPCs over HTTP
+		req, _ := http.NewRequest("GET", fmt.Sprintf("%s/internal/v1/users/%d", env.url, alice), nil)
+		req.Header.Set("Authorization", "Bearer "+e2eServiceToken)
+		var user struct{ Username string }
+		if code := env.browser(t).send(req, &user); code != http.StatusOK || user.Username != "alice" {
+			t.Fatalf("gateway GetUser = %d, %+v", code, user)
+		}
+	})
+}
+
+// recvAll reads a server stream to the end and returns how many messages
+// it sent
+func recvAll[T any](t *testing.T, recv func() (T, error)) int {
+	t.Helper()
+
+	count := 0
+	for {
+		_, err := recv()
+		if err == io.EOF {
+			return count
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+}
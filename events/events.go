@@ -0,0 +1,132 @@
+// Package events is the in-process pub/sub used to fan out domain changes
+// (follows, profile updates) to subscribers such as GraphQL subscriptions.
+// The Broker can be swapped for an external message broker.
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/antoniocfetngnu/users-api/models"
+)
+
+// Type identifies the kind of change
+type Type string
+
+const (
+	FollowerAdded   Type = "follower.added"
+	FollowerRemoved Type = "follower.removed"
+	UserCreated     Type = "user.created"
+	UserUpdated     Type = "user.updated"
+	UserDeleted     Type = "user.deleted"
+	UserErased      Type = "user.erased"
+)
+
+// AllUsers subscribes to events of a type about every user
+const AllUsers uint = 0
+
+// Event is a change published by the service layer. UserID is the user the
+// event is about (the followed user for follower events). User events carry
+// the Sequence of their models.UserChange.
+type Event struct {
+	Type       Type             `json:"type"`
+	UserID     uint             `json:"userId"`
+	Sequence   uint64           `json:"sequence,omitempty"`
+	User       *models.User     `json:"user,omitempty"`
+	Follower   *models.Follower `json:"follower,omitempty"`
+	OccurredAt time.Time        `json:"occurredAt"`
+}
+
+// Broker delivers published events to subscribers
+type Broker interface {
+	Publish(ctx context.Context, event Event) error
+	// Subscribe delivers events of the given type about userID (or
+	// AllUsers) until ctx is done, then closes the channel
+	Subscribe(ctx context.Context, eventType Type, userID uint) (<-chan Event, error)
+}
+
+var broker Broker = NewMemoryBroker()
+
+// SetBroker replaces the broker used by Publish and Subscribe
+func SetBroker(b Broker) {
+	broker = b
+}
+
+// Publish sends an event to the current broker
+func Publish(ctx context.Context, event Event) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	return broker.Publish(ctx, event)
+}
+
+// Subscribe listens for events on the current broker
+func Subscribe(ctx context.Context, eventType Type, userID uint) (<-chan Event, error) {
+	return broker.Subscribe(ctx, eventType, userID)
+}
+
+// subscriberBuffer is how many events a slow subscriber can lag behind
+// before events are dropped for it
+const subscriberBuffer = 16
+
+type topic struct {
+	eventType Type
+	userID    uint
+}
+
+// MemoryBroker is an in-process Broker. Events are only delivered to
+// subscribers of the same process.
+type MemoryBroker struct {
+	mu   sync.RWMutex
+	subs map[topic]map[chan Event]struct{}
+}
+
+// NewMemoryBroker returns an empty in-process broker
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subs: map[topic]map[chan Event]struct{}{}}
+}
+
+// Publish delivers the event without blocking; subscribers whose buffer is
+// full miss it
+func (b *MemoryBroker) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, key := range []topic{{event.Type, event.UserID}, {event.Type, AllUsers}} {
+		for ch := range b.subs[key] {
+			select {
+			case ch <- event:
+			default:
+			}
+		}
+	}
+	return nil
+}
+
+// Subscribe registers a subscriber until ctx is done
+func (b *MemoryBroker) Subscribe(ctx context.Context, eventType Type, userID uint) (<-chan Event, error) {
+	key := topic{eventType, userID}
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subs[key] == nil {
+		b.subs[key] = map[chan Event]struct{}{}
+	}
+	b.subs[key][ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		b.mu.Lock()
+		delete(b.subs[key], ch)
+		if len(b.subs[key]) == 0 {
+			delete(b.subs, key)
+		}
+		b.mu.Unlock()
+		close(ch)
+	}()
+
+	return ch, nil
+}
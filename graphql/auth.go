@@ -0,0 +1,42 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/antoniocfetngnu/users-api/middleware"
+	"github.com/antoniocfetngnu/users-api/services"
+)
+
+// publicRootFields can be resolved without an auth_token cookie
+var publicRootFields = map[string]bool{
+	"register": true,
+	"login":    true,
+	"logout":   true,
+
+	// Federation: the gateway composes the supergraph from the SDL
+	"_service": true,
+}
+
+// RequireAuth is a root field middleware that rejects every root field
+// except the public ones when the request is not authenticated
+func RequireAuth(ctx context.Context, next graphql.RootResolver) graphql.Marshaler {
+	field := graphql.GetRootFieldContext(ctx)
+	if field != nil && !publicRootFields[field.Field.Name] {
+		if _, err := currentPrincipal(ctx); err != nil {
+			graphql.AddError(ctx, err)
+			return graphql.Null
+		}
+	}
+	return next(ctx)
+}
+
+// currentPrincipal returns the authenticated user propagated from gin by
+// the auth middlewares
+func currentPrincipal(ctx context.Context) (*middleware.Principal, error) {
+	principal, ok := middleware.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, services.ErrUnauthenticated
+	}
+	return principal, nil
+}
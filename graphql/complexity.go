@@ -0,0 +1,54 @@
+package graphql
+
+import (
+	"github.com/antoniocfetngnu/users-api/services"
+)
+
+// listCost is the cost of a list field: its selection once per item
+func listCost(childComplexity, items int) int {
+	return 1 + childComplexity*items
+}
+
+// connectionCost weights a connection field by the page it asks for
+func connectionCost(childComplexity int, first, last *int) int {
+	items := services.DefaultPageSize
+	switch {
+	case first != nil:
+		items = *first
+	case last != nil:
+		items = *last
+	}
+	// Out of range sizes are rejected by the resolver, but must not make
+	// the operation look cheap
+	items = min(max(items, 1), services.MaxPageSize)
+
+	return listCost(childComplexity, items)
+}
+
+// newComplexityRoot returns the per-field costs used by QueryLimits. Fields
+// not listed here cost 1 plus their selection.
+func newComplexityRoot() ComplexityRoot {
+	var c ComplexityRoot
+
+	c.Query.Users = func(childComplexity int, first *int, after *string, last *int, before *string) int {
+		return connectionCost(childComplexity, first, last)
+	}
+	c.Query.SearchUsers = func(childComplexity int, query string, first *int, after *string, last *int, before *string) int {
+		return connectionCost(childComplexity, first, last)
+	}
+	c.Query.Followers = func(childComplexity int, userID string, first *int, after *string, last *int, before *string) int {
+		return connectionCost(childComplexity, first, last)
+	}
+	c.Query.Following = func(childComplexity int, userID string, first *int, after *string, last *int, before *string) int {
+		return connectionCost(childComplexity, first, last)
+	}
+
+	c.User.Followers = func(childComplexity int, first *int, after *string, last *int, before *string) int {
+		return connectionCost(childComplexity, first, last)
+	}
+	c.User.Following = func(childComplexity int, first *int, after *string, last *int, before *string) int {
+		return connectionCost(childComplexity, first, last)
+	}
+
+	return c
+}
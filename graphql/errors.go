@@ -0,0 +1,95 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"log"
+	"runtime/debug"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/antoniocfetngnu/users-api/middleware"
+	"github.com/antoniocfetngnu/users-api/services"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Error codes reported in extensions.code
+const (
+	CodeBadUserInput    = "BAD_USER_INPUT"
+	CodeUnauthenticated = "UNAUTHENTICATED"
+	CodeForbidden       = "FORBIDDEN"
+	CodeNotFound        = "NOT_FOUND"
+	CodeConflict        = "CONFLICT"
+	CodeInternal        = "INTERNAL"
+
+	// Operations rejected by QueryLimits
+	CodeQueryTooDeep    = "QUERY_TOO_DEEP"
+	CodeQueryTooComplex = "QUERY_TOO_COMPLEX"
+	CodeRateLimited     = "RATE_LIMITED"
+
+	// Ad-hoc query rejected in allowlist mode
+	CodePersistedQueryNotAllowed = "PERSISTED_QUERY_NOT_ALLOWED"
+)
+
+var codeByKind = map[services.Kind]string{
+	services.KindInvalid:         CodeBadUserInput,
+	services.KindUnauthenticated: CodeUnauthenticated,
+	services.KindForbidden:       CodeForbidden,
+	services.KindNotFound:        CodeNotFound,
+	services.KindConflict:        CodeConflict,
+}
+
+// internalMessage replaces the message of unexpected errors in production
+const internalMessage = "Internal server error"
+
+// errPanic is returned for resolvers that panicked, RecoverFunc already
+// logged the details
+var errPanic = errors.New(internalMessage)
+
+// NewErrorPresenter returns the presenter that adds extensions.code to every
+// error so clients can tell them apart without parsing messages:
+//   - domain errors from the service layer get the code of their kind
+//   - errors raised by gqlgen and the extensions (validation, limits, APQ)
+//     are already GraphQL errors and are kept as they are
+//   - anything else (database failures, bugs) is INTERNAL, logged with the
+//     request ID and, when hideInternal is set, reported without details
+func NewErrorPresenter(hideInternal bool) graphql.ErrorPresenterFunc {
+	return func(ctx context.Context, err error) *gqlerror.Error {
+		if code, ok := codeByKind[services.KindOf(err)]; ok {
+			return withCode(graphql.DefaultErrorPresenter(ctx, err), code)
+		}
+
+		// gqlgen wraps resolver errors in a gqlerror.Error, its own errors
+		// have no underlying error
+		gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+		if gqlErr.Unwrap() == nil {
+			return gqlErr
+		}
+
+		requestID := middleware.RequestIDFromContext(ctx)
+		if !errors.Is(err, errPanic) {
+			log.Printf("[%s] GraphQL internal error at %s: %v", requestID, gqlErr.Path, err)
+		}
+
+		if hideInternal {
+			gqlErr.Message = internalMessage
+		}
+		gqlErr = withCode(gqlErr, CodeInternal)
+		gqlErr.Extensions["requestId"] = requestID
+		return gqlErr
+	}
+}
+
+// RecoverFunc turns a resolver panic into an INTERNAL error and logs it
+// with the request ID and stack trace
+func RecoverFunc(ctx context.Context, p any) error {
+	log.Printf("[%s] GraphQL panic at %s: %v\n%s", middleware.RequestIDFromContext(ctx), graphql.GetPath(ctx), p, debug.Stack())
+	return errPanic
+}
+
+func withCode(gqlErr *gqlerror.Error, code string) *gqlerror.Error {
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = map[string]any{}
+	}
+	gqlErr.Extensions["code"] = code
+	return gqlErr
+}
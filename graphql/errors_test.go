@@ -0,0 +1,95 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/antoniocfetngnu/users-api/middleware"
+	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/services"
+)
+
+type presentedError struct {
+	Message    string
+	Extensions struct {
+		Code      string
+		RequestID string `json:"requestId"`
+	}
+}
+
+func firstError(t *testing.T, err error) presentedError {
+	t.Helper()
+
+	var errs []presentedError
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if jsonErr := json.Unmarshal([]byte(err.Error()), &errs); jsonErr != nil || len(errs) == 0 {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return errs[0]
+}
+
+func newProductionClient(userID uint) *client.Client {
+	srv := handler.New(NewExecutableSchema(newConfig(testServices)))
+	srv.AddTransport(transport.POST{})
+	srv.SetErrorPresenter(NewErrorPresenter(true))
+	srv.SetRecoverFunc(RecoverFunc)
+	srv.AroundRootFields(RequireAuth)
+
+	return client.New(srv, func(req *client.Request) {
+		ctx := middleware.WithPrincipal(req.HTTP.Context(), &middleware.Principal{UserID: userID, Role: models.RoleUser})
+		ctx = middleware.WithRequestID(ctx, "req-123")
+		req.HTTP = req.HTTP.WithContext(ctx)
+	})
+}
+
+func TestDomainErrorsHaveCodes(t *testing.T) {
+	setupDB(t, 1)
+
+	var resp map[string]any
+	got := firstError(t, newProductionClient(1).Post(`{ user(id: "42") { username } }`, &resp))
+	if got.Extensions.Code != CodeNotFound || got.Message != services.ErrUserNotFound.Message {
+		t.Fatalf("unexpected error %+v", got)
+	}
+}
+
+func TestInternalErrorsAreHiddenInProduction(t *testing.T) {
+	setupDB(t, 1)
+	sqlDB, _ := testDB.DB()
+	sqlDB.Close()
+
+	var resp map[string]any
+	got := firstError(t, newProductionClient(1).Post(`{ viewer { username } }`, &resp))
+	if got.Extensions.Code != CodeInternal {
+		t.Errorf("code = %s, want %s", got.Extensions.Code, CodeInternal)
+	}
+	if got.Message != internalMessage {
+		t.Errorf("database details leaked: %q", got.Message)
+	}
+	if got.Extensions.RequestID != "req-123" {
+		t.Errorf("requestId = %q, want req-123", got.Extensions.RequestID)
+	}
+}
+
+func TestInternalErrorsKeepDetailsOutsideProduction(t *testing.T) {
+	gqlErr := NewErrorPresenter(false)(context.Background(), errors.New("sql: database is closed"))
+
+	if gqlErr.Message != "sql: database is closed" || gqlErr.Extensions["code"] != CodeInternal {
+		t.Fatalf("unexpected error %+v", gqlErr)
+	}
+}
+
+func TestRecoveredPanicsAreInternal(t *testing.T) {
+	ctx := middleware.WithRequestID(context.Background(), "req-456")
+	gqlErr := NewErrorPresenter(true)(ctx, RecoverFunc(ctx, "boom"))
+
+	if gqlErr.Message != internalMessage || gqlErr.Extensions["code"] != CodeInternal || gqlErr.Extensions["requestId"] != "req-456" {
+		t.Fatalf("unexpected error %+v", gqlErr)
+	}
+}
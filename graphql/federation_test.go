@@ -0,0 +1,68 @@
+package graphql
+
+import (
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/antoniocfetngnu/users-api/config"
+)
+
+const entitiesQuery = `query($representations: [_Any!]!) {
+	_entities(representations: $representations) {
+		... on User { username }
+	}
+}`
+
+func TestEntitiesAreBatched(t *testing.T) {
+	queries := setupDB(t, 3)
+
+	var resp struct {
+		Entities []struct{ Username string } `json:"_entities"`
+	}
+	newClient(1).MustPost(entitiesQuery, &resp, client.Var("representations", []map[string]any{
+		{"__typename": "User", "id": globalID(typeUser, 3)},
+		{"__typename": "User", "id": globalID(typeUser, 1)},
+		{"__typename": "User", "id": "2"},
+	}))
+
+	got := []string{resp.Entities[0].Username, resp.Entities[1].Username, resp.Entities[2].Username}
+	if strings.Join(got, ",") != "user3,user1,user2" {
+		t.Fatalf("entities out of order: %v", got)
+	}
+	if n := atomic.LoadInt32(queries); n != 1 {
+		t.Fatalf("expected 1 query, got %d", n)
+	}
+}
+
+func TestEntitiesUnknownUser(t *testing.T) {
+	setupDB(t, 1)
+
+	var resp map[string]any
+	err := newClient(1).Post(entitiesQuery, &resp, client.Var("representations", []map[string]any{
+		{"__typename": "User", "id": globalID(typeUser, 42)},
+	}))
+	if err == nil || !strings.Contains(err.Error(), "User not found") {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
+
+func TestServiceSDL(t *testing.T) {
+	// _service needs introspection, which NewServer enables
+	resp := post(t, newTestServer(t, &config.Config{}), 0, `{ _service { sdl } }`)
+	if resp.Errors != nil {
+		t.Fatalf("unexpected errors: %s", resp.Errors)
+	}
+
+	var data struct {
+		Service struct{ SDL string } `json:"_service"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(data.Service.SDL, `type User implements Node @key(fields: "id")`) {
+		t.Fatalf("SDL is missing the User entity:\n%s", data.Service.SDL)
+	}
+}
@@ -0,0 +1,197 @@
+package graphql
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/complexity"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const limitsExtension = "limits"
+
+func init() {
+	// Answered with 422 like validation errors, the operation never ran
+	errcode.RegisterErrorType(CodeQueryTooDeep, errcode.KindProtocol)
+	errcode.RegisterErrorType(CodeQueryTooComplex, errcode.KindProtocol)
+}
+
+// QueryLimits is a gqlgen extension that rejects operations nested deeper
+// than MaxDepth or costing more than MaxComplexity, and charges the cost of
+// every accepted operation to the caller's Budget. The figures are reported
+// in the "limits" response extension.
+type QueryLimits struct {
+	MaxDepth      int
+	MaxComplexity int
+	Budget        *CostBudget
+
+	es graphql.ExecutableSchema
+}
+
+// LimitStats is reported in extensions.limits
+type LimitStats struct {
+	Depth         int          `json:"depth"`
+	MaxDepth      int          `json:"maxDepth,omitempty"`
+	Complexity    int          `json:"complexity"`
+	MaxComplexity int          `json:"maxComplexity,omitempty"`
+	Budget        *BudgetStats `json:"budget,omitempty"`
+}
+
+// BudgetStats is the state of the caller's budget after the operation
+type BudgetStats struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+	graphql.ResponseInterceptor
+} = &QueryLimits{}
+
+func (l *QueryLimits) ExtensionName() string {
+	return "QueryLimits"
+}
+
+func (l *QueryLimits) Validate(schema graphql.ExecutableSchema) error {
+	l.es = schema
+	return nil
+}
+
+func (l *QueryLimits) MutateOperationContext(ctx context.Context, opCtx *graphql.OperationContext) *gqlerror.Error {
+	stats := &LimitStats{
+		Depth:         selectionDepth(opCtx.Operation.SelectionSet),
+		MaxDepth:      l.MaxDepth,
+		Complexity:    complexity.Calculate(ctx, l.es, opCtx.Operation, opCtx.Variables),
+		MaxComplexity: l.MaxComplexity,
+	}
+	opCtx.Stats.SetExtension(limitsExtension, stats)
+
+	if l.MaxDepth > 0 && stats.Depth > l.MaxDepth {
+		return limitError(CodeQueryTooDeep, "Query depth %d exceeds the limit of %d", stats.Depth, l.MaxDepth)
+	}
+	if l.MaxComplexity > 0 && stats.Complexity > l.MaxComplexity {
+		return limitError(CodeQueryTooComplex, "Query complexity %d exceeds the limit of %d", stats.Complexity, l.MaxComplexity)
+	}
+
+	// Anonymous callers can only reach register/login/logout
+	principal, err := currentPrincipal(ctx)
+	if l.Budget == nil || err != nil {
+		return nil
+	}
+
+	remaining, resetAt, ok := l.Budget.Spend(principal.UserID, stats.Complexity)
+	stats.Budget = &BudgetStats{Limit: l.Budget.Limit, Remaining: remaining, ResetAt: resetAt}
+	if !ok {
+		gqlErr := limitError(CodeRateLimited, "Query cost budget exceeded, retry after %s", resetAt.Format(time.RFC3339))
+		gqlErr.Extensions["retryAfter"] = int(math.Ceil(time.Until(resetAt).Seconds()))
+		return gqlErr
+	}
+	return nil
+}
+
+func (l *QueryLimits) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if opCtx := graphql.GetOperationContext(ctx); opCtx != nil {
+		if stats, ok := opCtx.Stats.GetExtension(limitsExtension).(*LimitStats); ok {
+			graphql.RegisterExtension(ctx, limitsExtension, stats)
+		}
+	}
+	return next(ctx)
+}
+
+func limitError(code, format string, args ...any) *gqlerror.Error {
+	err := gqlerror.Errorf(format, args...)
+	errcode.Set(err, code)
+	return err
+}
+
+// selectionDepth is the number of nested fields of the deepest path.
+// Introspection fields are ignored so tooling keeps working.
+func selectionDepth(set ast.SelectionSet) int {
+	depth := 0
+	for _, selection := range set {
+		var d int
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name, "__") {
+				continue
+			}
+			d = 1 + selectionDepth(s.SelectionSet)
+		case *ast.FragmentSpread:
+			d = selectionDepth(s.Definition.SelectionSet)
+		case *ast.InlineFragment:
+			d = selectionDepth(s.SelectionSet)
+		}
+		depth = max(depth, d)
+	}
+	return depth
+}
+
+// CostBudget limits the query cost each user may spend per fixed time
+// window. It is kept in memory, so every replica has its own budget.
+type CostBudget struct {
+	Limit  int
+	Window time.Duration
+
+	mu        sync.Mutex
+	windows   map[uint]*costWindow
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type costWindow struct {
+	spent   int
+	resetAt time.Time
+}
+
+// NewCostBudget returns a budget of limit per window for every user
+func NewCostBudget(limit int, window time.Duration) *CostBudget {
+	return &CostBudget{
+		Limit:   limit,
+		Window:  window,
+		windows: map[uint]*costWindow{},
+		now:     time.Now,
+	}
+}
+
+// Spend charges cost to the budget of userID. ok is false, and nothing is
+// charged, when the remaining budget can't cover it.
+func (b *CostBudget) Spend(userID uint, cost int) (remaining int, resetAt time.Time, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.sweep(now)
+
+	w := b.windows[userID]
+	if w == nil || !now.Before(w.resetAt) {
+		w = &costWindow{resetAt: now.Add(b.Window)}
+		b.windows[userID] = w
+	}
+
+	if w.spent+cost > b.Limit {
+		return b.Limit - w.spent, w.resetAt, false
+	}
+	w.spent += cost
+	return b.Limit - w.spent, w.resetAt, true
+}
+
+// sweep drops expired windows, at most once per window
+func (b *CostBudget) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < b.Window {
+		return
+	}
+	for userID, w := range b.windows {
+		if !now.Before(w.resetAt) {
+			delete(b.windows, userID)
+		}
+	}
+	b.lastSweep = now
+}
@@ -0,0 +1,178 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/antoniocfetngnu/users-api/middleware"
+)
+
+func newLimitedServer(limits *QueryLimits) http.Handler {
+	srv := handler.New(NewExecutableSchema(newConfig(testServices)))
+	srv.AddTransport(transport.POST{})
+	srv.Use(limits)
+	srv.SetErrorPresenter(NewErrorPresenter(false))
+	srv.AroundRootFields(RequireAuth)
+	return srv
+}
+
+type rawResponse struct {
+	Status     int
+	Data       json.RawMessage
+	Errors     json.RawMessage
+	Extensions map[string]any
+}
+
+// post runs query as userID. The gqlgen test client can't be used because
+// it fails on the 422 answered to rejected operations.
+func post(t *testing.T, srv http.Handler, userID uint, query string) *rawResponse {
+	t.Helper()
+	return postParams(t, srv, userID, map[string]any{"query": query})
+}
+
+// postParams sends raw GraphQL request parameters as userID
+func postParams(t *testing.T, srv http.Handler, userID uint, params map[string]any) *rawResponse {
+	t.Helper()
+
+	body, _ := json.Marshal(params)
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: userID}))
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	resp := &rawResponse{Status: rec.Code}
+	if err := json.Unmarshal(rec.Body.Bytes(), resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// limitsOf decodes extensions.limits of a raw response
+func limitsOf(t *testing.T, resp *rawResponse) LimitStats {
+	t.Helper()
+
+	var stats LimitStats
+	raw, _ := json.Marshal(resp.Extensions[limitsExtension])
+	if err := json.Unmarshal(raw, &stats); err != nil {
+		t.Fatal(err)
+	}
+	return stats
+}
+
+// errorCode returns extensions.code of the first error of a raw response
+func errorCode(t *testing.T, resp *rawResponse) string {
+	t.Helper()
+
+	var errs []struct {
+		Extensions struct{ Code string }
+	}
+	if err := json.Unmarshal(resp.Errors, &errs); err != nil || len(errs) == 0 {
+		t.Fatalf("expected an error, got %s", resp.Errors)
+	}
+	return errs[0].Extensions.Code
+}
+
+func TestConnectionCostIsWeightedByPageSize(t *testing.T) {
+	setupDB(t, 3)
+	srv := newLimitedServer(&QueryLimits{})
+
+	small := post(t, srv, 1, `{ users(first: 2) { edges { node { username } } } }`)
+	large := post(t, srv, 1, `{ users(first: 50) { edges { node { username } } } }`)
+
+	// users(1 + 3 * items): edges, node and username cost 1 each
+	if got := limitsOf(t, small).Complexity; got != 7 {
+		t.Errorf("first: 2 costs %d, want 7", got)
+	}
+	if got := limitsOf(t, large).Complexity; got != 151 {
+		t.Errorf("first: 50 costs %d, want 151", got)
+	}
+	if got := limitsOf(t, large).Depth; got != 4 {
+		t.Errorf("depth = %d, want 4", got)
+	}
+
+	// Nested connections are priced the same way: viewer(1 + followers)
+	nested := post(t, srv, 1, `{ viewer { followers(first: 40) { edges { node { id } } } } }`)
+	if got := limitsOf(t, nested).Complexity; got != 122 {
+		t.Errorf("viewer.followers(first: 40) costs %d, want 122", got)
+	}
+}
+
+func TestQueryLimitsRejectOperations(t *testing.T) {
+	setupDB(t, 3)
+
+	tests := []struct {
+		name   string
+		limits *QueryLimits
+		code   string
+	}{
+		{"too deep", &QueryLimits{MaxDepth: 3}, CodeQueryTooDeep},
+		{"too complex", &QueryLimits{MaxComplexity: 100}, CodeQueryTooComplex},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := post(t, newLimitedServer(tt.limits), 1, `{ users(first: 50) { edges { node { username } } } }`)
+			if resp.Status != http.StatusUnprocessableEntity {
+				t.Errorf("status = %d, want 422", resp.Status)
+			}
+			if code := errorCode(t, resp); code != tt.code {
+				t.Errorf("code = %s, want %s", code, tt.code)
+			}
+			if string(resp.Data) != "null" {
+				t.Errorf("operation should not run, got %s", resp.Data)
+			}
+			if limitsOf(t, resp).Complexity != 151 {
+				t.Errorf("limits should be reported on rejection, got %v", resp.Extensions)
+			}
+		})
+	}
+}
+
+func TestCostBudgetIsPerPrincipal(t *testing.T) {
+	setupDB(t, 3)
+	srv := newLimitedServer(&QueryLimits{Budget: NewCostBudget(10, time.Minute)})
+	query := `{ users(first: 2) { edges { node { username } } } }` // costs 7
+
+	first := post(t, srv, 1, query)
+	if first.Errors != nil {
+		t.Fatalf("unexpected errors: %s", first.Errors)
+	}
+	if budget := limitsOf(t, first).Budget; budget == nil || budget.Remaining != 3 {
+		t.Fatalf("budget = %+v, want 3 remaining", budget)
+	}
+
+	second := post(t, srv, 1, query)
+	if code := errorCode(t, second); code != CodeRateLimited {
+		t.Errorf("code = %s, want %s", code, CodeRateLimited)
+	}
+
+	other := post(t, srv, 2, query)
+	if other.Errors != nil {
+		t.Errorf("another user has its own budget, got %s", other.Errors)
+	}
+}
+
+func TestCostBudgetResetsAfterWindow(t *testing.T) {
+	now := time.Now()
+	budget := NewCostBudget(10, time.Minute)
+	budget.now = func() time.Time { return now }
+
+	if _, _, ok := budget.Spend(1, 8); !ok {
+		t.Fatal("first spend should fit")
+	}
+	if _, _, ok := budget.Spend(1, 8); ok {
+		t.Fatal("second spend should exceed the budget")
+	}
+
+	now = now.Add(time.Minute)
+	if remaining, _, ok := budget.Spend(1, 8); !ok || remaining != 2 {
+		t.Fatalf("spend after reset: remaining=%d ok=%v", remaining, ok)
+	}
+}
@@ -0,0 +1,107 @@
+package loaders
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxBatch caps the number of keys sent to a single fetch
+const maxBatch = 500
+
+// FetchFunc loads a batch of keys. Keys missing from the returned map
+// resolve to the zero value of V.
+type FetchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader batches and caches lookups by key for the lifetime of a request.
+// Loads issued within the wait window are coalesced into one fetch.
+type Loader[K comparable, V any] struct {
+	fetch FetchFunc[K, V]
+	wait  time.Duration
+
+	mu      sync.Mutex
+	cache   map[K]*result[V]
+	pending *batch[K, V]
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+type batch[K comparable, V any] struct {
+	keys    []K
+	results []*result[V]
+}
+
+// NewLoader returns a loader that waits up to wait before fetching a batch
+func NewLoader[K comparable, V any](wait time.Duration, fetch FetchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch: fetch,
+		wait:  wait,
+		cache: map[K]*result[V]{},
+	}
+}
+
+// Load returns the value for key, fetching it together with the other keys
+// requested in the same window
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	res, ok := l.cache[key]
+	if !ok {
+		res = &result[V]{done: make(chan struct{})}
+		l.cache[key] = res
+		l.enqueue(ctx, key, res)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-res.done:
+		return res.value, res.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// enqueue adds key to the pending batch; l.mu must be held
+func (l *Loader[K, V]) enqueue(ctx context.Context, key K, res *result[V]) {
+	if l.pending == nil {
+		b := &batch[K, V]{}
+		l.pending = b
+		time.AfterFunc(l.wait, func() {
+			l.mu.Lock()
+			if l.pending != b {
+				// Already dispatched because it was full
+				l.mu.Unlock()
+				return
+			}
+			l.pending = nil
+			l.mu.Unlock()
+			l.dispatch(ctx, b)
+		})
+	}
+
+	b := l.pending
+	b.keys = append(b.keys, key)
+	b.results = append(b.results, res)
+
+	if len(b.keys) >= maxBatch {
+		l.pending = nil
+		go l.dispatch(ctx, b)
+	}
+}
+
+func (l *Loader[K, V]) dispatch(ctx context.Context, b *batch[K, V]) {
+	values, err := l.fetch(ctx, b.keys)
+	for i, key := range b.keys {
+		res := b.results[i]
+		if err != nil {
+			res.err = err
+		} else {
+			res.value = values[key]
+		}
+		close(res.done)
+	}
+}
@@ -0,0 +1,134 @@
+package loaders
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/antoniocfetngnu/users-api/services"
+)
+
+func TestLoaderBatchesConcurrentLoads(t *testing.T) {
+	var calls int32
+	var fetched []int
+	loader := NewLoader(10*time.Millisecond, func(ctx context.Context, keys []int) (map[int]string, error) {
+		atomic.AddInt32(&calls, 1)
+		fetched = keys
+		values := map[int]string{}
+		for _, k := range keys {
+			if k != 3 {
+				values[k] = string(rune('a' + k))
+			}
+		}
+		return values, nil
+	})
+
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := loader.Load(context.Background(), i)
+			if err != nil {
+				t.Errorf("Load(%d): %v", i, err)
+			}
+			results[i] = v
+		}(i)
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("expected 1 fetch, got %d", calls)
+	}
+	if len(fetched) != 5 {
+		t.Fatalf("expected 5 keys in the batch, got %v", fetched)
+	}
+	want := []string{"a", "b", "c", "", "e"}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("Load(%d) = %q, want %q", i, results[i], want[i])
+		}
+	}
+}
+
+func TestLoaderCachesKeys(t *testing.T) {
+	var calls int32
+	loader := NewLoader(time.Millisecond, func(ctx context.Context, keys []int) (map[int]int, error) {
+		atomic.AddInt32(&calls, 1)
+		values := map[int]int{}
+		for _, k := range keys {
+			values[k] = k * 2
+		}
+		return values, nil
+	})
+
+	for i := 0; i < 3; i++ {
+		v, err := loader.Load(context.Background(), 21)
+		if err != nil || v != 42 {
+			t.Fatalf("Load(21) = %d, %v", v, err)
+		}
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected 1 fetch, got %d", calls)
+	}
+}
+
+func TestLoaderSplitsFullBatches(t *testing.T) {
+	var calls int32
+	loader := NewLoader(time.Hour, func(ctx context.Context, keys []int) (map[int]int, error) {
+		atomic.AddInt32(&calls, 1)
+		return map[int]int{}, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < maxBatch; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			loader.Load(context.Background(), i)
+		}(i)
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("expected a full batch to be fetched without waiting, got %d fetches", calls)
+	}
+}
+
+func TestLoaderPropagatesErrors(t *testing.T) {
+	errFetch := errors.New("boom")
+	loader := NewLoader(time.Millisecond, func(ctx context.Context, keys []int) (map[int]int, error) {
+		return nil, errFetch
+	})
+
+	if _, err := loader.Load(context.Background(), 1); !errors.Is(err, errFetch) {
+		t.Fatalf("expected fetch error, got %v", err)
+	}
+}
+
+func TestPageKeyComparesArgsByValue(t *testing.T) {
+	first, otherFirst, zero := 10, 10, 0
+	after := uint(5)
+
+	a := NewPageKey(1, services.PageArgs{First: &first, After: &after})
+	b := NewPageKey(1, services.PageArgs{First: &otherFirst, After: &after})
+	if a != b {
+		t.Fatalf("keys of equal arguments differ: %+v, %+v", a, b)
+	}
+	if a == NewPageKey(2, services.PageArgs{First: &first, After: &after}) {
+		t.Fatal("keys of different users are equal")
+	}
+	if NewPageKey(1, services.PageArgs{First: &zero}) == NewPageKey(1, services.PageArgs{}) {
+		t.Fatal("first: 0 has the key of a missing first")
+	}
+
+	args := a.Args()
+	if *args.First != 10 || *args.After != 5 || args.Last != nil || args.Before != nil {
+		t.Fatalf("Args() = %+v", args)
+	}
+}
@@ -0,0 +1,150 @@
+// Package loaders provides per-request DataLoaders so nested GraphQL fields
+// are resolved with one query per level instead of one query per row.
+package loaders
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/services"
+)
+
+// DefaultWait is how long a loader collects keys before fetching them
+const DefaultWait = 2 * time.Millisecond
+
+// Loaders groups the DataLoaders of a single request
+type Loaders struct {
+	UserByID       *Loader[uint, *models.User]
+	FollowersPage  *Loader[PageKey, *services.Page[*models.Follower]]
+	FollowingPage  *Loader[PageKey, *services.Page[*models.Follower]]
+	FollowerCount  *Loader[uint, int]
+	FollowingCount *Loader[uint, int]
+	IsFollowing    *Loader[services.FollowPair, bool]
+}
+
+// New returns a fresh set of loaders backed by the service layer
+func New(wait time.Duration, users services.UserService, follows services.FollowService) *Loaders {
+	return &Loaders{
+		UserByID: NewLoader(wait, func(ctx context.Context, ids []uint) (map[uint]*models.User, error) {
+			found, err := users.GetUsersByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[uint]*models.User, len(found))
+			for _, user := range found {
+				byID[user.ID] = user
+			}
+			return byID, nil
+		}),
+		FollowersPage: NewLoader(wait, func(ctx context.Context, keys []PageKey) (map[PageKey]*services.Page[*models.Follower], error) {
+			return loadPages(ctx, keys, follows.ListFollowersPage)
+		}),
+		FollowingPage: NewLoader(wait, func(ctx context.Context, keys []PageKey) (map[PageKey]*services.Page[*models.Follower], error) {
+			return loadPages(ctx, keys, follows.ListFollowingPage)
+		}),
+		FollowerCount: NewLoader(wait, func(ctx context.Context, ids []uint) (map[uint]int, error) {
+			return follows.CountFollowersByUsers(ctx, ids)
+		}),
+		FollowingCount: NewLoader(wait, func(ctx context.Context, ids []uint) (map[uint]int, error) {
+			return follows.CountFollowingByUsers(ctx, ids)
+		}),
+		IsFollowing: NewLoader(wait, func(ctx context.Context, pairs []services.FollowPair) (map[services.FollowPair]bool, error) {
+			return follows.CheckFollows(ctx, pairs)
+		}),
+	}
+}
+
+type loadersKey struct{}
+
+// NewContext returns a copy of ctx carrying the loaders
+func NewContext(ctx context.Context, l *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+// FromContext returns the loaders of the current request
+func FromContext(ctx context.Context) (*Loaders, bool) {
+	l, ok := ctx.Value(loadersKey{}).(*Loaders)
+	return l, ok
+}
+
+// Middleware returns a gqlgen response middleware that attaches a fresh set
+// of loaders to every response. It also runs once per subscription event,
+// so long-lived WebSocket operations never serve rows cached by earlier
+// events.
+func Middleware(users services.UserService, follows services.FollowService) graphql.ResponseMiddleware {
+	return func(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+		return next(NewContext(ctx, New(DefaultWait, users, follows)))
+	}
+}
+
+// LoadUser returns the user with the given ID, or services.ErrUserNotFound
+func (l *Loaders) LoadUser(ctx context.Context, id uint) (*models.User, error) {
+	user, err := l.UserByID.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, services.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// PageKey selects a page of a user's list. It holds the page arguments by
+// value, since services.PageArgs compares its pointers.
+type PageKey struct {
+	UserID        uint
+	first, last   optional[int]
+	after, before optional[uint]
+}
+
+type optional[T comparable] struct {
+	value T
+	set   bool
+}
+
+func optionalOf[T comparable](p *T) optional[T] {
+	if p == nil {
+		return optional[T]{}
+	}
+	return optional[T]{value: *p, set: true}
+}
+
+func (o optional[T]) ptr() *T {
+	if !o.set {
+		return nil
+	}
+	return &o.value
+}
+
+// NewPageKey returns the key of the page of userID's list selected by args
+func NewPageKey(userID uint, args services.PageArgs) PageKey {
+	return PageKey{
+		UserID: userID,
+		first:  optionalOf(args.First),
+		last:   optionalOf(args.Last),
+		after:  optionalOf(args.After),
+		before: optionalOf(args.Before),
+	}
+}
+
+// Args returns the page arguments of the key
+func (k PageKey) Args() services.PageArgs {
+	return services.PageArgs{First: k.first.ptr(), After: k.after.ptr(), Last: k.last.ptr(), Before: k.before.ptr()}
+}
+
+// loadPages reads the pages of a batch one at a time. There is no query
+// for pages of several users at once, but the batch still resolves
+// together, so the fields below the pages are loaded in one batch too.
+func loadPages[T any](ctx context.Context, keys []PageKey, list func(context.Context, uint, services.PageArgs) (*services.Page[T], error)) (map[PageKey]*services.Page[T], error) {
+	pages := make(map[PageKey]*services.Page[T], len(keys))
+	for _, key := range keys {
+		page, err := list(ctx, key.UserID, key.Args())
+		if err != nil {
+			return nil, err
+		}
+		pages[key] = page
+	}
+	return pages, nil
+}
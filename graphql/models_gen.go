@@ -2,5 +2,110 @@
 
 package graphql
 
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/antoniocfetngnu/users-api/models"
+)
+
+type FollowerConnection struct {
+	Edges      []*FollowerEdge `json:"edges"`
+	PageInfo   *PageInfo       `json:"pageInfo"`
+	TotalCount int             `json:"totalCount"`
+}
+
+type FollowerEdge struct {
+	Cursor string           `json:"cursor"`
+	Node   *models.Follower `json:"node"`
+}
+
+type Mutation struct {
+}
+
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor,omitempty"`
+	EndCursor       *string `json:"endCursor,omitempty"`
+}
+
 type Query struct {
 }
+
+type Subscription struct {
+}
+
+type UserConnection struct {
+	Edges      []*UserEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int         `json:"totalCount"`
+}
+
+type UserEdge struct {
+	Cursor string       `json:"cursor"`
+	Node   *models.User `json:"node"`
+}
+
+// Who can read a field
+type Visibility string
+
+const (
+	// Any authenticated user
+	VisibilityAuthenticated Visibility = "AUTHENTICATED"
+	// The user the object belongs to, and admins
+	VisibilityOwner Visibility = "OWNER"
+	// Admins only
+	VisibilityAdmin Visibility = "ADMIN"
+)
+
+var AllVisibility = []Visibility{
+	VisibilityAuthenticated,
+	VisibilityOwner,
+	VisibilityAdmin,
+}
+
+func (e Visibility) IsValid() bool {
+	switch e {
+	case VisibilityAuthenticated, VisibilityOwner, VisibilityAdmin:
+		return true
+	}
+	return false
+}
+
+func (e Visibility) String() string {
+	return string(e)
+}
+
+func (e *Visibility) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Visibility(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Visibility", str)
+	}
+	return nil
+}
+
+func (e Visibility) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *Visibility) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e Visibility) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
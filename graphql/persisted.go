@@ -0,0 +1,85 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func init() {
+	errcode.RegisterErrorType(CodePersistedQueryNotAllowed, errcode.KindProtocol)
+}
+
+// PersistedQueries is an allowlist of approved query documents keyed by
+// the hex sha256 of their text, the same hash clients send for APQ.
+//
+// It is both the read-only cache of the APQ extension, so clients can send
+// just the hash, and an extension that rejects any other query text.
+type PersistedQueries map[string]string
+
+var _ interface {
+	graphql.Cache[string]
+	graphql.HandlerExtension
+	graphql.OperationParameterMutator
+} = PersistedQueries{}
+
+// LoadPersistedQueries reads a manifest of approved documents, a JSON
+// object of {"<sha256>": "<query>"} as written by graphql-codegen's
+// persisted documents. Every hash is checked against its document.
+func LoadPersistedQueries(path string) (PersistedQueries, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var queries PersistedQueries
+	if err := json.Unmarshal(data, &queries); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for hash, query := range queries {
+		if queryHash(query) != hash {
+			return nil, fmt.Errorf("parse %s: hash %s does not match its query", path, hash)
+		}
+	}
+	return queries, nil
+}
+
+// Get returns the approved query with the given hash
+func (q PersistedQueries) Get(ctx context.Context, hash string) (string, bool) {
+	query, ok := q[hash]
+	return query, ok
+}
+
+// Add is a no-op, APQ can't register queries in allowlist mode
+func (q PersistedQueries) Add(ctx context.Context, hash, query string) {}
+
+func (q PersistedQueries) ExtensionName() string {
+	return "PersistedQueryAllowlist"
+}
+
+func (q PersistedQueries) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationParameters runs after APQ has resolved hashes, so the
+// query text is always set here
+func (q PersistedQueries) MutateOperationParameters(ctx context.Context, params *graphql.RawParams) *gqlerror.Error {
+	if _, ok := q[queryHash(params.Query)]; !ok {
+		err := gqlerror.Errorf("Only persisted queries are allowed")
+		errcode.Set(err, CodePersistedQueryNotAllowed)
+		return err
+	}
+	return nil
+}
+
+func queryHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
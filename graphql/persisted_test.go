@@ -0,0 +1,113 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/antoniocfetngnu/users-api/config"
+)
+
+const approvedQuery = `{ viewer { username } }`
+
+func persistedQuery(hash string) map[string]any {
+	return map[string]any{"persistedQuery": map[string]any{"version": 1, "sha256Hash": hash}}
+}
+
+func writeManifest(t *testing.T, queries map[string]string) string {
+	t.Helper()
+
+	data, _ := json.Marshal(queries)
+	path := filepath.Join(t.TempDir(), "persisted-queries.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newTestServer(t *testing.T, cfg *config.Config) http.Handler {
+	t.Helper()
+
+	cfg.GraphQLAPQCacheSize = 100
+	srv, err := NewServer(cfg, testServices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv
+}
+
+func TestAutomaticPersistedQueries(t *testing.T) {
+	setupDB(t, 2)
+	srv := newTestServer(t, &config.Config{})
+	hash := queryHash(approvedQuery)
+
+	resp := postParams(t, srv, 1, map[string]any{"extensions": persistedQuery(hash)})
+	if code := errorCode(t, resp); code != "PERSISTED_QUERY_NOT_FOUND" {
+		t.Fatalf("code = %s, want PERSISTED_QUERY_NOT_FOUND", code)
+	}
+
+	resp = postParams(t, srv, 1, map[string]any{"query": approvedQuery, "extensions": persistedQuery(hash)})
+	if resp.Errors != nil {
+		t.Fatalf("registering the query failed: %s", resp.Errors)
+	}
+
+	resp = postParams(t, srv, 1, map[string]any{"extensions": persistedQuery(hash)})
+	if resp.Errors != nil || string(resp.Data) != `{"viewer":{"username":"user1"}}` {
+		t.Fatalf("hash only request: data=%s errors=%s", resp.Data, resp.Errors)
+	}
+}
+
+func TestAllowlistOnly(t *testing.T) {
+	setupDB(t, 2)
+	hash := queryHash(approvedQuery)
+	srv := newTestServer(t, &config.Config{
+		GraphQLPersistedQueries: writeManifest(t, map[string]string{hash: approvedQuery}),
+	})
+
+	t.Run("approved hash", func(t *testing.T) {
+		resp := postParams(t, srv, 1, map[string]any{"extensions": persistedQuery(hash)})
+		if resp.Errors != nil || string(resp.Data) != `{"viewer":{"username":"user1"}}` {
+			t.Fatalf("data=%s errors=%s", resp.Data, resp.Errors)
+		}
+	})
+
+	t.Run("approved text", func(t *testing.T) {
+		resp := post(t, srv, 1, approvedQuery)
+		if resp.Errors != nil {
+			t.Fatalf("unexpected errors: %s", resp.Errors)
+		}
+	})
+
+	t.Run("ad-hoc query", func(t *testing.T) {
+		resp := post(t, srv, 1, `{ viewer { email } }`)
+		if resp.Status != http.StatusUnprocessableEntity {
+			t.Errorf("status = %d, want 422", resp.Status)
+		}
+		if code := errorCode(t, resp); code != CodePersistedQueryNotAllowed {
+			t.Errorf("code = %s, want %s", code, CodePersistedQueryNotAllowed)
+		}
+	})
+
+	t.Run("APQ can't register queries", func(t *testing.T) {
+		adHoc := `{ viewer { email } }`
+		resp := postParams(t, srv, 1, map[string]any{"query": adHoc, "extensions": persistedQuery(queryHash(adHoc))})
+		if code := errorCode(t, resp); code != CodePersistedQueryNotAllowed {
+			t.Fatalf("code = %s, want %s", code, CodePersistedQueryNotAllowed)
+		}
+
+		resp = postParams(t, srv, 1, map[string]any{"extensions": persistedQuery(queryHash(adHoc))})
+		if code := errorCode(t, resp); code != "PERSISTED_QUERY_NOT_FOUND" {
+			t.Fatalf("code = %s, want PERSISTED_QUERY_NOT_FOUND", code)
+		}
+	})
+}
+
+func TestLoadPersistedQueriesChecksHashes(t *testing.T) {
+	path := writeManifest(t, map[string]string{queryHash(approvedQuery): `{ viewer { email } }`})
+
+	if _, err := LoadPersistedQueries(path); err == nil {
+		t.Fatal("expected a hash mismatch error")
+	}
+}
@@ -0,0 +1,144 @@
+package graphql
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/services"
+)
+
+// Node is any object that implements the Node interface (*models.User,
+// *models.Follower)
+type Node any
+
+// Global ID type names
+const (
+	typeUser     = "User"
+	typeFollower = "Follower"
+)
+
+// globalID returns the opaque Relay ID of an object: base64("Type:id")
+func globalID(typeName string, id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%d", typeName, id)))
+}
+
+// parseGlobalID splits a global ID into its type name and database ID
+func parseGlobalID(id string) (string, uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil {
+		return "", 0, services.Invalid("Invalid ID")
+	}
+
+	typeName, dbID, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return "", 0, services.Invalid("Invalid ID")
+	}
+
+	n, err := strconv.ParseUint(dbID, 10, 32)
+	if err != nil {
+		return "", 0, services.Invalid("Invalid ID")
+	}
+	return typeName, uint(n), nil
+}
+
+// decodeID accepts either a global ID of the given type or a plain numeric
+// database ID (as used by the REST API and older clients)
+func decodeID(id, typeName string) (uint, error) {
+	if n, err := strconv.ParseUint(id, 10, 32); err == nil {
+		return uint(n), nil
+	}
+
+	gotType, dbID, err := parseGlobalID(id)
+	if err != nil {
+		return 0, err
+	}
+	if gotType != typeName {
+		return 0, services.Invalid(fmt.Sprintf("Expected a %s ID", typeName))
+	}
+	return dbID, nil
+}
+
+// encodeCursor returns the opaque cursor of a list item
+func encodeCursor(id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("cursor:%d", id)))
+}
+
+// decodeCursor parses a cursor returned by encodeCursor
+func decodeCursor(cursor *string) (*uint, error) {
+	if cursor == nil {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(*cursor)
+	if err != nil {
+		return nil, services.Invalid("Invalid cursor")
+	}
+
+	n, err := strconv.ParseUint(strings.TrimPrefix(string(raw), "cursor:"), 10, 32)
+	if err != nil {
+		return nil, services.Invalid("Invalid cursor")
+	}
+	id := uint(n)
+	return &id, nil
+}
+
+// pageArgs converts connection arguments to service page arguments
+func pageArgs(first *int, after *string, last *int, before *string) (services.PageArgs, error) {
+	afterID, err := decodeCursor(after)
+	if err != nil {
+		return services.PageArgs{}, err
+	}
+
+	beforeID, err := decodeCursor(before)
+	if err != nil {
+		return services.PageArgs{}, err
+	}
+
+	return services.PageArgs{First: first, After: afterID, Last: last, Before: beforeID}, nil
+}
+
+// pageInfo builds the PageInfo of a page whose items have the given IDs
+func pageInfo[T any](page *services.Page[T], id func(T) uint) *PageInfo {
+	info := &PageInfo{
+		HasNextPage:     page.HasNextPage,
+		HasPreviousPage: page.HasPreviousPage,
+	}
+	if len(page.Items) > 0 {
+		start := encodeCursor(id(page.Items[0]))
+		end := encodeCursor(id(page.Items[len(page.Items)-1]))
+		info.StartCursor = &start
+		info.EndCursor = &end
+	}
+	return info
+}
+
+// userConnection builds a UserConnection from a page of users
+func userConnection(page *services.Page[*models.User]) *UserConnection {
+	edges := make([]*UserEdge, len(page.Items))
+	for i, user := range page.Items {
+		edges[i] = &UserEdge{Cursor: encodeCursor(user.ID), Node: user}
+	}
+
+	return &UserConnection{
+		Edges:      edges,
+		PageInfo:   pageInfo(page, func(u *models.User) uint { return u.ID }),
+		TotalCount: page.TotalCount,
+	}
+}
+
+// followerConnection builds a FollowerConnection from a page of edges
+func followerConnection(page *services.Page[*models.Follower]) *FollowerConnection {
+	edges := make([]*FollowerEdge, len(page.Items))
+	for i, follower := range page.Items {
+		edges[i] = &FollowerEdge{Cursor: encodeCursor(follower.ID), Node: follower}
+	}
+
+	return &FollowerConnection{
+		Edges:      edges,
+		PageInfo:   pageInfo(page, func(f *models.Follower) uint { return f.ID }),
+		TotalCount: page.TotalCount,
+	}
+}
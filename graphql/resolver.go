@@ -5,229 +5,420 @@ package graphql
 import (
 	"context"
 	"strconv"
-	"strings"
 
-	"github.com/antoniocfetngnu/users-api/database"
+	"github.com/antoniocfetngnu/users-api/events"
+	"github.com/antoniocfetngnu/users-api/graphql/loaders"
+	"github.com/antoniocfetngnu/users-api/middleware"
 	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/services"
+	"github.com/antoniocfetngnu/users-api/utils"
 )
 
-type Resolver struct{}
+type Resolver struct {
+	users   services.UserService
+	follows services.FollowService
+}
+
+// FindUserByID is the resolver for the findUserByID field.
+func (r *entityResolver) FindUserByID(ctx context.Context, id string) (*models.User, error) {
+	userID, err := decodeID(id, typeUser)
+	if err != nil {
+		return nil, err
+	}
+
+	// The gateway sends every representation in one _entities call, the
+	// loader turns them into a single query
+	return r.loadersFor(ctx).LoadUser(ctx, userID)
+}
 
 // Follower field resolvers
 func (r *followerResolver) ID(ctx context.Context, obj *models.Follower) (string, error) {
-	return strconv.FormatUint(uint64(obj.ID), 10), nil
+	return globalID(typeFollower, obj.ID), nil
 }
 
 func (r *followerResolver) FollowerID(ctx context.Context, obj *models.Follower) (string, error) {
-	return strconv.FormatUint(uint64(obj.FollowerID), 10), nil
+	return globalID(typeUser, obj.FollowerID), nil
 }
 
 func (r *followerResolver) FollowedID(ctx context.Context, obj *models.Follower) (string, error) {
-	return strconv.FormatUint(uint64(obj.FollowedID), 10), nil
+	return globalID(typeUser, obj.FollowedID), nil
+}
+
+// Follower is the resolver for the follower field.
+func (r *followerResolver) Follower(ctx context.Context, obj *models.Follower) (*models.User, error) {
+	return r.loadersFor(ctx).LoadUser(ctx, obj.FollowerID)
+}
+
+// Followed is the resolver for the followed field.
+func (r *followerResolver) Followed(ctx context.Context, obj *models.Follower) (*models.User, error) {
+	return r.loadersFor(ctx).LoadUser(ctx, obj.FollowedID)
+}
+
+// Register is the resolver for the register field.
+func (r *mutationResolver) Register(ctx context.Context, input models.RegisterRequest) (*models.User, error) {
+	return r.users.Register(ctx, input)
+}
+
+// Login is the resolver for the login field.
+func (r *mutationResolver) Login(ctx context.Context, input models.LoginRequest) (*models.User, error) {
+	c, err := middleware.GinContextFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, token, err := r.users.Login(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	utils.SetAuthCookie(c, token)
+	return user, nil
+}
+
+// Logout is the resolver for the logout field.
+func (r *mutationResolver) Logout(ctx context.Context) (bool, error) {
+	c, err := middleware.GinContextFromContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	utils.ClearAuthCookie(c)
+	return true, nil
 }
 
-func (r *followerResolver) FollowedSince(ctx context.Context, obj *models.Follower) (string, error) {
-	return obj.FollowedSince.Format("2006-01-02T15:04:05Z07:00"), nil
+// UpdateProfile is the resolver for the updateProfile field.
+func (r *mutationResolver) UpdateProfile(ctx context.Context, input models.UpdateUserRequest) (*models.User, error) {
+	principal, err := currentPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.users.UpdateUser(ctx, principal.UserID, input)
+}
+
+// ChangePassword is the resolver for the changePassword field.
+func (r *mutationResolver) ChangePassword(ctx context.Context, input models.ChangePasswordRequest) (bool, error) {
+	principal, err := currentPrincipal(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.users.ChangePassword(ctx, principal.UserID, input); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Follow is the resolver for the follow field.
+func (r *mutationResolver) Follow(ctx context.Context, userID string) (*models.Follower, error) {
+	principal, err := currentPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	followedID, err := decodeID(userID, typeUser)
+	if err != nil {
+		return nil, err
+	}
+
+	follower, _, err := r.follows.Follow(ctx, principal.UserID, followedID)
+	return follower, err
+}
+
+// Unfollow is the resolver for the unfollow field.
+func (r *mutationResolver) Unfollow(ctx context.Context, userID string) (bool, error) {
+	principal, err := currentPrincipal(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	followedID, err := decodeID(userID, typeUser)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := r.follows.Unfollow(ctx, principal.UserID, followedID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteAccount is the resolver for the deleteAccount field.
+func (r *mutationResolver) DeleteAccount(ctx context.Context) (bool, error) {
+	c, err := middleware.GinContextFromContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	principal, err := currentPrincipal(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.users.DeleteUser(ctx, principal.UserID); err != nil {
+		return false, err
+	}
+
+	utils.ClearAuthCookie(c)
+	return true, nil
+}
+
+// Node is the resolver for the node field.
+func (r *queryResolver) Node(ctx context.Context, id string) (Node, error) {
+	typeName, dbID, err := parseGlobalID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch typeName {
+	case typeUser:
+		return r.loadersFor(ctx).LoadUser(ctx, dbID)
+	case typeFollower:
+		return r.follows.GetFollower(ctx, dbID)
+	default:
+		return nil, services.Invalid("Unknown ID type " + typeName)
+	}
+}
+
+// Viewer is the resolver for the viewer field.
+func (r *queryResolver) Viewer(ctx context.Context) (*models.User, error) {
+	principal, err := currentPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.users.GetUser(ctx, principal.UserID)
 }
 
 // Users resolver
-func (r *queryResolver) Users(ctx context.Context) ([]*models.User, error) {
-	var users []*models.User
-	if err := database.DB.Find(&users).Error; err != nil {
+func (r *queryResolver) Users(ctx context.Context, first *int, after *string, last *int, before *string) (*UserConnection, error) {
+	args, err := pageArgs(first, after, last, before)
+	if err != nil {
 		return nil, err
 	}
-	return users, nil
+
+	page, err := r.users.ListUsers(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return userConnection(page), nil
 }
 
 // User by ID resolver
 func (r *queryResolver) User(ctx context.Context, id string) (*models.User, error) {
-	userID, err := strconv.ParseUint(id, 10, 32)
+	userID, err := decodeID(id, typeUser)
 	if err != nil {
 		return nil, err
 	}
 
-	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		return nil, err
-	}
-	return &user, nil
+	return r.users.GetUser(ctx, userID)
 }
 
 // User by username resolver
 func (r *queryResolver) UserByUsername(ctx context.Context, username string) (*models.User, error) {
-	var user models.User
-	if err := database.DB.Where("username = ?", username).First(&user).Error; err != nil {
-		return nil, err
-	}
-	return &user, nil
+	return r.users.GetUserByUsername(ctx, username)
 }
 
 // User by email resolver
 func (r *queryResolver) UserByEmail(ctx context.Context, email string) (*models.User, error) {
-	var user models.User
-	if err := database.DB.Where("email = ?", email).First(&user).Error; err != nil {
-		return nil, err
-	}
-	return &user, nil
+	return r.users.GetUserByEmail(ctx, email)
 }
 
 // Search users resolver
-func (r *queryResolver) SearchUsers(ctx context.Context, query string) ([]*models.User, error) {
-	var users []*models.User
-	searchQuery := "%" + strings.ToLower(query) + "%"
-
-	if err := database.DB.Where(
-		"LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ? OR LOWER(username) LIKE ?",
-		searchQuery, searchQuery, searchQuery,
-	).Find(&users).Error; err != nil {
+func (r *queryResolver) SearchUsers(ctx context.Context, query string, first *int, after *string, last *int, before *string) (*UserConnection, error) {
+	args, err := pageArgs(first, after, last, before)
+	if err != nil {
 		return nil, err
 	}
 
-	return users, nil
+	page, err := r.users.SearchUsers(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return userConnection(page), nil
 }
 
 // Get all users that a specific user follows
-func (r *queryResolver) Following(ctx context.Context, userID string) ([]*models.Follower, error) {
-	id, err := strconv.ParseUint(userID, 10, 32)
+func (r *queryResolver) Following(ctx context.Context, userID string, first *int, after *string, last *int, before *string) (*FollowerConnection, error) {
+	id, err := decodeID(userID, typeUser)
 	if err != nil {
 		return nil, err
 	}
 
-	var followers []*models.Follower
-	if err := database.DB.
-		Preload("Follower").
-		Preload("Followed").
-		Where("follower_id = ?", id).
-		Find(&followers).Error; err != nil {
+	args, err := pageArgs(first, after, last, before)
+	if err != nil {
 		return nil, err
 	}
 
-	return followers, nil
+	page, err := r.follows.ListFollowingPage(ctx, id, args)
+	if err != nil {
+		return nil, err
+	}
+	return followerConnection(page), nil
 }
 
 // Get all followers of a specific user
-func (r *queryResolver) Followers(ctx context.Context, userID string) ([]*models.Follower, error) {
-	id, err := strconv.ParseUint(userID, 10, 32)
+func (r *queryResolver) Followers(ctx context.Context, userID string, first *int, after *string, last *int, before *string) (*FollowerConnection, error) {
+	id, err := decodeID(userID, typeUser)
 	if err != nil {
 		return nil, err
 	}
 
-	var followers []*models.Follower
-	if err := database.DB.
-		Preload("Follower").
-		Preload("Followed").
-		Where("followed_id = ?", id).
-		Find(&followers).Error; err != nil {
+	args, err := pageArgs(first, after, last, before)
+	if err != nil {
 		return nil, err
 	}
 
-	return followers, nil
+	page, err := r.follows.ListFollowersPage(ctx, id, args)
+	if err != nil {
+		return nil, err
+	}
+	return followerConnection(page), nil
 }
 
 // Check if userA follows userB
 func (r *queryResolver) IsFollowing(ctx context.Context, followerID string, followedID string) (bool, error) {
-	fID, err := strconv.ParseUint(followerID, 10, 32)
+	fID, err := decodeID(followerID, typeUser)
 	if err != nil {
 		return false, err
 	}
 
-	fdID, err := strconv.ParseUint(followedID, 10, 32)
+	fdID, err := decodeID(followedID, typeUser)
 	if err != nil {
 		return false, err
 	}
 
-	var count int64
-	if err := database.DB.Model(&models.Follower{}).
-		Where("follower_id = ? AND followed_id = ?", fID, fdID).
-		Count(&count).Error; err != nil {
-		return false, err
-	}
-
-	return count > 0, nil
+	return r.loadersFor(ctx).IsFollowing.Load(ctx, services.FollowPair{FollowerID: fID, FollowedID: fdID})
 }
 
 // Get follower relationship details
 func (r *queryResolver) FollowerRelationship(ctx context.Context, followerID string, followedID string) (*models.Follower, error) {
-	fID, err := strconv.ParseUint(followerID, 10, 32)
+	fID, err := decodeID(followerID, typeUser)
 	if err != nil {
 		return nil, err
 	}
 
-	fdID, err := strconv.ParseUint(followedID, 10, 32)
+	fdID, err := decodeID(followedID, typeUser)
 	if err != nil {
 		return nil, err
 	}
 
-	var follower models.Follower
-	if err := database.DB.
-		Preload("Follower").
-		Preload("Followed").
-		Where("follower_id = ? AND followed_id = ?", fID, fdID).
-		First(&follower).Error; err != nil {
-		return nil, err
-	}
-
-	return &follower, nil
+	return r.follows.GetFollowRelationship(ctx, fID, fdID)
 }
 
 // Get follower count for a user
 func (r *queryResolver) FollowerCount(ctx context.Context, userID string) (int, error) {
-	id, err := strconv.ParseUint(userID, 10, 32)
+	id, err := decodeID(userID, typeUser)
 	if err != nil {
 		return 0, err
 	}
 
-	var count int64
-	if err := database.DB.Model(&models.Follower{}).
-		Where("followed_id = ?", id).
-		Count(&count).Error; err != nil {
-		return 0, err
-	}
-
-	return int(count), nil
+	return r.loadersFor(ctx).FollowerCount.Load(ctx, id)
 }
 
 // Get following count for a user
 func (r *queryResolver) FollowingCount(ctx context.Context, userID string) (int, error) {
-	id, err := strconv.ParseUint(userID, 10, 32)
+	id, err := decodeID(userID, typeUser)
 	if err != nil {
 		return 0, err
 	}
 
-	var count int64
-	if err := database.DB.Model(&models.Follower{}).
-		Where("follower_id = ?", id).
-		Count(&count).Error; err != nil {
-		return 0, err
-	}
+	return r.loadersFor(ctx).FollowingCount.Load(ctx, id)
+}
+
+// FollowerAdded is the resolver for the followerAdded field.
+func (r *subscriptionResolver) FollowerAdded(ctx context.Context, userID string) (<-chan *models.Follower, error) {
+	return subscribeFollowers(ctx, events.FollowerAdded, userID)
+}
+
+// FollowerRemoved is the resolver for the followerRemoved field.
+func (r *subscriptionResolver) FollowerRemoved(ctx context.Context, userID string) (<-chan *models.Follower, error) {
+	return subscribeFollowers(ctx, events.FollowerRemoved, userID)
+}
 
-	return int(count), nil
+// UserUpdated is the resolver for the userUpdated field.
+func (r *subscriptionResolver) UserUpdated(ctx context.Context, id string) (<-chan *models.User, error) {
+	return subscribeUser(ctx, events.UserUpdated, id)
 }
 
 // Field resolvers for User type
 func (r *userResolver) ID(ctx context.Context, obj *models.User) (string, error) {
+	return globalID(typeUser, obj.ID), nil
+}
+
+// DatabaseID is the resolver for the databaseId field.
+func (r *userResolver) DatabaseID(ctx context.Context, obj *models.User) (string, error) {
 	return strconv.FormatUint(uint64(obj.ID), 10), nil
 }
 
-// CreatedAt is the resolver for the createdAt field.
-func (r *userResolver) CreatedAt(ctx context.Context, obj *models.User) (string, error) {
-	return obj.CreatedAt.Format("2006-01-02T15:04:05Z07:00"), nil
+// Followers is the resolver for the followers field. The pages of sibling
+// users are loaded together, and priced per page by the complexity limits.
+func (r *userResolver) Followers(ctx context.Context, obj *models.User, first *int, after *string, last *int, before *string) (*FollowerConnection, error) {
+	args, err := pageArgs(first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := r.loadersFor(ctx).FollowersPage.Load(ctx, loaders.NewPageKey(obj.ID, args))
+	if err != nil {
+		return nil, err
+	}
+	return followerConnection(page), nil
 }
 
-// UpdatedAt is the resolver for the updatedAt field.
-func (r *userResolver) UpdatedAt(ctx context.Context, obj *models.User) (string, error) {
-	return obj.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"), nil
+// Following is the resolver for the following field.
+func (r *userResolver) Following(ctx context.Context, obj *models.User, first *int, after *string, last *int, before *string) (*FollowerConnection, error) {
+	args, err := pageArgs(first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := r.loadersFor(ctx).FollowingPage.Load(ctx, loaders.NewPageKey(obj.ID, args))
+	if err != nil {
+		return nil, err
+	}
+	return followerConnection(page), nil
+}
+
+// FollowerCount is the resolver for the followerCount field.
+func (r *userResolver) FollowerCount(ctx context.Context, obj *models.User) (int, error) {
+	return r.loadersFor(ctx).FollowerCount.Load(ctx, obj.ID)
+}
+
+// FollowingCount is the resolver for the followingCount field.
+func (r *userResolver) FollowingCount(ctx context.Context, obj *models.User) (int, error) {
+	return r.loadersFor(ctx).FollowingCount.Load(ctx, obj.ID)
 }
 
+// IsFollowedByViewer is the resolver for the isFollowedByViewer field.
+func (r *userResolver) IsFollowedByViewer(ctx context.Context, obj *models.User) (bool, error) {
+	principal, err := currentPrincipal(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return r.loadersFor(ctx).IsFollowing.Load(ctx, services.FollowPair{FollowerID: principal.UserID, FollowedID: obj.ID})
+}
+
+// Entity returns EntityResolver implementation.
+func (r *Resolver) Entity() EntityResolver { return &entityResolver{r} }
+
 // Follower returns FollowerResolver implementation.
 func (r *Resolver) Follower() FollowerResolver { return &followerResolver{r} }
 
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Subscription returns SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
 // User returns UserResolver implementation.
 func (r *Resolver) User() UserResolver { return &userResolver{r} }
 
+type entityResolver struct{ *Resolver }
 type followerResolver struct{ *Resolver }
+type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
 type userResolver struct{ *Resolver }
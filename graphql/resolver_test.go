@@ -0,0 +1,324 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/antoniocfetngnu/users-api/graphql/loaders"
+	"github.com/antoniocfetngnu/users-api/middleware"
+	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/repository"
+	"github.com/antoniocfetngnu/users-api/repository/repositorytest"
+	"github.com/antoniocfetngnu/users-api/services"
+	"gorm.io/gorm"
+)
+
+// testDB and testServices are set by setupDB
+var (
+	testDB       *gorm.DB
+	testServices *services.Services
+)
+
+// setupDB backs testServices with a fresh in-memory SQLite database with
+// `users` users where every user follows every other user, and returns a
+// counter of the queries executed afterwards
+func setupDB(t *testing.T, users int) *int32 {
+	t.Helper()
+
+	db := repositorytest.OpenSQLite(t)
+
+	for i := 1; i <= users; i++ {
+		name := fmt.Sprintf("user%d", i)
+		if err := db.Create(&models.User{FirstName: name, LastName: name, Email: name + "@example.com", Username: name, Password: "x"}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for a := 1; a <= users; a++ {
+		for b := 1; b <= users; b++ {
+			if a != b {
+				db.Create(&models.Follower{FollowerID: uint(a), FollowedID: uint(b), FollowedSince: time.Now()})
+			}
+		}
+	}
+
+	var queries int32
+	count := func(*gorm.DB) { atomic.AddInt32(&queries, 1) }
+	db.Callback().Query().After("gorm:query").Register("test:count", count)
+	db.Callback().Row().After("gorm:row").Register("test:count", count)
+
+	testDB = db
+	testServices = services.New(repository.NewGormStore(db), services.Config{})
+	return &queries
+}
+
+// newClient returns a GraphQL client authenticated as userID with loaders
+// that wait long enough to batch reliably on slow machines
+func newClient(userID uint) *client.Client {
+	return newClientAs(&middleware.Principal{UserID: userID, Role: models.RoleUser})
+}
+
+// newClientAs is newClient for an arbitrary principal
+func newClientAs(principal *middleware.Principal) *client.Client {
+	srv := handler.New(NewExecutableSchema(newConfig(testServices)))
+	srv.AddTransport(transport.POST{})
+	srv.SetErrorPresenter(NewErrorPresenter(false))
+	srv.AroundRootFields(RequireAuth)
+
+	return client.New(srv, func(req *client.Request) {
+		ctx := middleware.WithPrincipal(req.HTTP.Context(), principal)
+		ctx = loaders.NewContext(ctx, loaders.New(20*time.Millisecond, testServices.Users, testServices.Follows))
+		req.HTTP = req.HTTP.WithContext(ctx)
+	})
+}
+
+const nestedQuery = `{
+	users(first: 50) {
+		edges {
+			node {
+				id
+				followerCount
+				followingCount
+				isFollowedByViewer
+				followers(first: 50) {
+					edges {
+						node {
+							follower { username }
+							followed { username }
+						}
+					}
+				}
+				following(first: 50) {
+					edges {
+						node {
+							followed { username followerCount }
+						}
+					}
+				}
+			}
+		}
+	}
+}`
+
+func TestNestedQueryIsBatched(t *testing.T) {
+	for _, users := range []int{3, 12} {
+		t.Run(fmt.Sprintf("%d users", users), func(t *testing.T) {
+			queries := setupDB(t, users)
+
+			var resp struct {
+				Users struct {
+					Edges []struct {
+						Node struct {
+							ID                 string
+							FollowerCount      int
+							FollowingCount     int
+							IsFollowedByViewer bool
+							Followers          struct {
+								Edges []struct {
+									Node struct {
+										Follower struct{ Username string }
+										Followed struct{ Username string }
+									}
+								}
+							}
+							Following struct {
+								Edges []struct {
+									Node struct {
+										Followed struct {
+											Username      string
+											FollowerCount int
+										}
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+			newClient(1).MustPost(nestedQuery, &resp)
+
+			if len(resp.Users.Edges) != users {
+				t.Fatalf("expected %d users, got %d", users, len(resp.Users.Edges))
+			}
+			if followers := resp.Users.Edges[0].Node.Followers.Edges; len(followers) != users-1 {
+				t.Fatalf("expected %d followers, got %d", users-1, len(followers))
+			}
+
+			// users (count + page), follower counts, following counts,
+			// relationship checks and the nested user lookups are batched.
+			// The followers and following connections are paged per user
+			// (count + page each), bounded by the complexity limits, but
+			// resolve together so the fields below them are still batched.
+			if got, want := atomic.LoadInt32(queries), int32(6+4*users); got != want {
+				t.Fatalf("expected %d queries, got %d", want, got)
+			}
+		})
+	}
+}
+
+func TestViewerRequiresAuthentication(t *testing.T) {
+	setupDB(t, 1)
+
+	srv := handler.New(NewExecutableSchema(newConfig(testServices)))
+	srv.AddTransport(transport.POST{})
+	srv.SetErrorPresenter(NewErrorPresenter(false))
+	srv.AroundRootFields(RequireAuth)
+
+	var resp map[string]any
+	err := client.New(srv).Post(`{ viewer { id } }`, &resp)
+	if err == nil {
+		t.Fatal("expected an UNAUTHENTICATED error")
+	}
+}
+
+func TestViewer(t *testing.T) {
+	setupDB(t, 2)
+
+	var resp struct {
+		Viewer struct {
+			Username      string
+			FollowerCount int
+		}
+	}
+	newClient(2).MustPost(`{ viewer { username followerCount } }`, &resp)
+
+	if resp.Viewer.Username != "user2" || resp.Viewer.FollowerCount != 1 {
+		t.Fatalf("unexpected viewer %+v", resp.Viewer)
+	}
+}
+
+func TestLoadersFallBackWithoutMiddleware(t *testing.T) {
+	setupDB(t, 2)
+	if _, err := (&Resolver{users: testServices.Users, follows: testServices.Follows}).loadersFor(context.Background()).LoadUser(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type userPage struct {
+	Users struct {
+		Edges []struct {
+			Cursor string
+			Node   struct{ Username string }
+		}
+		PageInfo struct {
+			HasNextPage     bool
+			HasPreviousPage bool
+			StartCursor     *string
+			EndCursor       *string
+		}
+		TotalCount int
+	}
+}
+
+func usernames(page userPage) []string {
+	names := make([]string, len(page.Users.Edges))
+	for i, edge := range page.Users.Edges {
+		names[i] = edge.Node.Username
+	}
+	return names
+}
+
+func TestUsersConnectionPaginates(t *testing.T) {
+	setupDB(t, 5)
+	c := newClient(1)
+	const query = `query($first: Int, $after: String, $last: Int, $before: String) {
+		users(first: $first, after: $after, last: $last, before: $before) {
+			edges { cursor node { username } }
+			pageInfo { hasNextPage hasPreviousPage startCursor endCursor }
+			totalCount
+		}
+	}`
+
+	var first userPage
+	c.MustPost(query, &first, client.Var("first", 2))
+	if got := fmt.Sprint(usernames(first)); got != "[user1 user2]" {
+		t.Fatalf("first page = %s", got)
+	}
+	if !first.Users.PageInfo.HasNextPage || first.Users.PageInfo.HasPreviousPage || first.Users.TotalCount != 5 {
+		t.Fatalf("unexpected page info %+v (total %d)", first.Users.PageInfo, first.Users.TotalCount)
+	}
+
+	var second userPage
+	c.MustPost(query, &second, client.Var("first", 2), client.Var("after", *first.Users.PageInfo.EndCursor))
+	if got := fmt.Sprint(usernames(second)); got != "[user3 user4]" {
+		t.Fatalf("second page = %s", got)
+	}
+
+	var last userPage
+	c.MustPost(query, &last, client.Var("last", 2), client.Var("before", *second.Users.PageInfo.EndCursor))
+	if got := fmt.Sprint(usernames(last)); got != "[user2 user3]" {
+		t.Fatalf("backward page = %s", got)
+	}
+	if !last.Users.PageInfo.HasPreviousPage || !last.Users.PageInfo.HasNextPage {
+		t.Fatalf("unexpected page info %+v", last.Users.PageInfo)
+	}
+}
+
+func TestSearchUsersConnection(t *testing.T) {
+	setupDB(t, 12)
+
+	var resp userPage
+	newClient(1).MustPost(`{ users: searchUsers(query: "USER1", first: 2) {
+		edges { cursor node { username } }
+		pageInfo { hasNextPage hasPreviousPage startCursor endCursor }
+		totalCount
+	} }`, &resp)
+
+	// user1, user10, user11, user12
+	if resp.Users.TotalCount != 4 || fmt.Sprint(usernames(resp)) != "[user1 user10]" || !resp.Users.PageInfo.HasNextPage {
+		t.Fatalf("unexpected search result %+v", resp.Users)
+	}
+}
+
+func TestNodeRefetchesByGlobalID(t *testing.T) {
+	setupDB(t, 2)
+	c := newClient(1)
+
+	var viewer struct {
+		Viewer struct {
+			ID         string
+			DatabaseID string
+			Following  struct {
+				Edges []struct{ Node struct{ ID string } }
+			}
+		}
+	}
+	c.MustPost(`{ viewer { id databaseId following { edges { node { id } } } } }`, &viewer)
+	if viewer.Viewer.DatabaseID != "1" || viewer.Viewer.ID == "1" {
+		t.Fatalf("expected an opaque global ID, got %+v", viewer.Viewer)
+	}
+
+	var user struct {
+		Node struct {
+			Typename string `json:"__typename"`
+			Username string
+		}
+	}
+	c.MustPost(`query($id: ID!) { node(id: $id) { __typename ... on User { username } } }`, &user, client.Var("id", viewer.Viewer.ID))
+	if user.Node.Typename != "User" || user.Node.Username != "user1" {
+		t.Fatalf("unexpected node %+v", user.Node)
+	}
+
+	var edge struct {
+		Node struct {
+			Typename   string `json:"__typename"`
+			FollowedID string
+		}
+	}
+	c.MustPost(`query($id: ID!) { node(id: $id) { __typename ... on Follower { followedId } } }`, &edge, client.Var("id", viewer.Viewer.Following.Edges[0].Node.ID))
+	if edge.Node.Typename != "Follower" || edge.Node.FollowedID != globalID(typeUser, 2) {
+		t.Fatalf("unexpected node %+v", edge.Node)
+	}
+
+	// Plain numeric IDs are still accepted by arguments
+	var byID struct{ User struct{ Username string } }
+	c.MustPost(`{ user(id: 2) { username } }`, &byID)
+	if byID.User.Username != "user2" {
+		t.Fatalf("unexpected user %+v", byID.User)
+	}
+}
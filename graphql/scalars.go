@@ -0,0 +1,36 @@
+package graphql
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/antoniocfetngnu/users-api/services"
+)
+
+// dateTimeFormat is RFC 3339 with a fixed millisecond precision, so every
+// timestamp has the same shape
+const dateTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// MarshalDateTime writes the DateTime scalar in UTC
+func MarshalDateTime(t time.Time) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		io.WriteString(w, strconv.Quote(t.UTC().Format(dateTimeFormat)))
+	})
+}
+
+// UnmarshalDateTime reads the DateTime scalar, any RFC 3339 timestamp is
+// accepted
+func UnmarshalDateTime(v any) (time.Time, error) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, services.Invalid("DateTime must be a string")
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, services.Invalid("DateTime must be an RFC 3339 timestamp")
+	}
+	return t, nil
+}
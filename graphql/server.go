@@ -0,0 +1,126 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/antoniocfetngnu/users-api/config"
+	"github.com/antoniocfetngnu/users-api/graphql/loaders"
+	"github.com/antoniocfetngnu/users-api/middleware"
+	"github.com/antoniocfetngnu/users-api/services"
+	"github.com/antoniocfetngnu/users-api/utils"
+	"github.com/gorilla/websocket"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// NewServer returns the GraphQL handler with queries and mutations over
+// HTTP and subscriptions over WebSocket (graphql-ws and graphql-transport-ws).
+// In allowlist mode it fails if the persisted query manifest can't be loaded.
+func NewServer(cfg *config.Config, svc *services.Services) (*handler.Server, error) {
+	srv := handler.New(NewExecutableSchema(newConfig(svc)))
+
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+		Upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				// Non-browser clients don't send an Origin
+				return origin == "" || slices.Contains(cfg.AllowedOrigins, origin)
+			},
+		},
+		InitFunc: websocketInit,
+	})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+
+	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
+
+	srv.Use(extension.Introspection{})
+	if cfg.GraphQLAllowlistOnly() {
+		queries, err := LoadPersistedQueries(cfg.GraphQLPersistedQueries)
+		if err != nil {
+			return nil, err
+		}
+		// APQ resolves hashes from the manifest, then the allowlist rejects
+		// any query text that isn't in it
+		srv.Use(extension.AutomaticPersistedQuery{Cache: queries})
+		srv.Use(queries)
+	} else {
+		srv.Use(extension.AutomaticPersistedQuery{
+			Cache: lru.New[string](cfg.GraphQLAPQCacheSize),
+		})
+	}
+
+	limits := &QueryLimits{
+		MaxDepth:      cfg.GraphQLMaxDepth,
+		MaxComplexity: cfg.GraphQLMaxComplexity,
+	}
+	if cfg.GraphQLCostBudget > 0 {
+		limits.Budget = NewCostBudget(cfg.GraphQLCostBudget, cfg.GraphQLCostWindow)
+	}
+	srv.Use(limits)
+
+	srv.SetErrorPresenter(NewErrorPresenter(cfg.Environment == "production"))
+	srv.SetRecoverFunc(RecoverFunc)
+	srv.AroundRootFields(RequireAuth)
+	srv.AroundResponses(loaders.Middleware(svc.Users, svc.Follows))
+
+	return srv, nil
+}
+
+// newConfig wires the resolvers, directives and field costs of the schema
+func newConfig(svc *services.Services) Config {
+	return Config{
+		Resolvers: &Resolver{users: svc.Users, follows: svc.Follows},
+		Directives: DirectiveRoot{
+			Visibility: VisibilityDirective,
+		},
+		Complexity: newComplexityRoot(),
+	}
+}
+
+// loadersFor returns the loaders of the current request. It falls back to a
+// fresh set so resolvers never have to nil-check.
+func (r *Resolver) loadersFor(ctx context.Context) *loaders.Loaders {
+	if l, ok := loaders.FromContext(ctx); ok {
+		return l
+	}
+	return loaders.New(loaders.DefaultWait, r.users, r.follows)
+}
+
+// websocketInit authenticates a WebSocket connection. Browsers send the
+// auth_token cookie with the handshake (already verified and decoded by
+// VerifiedOptionalAuthMiddleware), other clients pass the JWT in the
+// connection_init payload as "Authorization: Bearer <token>" or "authToken".
+// That token never passes through Kong, so its signature is checked here.
+func websocketInit(ctx context.Context, payload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+	if _, ok := middleware.PrincipalFromContext(ctx); ok {
+		return ctx, nil, nil
+	}
+
+	token := strings.TrimPrefix(payload.Authorization(), "Bearer ")
+	if token == "" {
+		token = payload.GetString("authToken")
+	}
+	if token == "" {
+		return nil, nil, services.ErrUnauthenticated
+	}
+
+	if err := utils.VerifyJWT(token); err != nil {
+		return nil, nil, services.ErrUnauthenticated
+	}
+	principal, err := middleware.PrincipalFromToken(token)
+	if err != nil {
+		return nil, nil, services.ErrUnauthenticated
+	}
+	return middleware.WithPrincipal(ctx, principal), nil, nil
+}
@@ -0,0 +1,149 @@
+package graphql
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/antoniocfetngnu/users-api/config"
+	"github.com/antoniocfetngnu/users-api/events"
+	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/services"
+	"github.com/antoniocfetngnu/users-api/utils"
+)
+
+// notifyingBroker signals every subscription so tests only publish once
+// the subscriber is registered
+type notifyingBroker struct {
+	*events.MemoryBroker
+	subscribed chan struct{}
+}
+
+func (b *notifyingBroker) Subscribe(ctx context.Context, eventType events.Type, userID uint) (<-chan events.Event, error) {
+	ch, err := b.MemoryBroker.Subscribe(ctx, eventType, userID)
+	b.subscribed <- struct{}{}
+	return ch, err
+}
+
+func setupBroker(t *testing.T) *notifyingBroker {
+	t.Helper()
+
+	b := &notifyingBroker{MemoryBroker: events.NewMemoryBroker(), subscribed: make(chan struct{}, 1)}
+	events.SetBroker(b)
+	t.Cleanup(func() { events.SetBroker(events.NewMemoryBroker()) })
+	return b
+}
+
+func newWebsocketClient(t *testing.T) *client.Client {
+	t.Helper()
+
+	cfg := &config.Config{JWTSecret: "test-secret", GraphQLAPQCacheSize: 100}
+	utils.InitJWT(cfg)
+	srv, err := NewServer(cfg, testServices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client.New(srv)
+}
+
+func authPayload(t *testing.T, userID uint) map[string]any {
+	t.Helper()
+
+	token, err := utils.GenerateJWT(userID, "user", "user@example.com", models.RoleUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]any{"Authorization": "Bearer " + token}
+}
+
+func TestSubscriptionRequiresAuthentication(t *testing.T) {
+	setupDB(t, 2)
+	c := newWebsocketClient(t)
+
+	// A valid token with its payload swapped for an admin's keeps the
+	// original signature, which no longer matches
+	valid := authPayload(t, 2)["Authorization"].(string)
+	parts := strings.Split(valid, ".")
+	parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"1","role":"admin"}`))
+	tampered := strings.Join(parts, ".")
+
+	for name, payload := range map[string]map[string]any{
+		"no token":           nil,
+		"tampered token":     {"Authorization": tampered},
+		"tampered authToken": {"authToken": strings.TrimPrefix(tampered, "Bearer ")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			sub := c.WebsocketWithPayload(`subscription { followerAdded(userId: "2") { id } }`, payload)
+			defer sub.Close()
+
+			// An accepted connection just waits for events
+			rejected := make(chan error, 1)
+			go func() {
+				var resp map[string]any
+				rejected <- sub.Next(&resp)
+			}()
+			select {
+			case err := <-rejected:
+				if err == nil {
+					t.Fatal("expected the connection to be rejected")
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("the connection was accepted")
+			}
+		})
+	}
+}
+
+func TestFollowerSubscriptions(t *testing.T) {
+	setupDB(t, 2)
+	broker := setupBroker(t)
+	c := newWebsocketClient(t)
+
+	if _, err := testServices.Follows.Unfollow(services.AsService(context.Background(), "tests"), 1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	added := c.WebsocketWithPayload(`subscription { followerAdded(userId: "2") { follower { username } followed { username } } }`, authPayload(t, 2))
+	defer added.Close()
+	<-broker.subscribed
+
+	if _, _, err := testServices.Follows.Follow(services.AsService(context.Background(), "tests"), 1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	var addedResp struct {
+		FollowerAdded struct {
+			Follower struct{ Username string }
+			Followed struct{ Username string }
+		}
+	}
+	if err := added.Next(&addedResp); err != nil {
+		t.Fatal(err)
+	}
+	if addedResp.FollowerAdded.Follower.Username != "user1" || addedResp.FollowerAdded.Followed.Username != "user2" {
+		t.Fatalf("unexpected event: %+v", addedResp)
+	}
+
+	removed := c.WebsocketWithPayload(`subscription { followerRemoved(userId: "2") { follower { username } } }`, authPayload(t, 2))
+	defer removed.Close()
+	<-broker.subscribed
+
+	if _, err := testServices.Follows.Unfollow(services.AsService(context.Background(), "tests"), 1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	var removedResp struct {
+		FollowerRemoved struct {
+			Follower struct{ Username string }
+		}
+	}
+	if err := removed.Next(&removedResp); err != nil {
+		t.Fatal(err)
+	}
+	if removedResp.FollowerRemoved.Follower.Username != "user1" {
+		t.Fatalf("unexpected event: %+v", removedResp)
+	}
+}
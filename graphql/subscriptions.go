@@ -0,0 +1,62 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/antoniocfetngnu/users-api/events"
+	"github.com/antoniocfetngnu/users-api/models"
+)
+
+// subscribeFollowers streams the follow edges of follower events about
+// userID until the subscription ends
+func subscribeFollowers(ctx context.Context, eventType events.Type, userID string) (<-chan *models.Follower, error) {
+	id, err := decodeID(userID, typeUser)
+	if err != nil {
+		return nil, err
+	}
+
+	in, err := events.Subscribe(ctx, eventType, id)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan *models.Follower, 1)
+	go func() {
+		defer close(out)
+		for event := range in {
+			select {
+			case out <- event.Follower:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// subscribeUser streams the user of user events about id until the
+// subscription ends
+func subscribeUser(ctx context.Context, eventType events.Type, id string) (<-chan *models.User, error) {
+	userID, err := decodeID(id, typeUser)
+	if err != nil {
+		return nil, err
+	}
+
+	in, err := events.Subscribe(ctx, eventType, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan *models.User, 1)
+	go func() {
+		defer close(out)
+		for event := range in {
+			select {
+			case out <- event.User:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,46 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/antoniocfetngnu/users-api/middleware"
+	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/services"
+)
+
+// VisibilityDirective implements @visibility: the field resolves to null,
+// without an error, when the viewer is not allowed to read it
+func VisibilityDirective(ctx context.Context, obj any, next graphql.Resolver, level Visibility) (any, error) {
+	principal, err := currentPrincipal(ctx)
+	if err != nil || !canSee(ctx, principal, obj, level) {
+		return nil, nil
+	}
+	return next(ctx)
+}
+
+func canSee(ctx context.Context, principal *middleware.Principal, obj any, level Visibility) bool {
+	switch level {
+	case VisibilityAuthenticated:
+		return true
+	case VisibilityOwner:
+		if principal.IsAdmin() {
+			return true
+		}
+		ownerID, ok := ownerOf(obj)
+		return ok && services.CanAccessUser(ctx, ownerID)
+	default:
+		return principal.IsAdmin()
+	}
+}
+
+// ownerOf returns the user an object belongs to. Objects without an owner
+// (including root fields) are only visible to admins at the OWNER level.
+func ownerOf(obj any) (uint, bool) {
+	switch o := obj.(type) {
+	case *models.User:
+		return o.ID, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,96 @@
+package graphql
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/antoniocfetngnu/users-api/middleware"
+	"github.com/antoniocfetngnu/users-api/models"
+)
+
+type emailsResponse struct {
+	Users struct {
+		Edges []struct {
+			Node struct {
+				DatabaseID string
+				Email      *string
+			}
+		}
+	}
+}
+
+func emailsByID(t *testing.T, principal *middleware.Principal) map[string]*string {
+	t.Helper()
+
+	var resp emailsResponse
+	newClientAs(principal).MustPost(`{ users { edges { node { databaseId email } } } }`, &resp)
+
+	emails := map[string]*string{}
+	for _, edge := range resp.Users.Edges {
+		emails[edge.Node.DatabaseID] = edge.Node.Email
+	}
+	return emails
+}
+
+func TestEmailIsVisibleToOwnerOnly(t *testing.T) {
+	setupDB(t, 2)
+
+	emails := emailsByID(t, &middleware.Principal{UserID: 1, Role: models.RoleUser})
+	if emails["1"] == nil || *emails["1"] != "user1@example.com" {
+		t.Errorf("owner should see their email, got %v", emails["1"])
+	}
+	if emails["2"] != nil {
+		t.Errorf("other users' email should be null, got %q", *emails["2"])
+	}
+}
+
+func TestEmailIsVisibleToAdmins(t *testing.T) {
+	setupDB(t, 2)
+
+	emails := emailsByID(t, &middleware.Principal{UserID: 1, Role: models.RoleAdmin})
+	if emails["2"] == nil || *emails["2"] != "user2@example.com" {
+		t.Errorf("admins should see every email, got %v", emails["2"])
+	}
+}
+
+func TestUserByEmailIsAdminOnly(t *testing.T) {
+	setupDB(t, 2)
+	query := `{ userByEmail(email: "user2@example.com") { username } }`
+
+	var resp struct {
+		UserByEmail *struct{ Username string }
+	}
+	newClient(1).MustPost(query, &resp)
+	if resp.UserByEmail != nil {
+		t.Errorf("userByEmail should be null for non-admins, got %+v", resp.UserByEmail)
+	}
+
+	newClientAs(&middleware.Principal{UserID: 1, Role: models.RoleAdmin}).MustPost(query, &resp)
+	if resp.UserByEmail == nil || resp.UserByEmail.Username != "user2" {
+		t.Errorf("admins should find users by email, got %+v", resp.UserByEmail)
+	}
+}
+
+func TestDateTimeFormat(t *testing.T) {
+	setupDB(t, 2)
+
+	var resp struct {
+		Viewer struct {
+			CreatedAt string
+			Followers struct {
+				Edges []struct {
+					Node struct{ FollowedSince string }
+				}
+			}
+		}
+	}
+	newClient(1).MustPost(`{ viewer { createdAt followers { edges { node { followedSince } } } } }`, &resp)
+
+	format := regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{3}Z$`)
+	if !format.MatchString(resp.Viewer.CreatedAt) {
+		t.Errorf("createdAt = %q", resp.Viewer.CreatedAt)
+	}
+	if since := resp.Viewer.Followers.Edges[0].Node.FollowedSince; !format.MatchString(since) {
+		t.Errorf("followedSince = %q", since)
+	}
+}
@@ -0,0 +1,187 @@
+package grpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"path"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/antoniocfetngnu/users-api/middleware"
+	"github.com/antoniocfetngnu/users-api/services"
+	"github.com/antoniocfetngnu/users-api/utils"
+)
+
+// Caller is who is making a gRPC call: another service (Service is set) or
+// an end user (Principal is set)
+type Caller struct {
+	Service   string
+	Principal *middleware.Principal
+}
+
+// Subject is the name the ACL knows the caller by: the service name, or
+// "user" for end users
+func (c *Caller) Subject() string {
+	if c.Principal != nil {
+		return "user"
+	}
+	return c.Service
+}
+
+type callerKey struct{}
+
+// CallerFromContext returns the caller authenticated by the interceptors
+func CallerFromContext(ctx context.Context) (*Caller, bool) {
+	c, ok := ctx.Value(callerKey{}).(*Caller)
+	return c, ok
+}
+
+// Authorizer decides whether caller may call fullMethod
+// ("/users.UsersService/GetUser"). A non-nil error rejects the call.
+type Authorizer func(ctx context.Context, caller *Caller, fullMethod string) error
+
+// ACLAuthorizer allows each subject the listed RPC names ("*" for all).
+// An empty ACL allows every authenticated caller.
+func ACLAuthorizer(acl map[string][]string) Authorizer {
+	return func(ctx context.Context, caller *Caller, fullMethod string) error {
+		if len(acl) == 0 {
+			return nil
+		}
+		allowed := acl[caller.Subject()]
+		if slices.Contains(allowed, "*") || slices.Contains(allowed, path.Base(fullMethod)) {
+			return nil
+		}
+		return status.Errorf(codes.PermissionDenied, "%s may not call %s", caller.Subject(), path.Base(fullMethod))
+	}
+}
+
+// Authenticator identifies callers from the "authorization: Bearer <token>"
+// metadata, which holds either a service token or an end user's JWT, or
+// from a verified client certificate (its common name is the service
+// name)
+type Authenticator struct {
+	// ServiceTokens maps service names to their tokens
+	ServiceTokens map[string]string
+	Authorize     Authorizer
+}
+
+// UnaryInterceptor authenticates and authorizes unary calls
+func (a *Authenticator) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.check(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor authenticates and authorizes streaming calls
+func (a *Authenticator) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.check(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// check returns ctx carrying the caller, or the status to fail the call with
+func (a *Authenticator) check(ctx context.Context, fullMethod string) (context.Context, error) {
+	if isPublic(fullMethod) {
+		return ctx, nil
+	}
+
+	caller, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if a.Authorize != nil {
+		if err := a.Authorize(ctx, caller, fullMethod); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx = context.WithValue(ctx, callerKey{}, caller)
+	if caller.Principal != nil {
+		ctx = middleware.WithPrincipal(ctx, caller.Principal)
+	} else {
+		ctx = services.AsService(ctx, caller.Service)
+	}
+	return ctx, nil
+}
+
+func (a *Authenticator) authenticate(ctx context.Context) (*Caller, error) {
+	if token := bearerToken(ctx); token != "" {
+		for service, serviceToken := range a.ServiceTokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(serviceToken)) == 1 {
+				return &Caller{Service: service}, nil
+			}
+		}
+
+		if err := utils.VerifyJWT(token); err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		principal, err := middleware.PrincipalFromToken(token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		return &Caller{Principal: principal}, nil
+	}
+
+	if service := clientCertName(ctx); service != "" {
+		return &Caller{Service: service}, nil
+	}
+	return nil, status.Error(codes.Unauthenticated, "missing credentials")
+}
+
+// isPublic reports whether fullMethod may be called without credentials:
+// health checks (load balancer probes) and reflection, which only exposes
+// the schema and is disabled unless GRPC_REFLECTION is set
+func isPublic(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/grpc.health.v1.Health/") ||
+		strings.HasPrefix(fullMethod, "/grpc.reflection.")
+}
+
+// bearerToken returns the token of the authorization metadata, if any
+func bearerToken(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		if token, ok := strings.CutPrefix(value, "Bearer "); ok {
+			return token
+		}
+	}
+	return ""
+}
+
+// clientCertName returns the common name of the verified client
+// certificate, if the connection uses mutual TLS
+func clientCertName(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 {
+		return ""
+	}
+	return tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+}
+
+// contextStream overrides the context of a server stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
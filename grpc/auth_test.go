@@ -0,0 +1,195 @@
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/antoniocfetngnu/users-api/middleware"
+	pb "github.com/antoniocfetngnu/users-api/proto"
+	"github.com/antoniocfetngnu/users-api/utils"
+)
+
+func TestAuthentication(t *testing.T) {
+	setupDB(t, 2)
+	cfg := testConfig()
+
+	userToken, err := utils.GenerateJWT(1, "user1", "user1@example.com", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := userToken[:len(userToken)-2] + "xx"
+
+	tests := []struct {
+		name string
+		opts []grpc.DialOption
+		want codes.Code
+	}{
+		{"no credentials", nil, codes.Unauthenticated},
+		{"service token", []grpc.DialOption{grpc.WithPerRPCCredentials(bearer(testServiceToken))}, codes.OK},
+		{"unknown token", []grpc.DialOption{grpc.WithPerRPCCredentials(bearer("nope"))}, codes.Unauthenticated},
+		{"user JWT", []grpc.DialOption{grpc.WithPerRPCCredentials(bearer(userToken))}, codes.OK},
+		{"forged JWT", []grpc.DialOption{grpc.WithPerRPCCredentials(bearer(forged))}, codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := dial(t, cfg, insecure.NewCredentials(), tt.opts...)
+
+			_, err := client.GetUser(context.Background(), &pb.GetUserRequest{UserId: 1})
+			if code := status.Code(err); code != tt.want {
+				t.Fatalf("GetUser: code = %v, want %v", code, tt.want)
+			}
+
+			stream, err := client.StreamFollowerIDs(context.Background(), &pb.StreamFollowerIDsRequest{UserId: 1})
+			if err == nil {
+				_, err = stream.Recv()
+			}
+			if err == io.EOF {
+				err = nil
+			}
+			if code := status.Code(err); code != tt.want {
+				t.Fatalf("StreamFollowerIDs: code = %v, want %v", code, tt.want)
+			}
+		})
+	}
+}
+
+func TestACLAuthorizer(t *testing.T) {
+	authorize := ACLAuthorizer(map[string][]string{
+		"posts": {"GetUser", "GetUsers"},
+		"admin": {"*"},
+	})
+
+	tests := []struct {
+		caller *Caller
+		method string
+		want   codes.Code
+	}{
+		{&Caller{Service: "posts"}, "/users.UsersService/GetUsers", codes.OK},
+		{&Caller{Service: "posts"}, "/users.UsersService/GetFollowerIDs", codes.PermissionDenied},
+		{&Caller{Service: "admin"}, "/users.UsersService/GetFollowerIDs", codes.OK},
+		{&Caller{Service: "unknown"}, "/users.UsersService/GetUser", codes.PermissionDenied},
+		{&Caller{Principal: &middleware.Principal{UserID: 1}}, "/users.UsersService/GetUser", codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		if code := status.Code(authorize(context.Background(), tt.caller, tt.method)); code != tt.want {
+			t.Errorf("%s calling %s: code = %v, want %v", tt.caller.Subject(), tt.method, code, tt.want)
+		}
+	}
+
+	if err := ACLAuthorizer(nil)(context.Background(), &Caller{Service: "any"}, "/users.UsersService/GetUser"); err != nil {
+		t.Errorf("empty ACL should allow everyone: %v", err)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	setupDB(t, 1)
+	dir := t.TempDir()
+
+	caCert, caKey := newCert(t, "test-ca", nil, nil)
+	serverCert, serverKey := newCert(t, "localhost", caCert, caKey)
+	clientCert, clientKey := newCert(t, "posts", caCert, caKey)
+
+	cfg := testConfig()
+	cfg.GRPCTLSCert = writePEM(t, dir, "server.crt", "CERTIFICATE", serverCert.Raw)
+	cfg.GRPCTLSKey = writeKey(t, dir, "server.key", serverKey)
+	cfg.GRPCClientCA = writePEM(t, dir, "ca.crt", "CERTIFICATE", caCert.Raw)
+	cfg.GRPCServiceACL = map[string][]string{"posts": {"GetUser"}}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	clientTLS := &tls.Config{
+		RootCAs:    roots,
+		ServerName: "localhost",
+		Certificates: []tls.Certificate{{
+			Certificate: [][]byte{clientCert.Raw},
+			PrivateKey:  clientKey,
+		}},
+	}
+
+	// The certificate alone identifies the "posts" service
+	client := dial(t, cfg, credentials.NewTLS(clientTLS))
+	if _, err := client.GetUser(context.Background(), &pb.GetUserRequest{UserId: 1}); err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	_, err := client.GetUsers(context.Background(), &pb.GetUsersRequest{UserIds: []uint32{1}})
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Fatalf("GetUsers: code = %v, want PermissionDenied", code)
+	}
+
+	// Without a client certificate the handshake fails
+	clientTLS.Certificates = nil
+	client = dial(t, cfg, credentials.NewTLS(clientTLS))
+	if _, err := client.GetUser(context.Background(), &pb.GetUserRequest{UserId: 1}); status.Code(err) != codes.Unavailable {
+		t.Fatalf("GetUser without certificate: %v", err)
+	}
+}
+
+// newCert issues a certificate for name, self-signed when parent is nil
+func newCert(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func writeKey(t *testing.T, dir, name string, key *ecdsa.PrivateKey) string {
+	t.Helper()
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return writePEM(t, dir, name, "EC PRIVATE KEY", der)
+}
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"github.com/antoniocfetngnu/users-api/middleware"
+	"github.com/antoniocfetngnu/users-api/services"
+)
+
+// codeByKind maps domain error kinds to gRPC status codes
+var codeByKind = map[services.Kind]codes.Code{
+	services.KindInvalid:         codes.InvalidArgument,
+	services.KindUnauthenticated: codes.Unauthenticated,
+	services.KindForbidden:       codes.PermissionDenied,
+	services.KindNotFound:        codes.NotFound,
+	services.KindConflict:        codes.AlreadyExists,
+}
+
+// statusError converts err to a gRPC status. Domain errors keep their
+// message and carry details; anything else becomes Internal with
+// internalMessage (the details are only logged, with the request ID), like
+// the REST handlers do. Cancelled or expired contexts keep their own codes.
+func statusError(ctx context.Context, err error, internalMessage string, details ...protoadapt.MessageV1) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	code, ok := codeByKind[services.KindOf(err)]
+	if !ok {
+		log.Printf("[%s] %s: %v", middleware.RequestIDFromContext(ctx), internalMessage, err)
+		return status.Error(codes.Internal, internalMessage)
+	}
+	return withDetails(status.New(code, err.Error()), details...)
+}
+
+// invalidArgument returns an InvalidArgument status describing the
+// offending request field
+func invalidArgument(field, description string) error {
+	return withDetails(status.New(codes.InvalidArgument, "invalid "+field), &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: description}},
+	})
+}
+
+// userResource identifies the user a NotFound status refers to
+func userResource(name string) *errdetails.ResourceInfo {
+	return &errdetails.ResourceInfo{ResourceType: "user", ResourceName: name}
+}
+
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	if len(details) == 0 {
+		return st.Err()
+	}
+	if detailed, err := st.WithDetails(details...); err == nil {
+		return detailed.Err()
+	}
+	return st.Err()
+}
@@ -0,0 +1,200 @@
+package grpc
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/antoniocfetngnu/users-api/models"
+	pb "github.com/antoniocfetngnu/users-api/proto"
+	"github.com/antoniocfetngnu/users-api/services"
+)
+
+// defaultChunkSize is how many IDs StreamFollowerIDs sends per message
+const defaultChunkSize = 1000
+
+// CheckFollows reports which of the pairs are existing follow relationships
+func (s *UsersServer) CheckFollows(ctx context.Context, req *pb.CheckFollowsRequest) (*pb.CheckFollowsResponse, error) {
+	pairs := make([]services.FollowPair, len(req.Pairs))
+	for i, pair := range req.Pairs {
+		pairs[i] = services.FollowPair{FollowerID: uint(pair.FollowerId), FollowedID: uint(pair.FollowedId)}
+	}
+
+	follows, err := s.checkFollows(ctx, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*pb.FollowCheck, len(pairs))
+	for i, pair := range req.Pairs {
+		results[i] = &pb.FollowCheck{FollowerId: pair.FollowerId, FollowedId: pair.FollowedId, Follows: follows[i]}
+	}
+	return &pb.CheckFollowsResponse{Results: results}, nil
+}
+
+// ListFollowers returns a page of the followers of a user
+func (s *UsersServer) ListFollowers(ctx context.Context, req *pb.ListFollowsRequest) (*pb.ListFollowsResponse, error) {
+	return s.listFollows(ctx, req, s.svc.Follows.ListFollowersPage, followerOf)
+}
+
+// ListFollowing returns a page of the users a user follows
+func (s *UsersServer) ListFollowing(ctx context.Context, req *pb.ListFollowsRequest) (*pb.ListFollowsResponse, error) {
+	return s.listFollows(ctx, req, s.svc.Follows.ListFollowingPage, followedOf)
+}
+
+// GetFollowerIDs returns the IDs of all followers of a user. Use
+// StreamFollowerIDs for large follower sets.
+func (s *UsersServer) GetFollowerIDs(ctx context.Context, req *pb.GetFollowerIDsRequest) (*pb.FollowerIDsResponse, error) {
+	ids, err := s.followerIDs(ctx, uint64(req.UserId))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.FollowerIDsResponse{FollowerIds: toUint32s(ids)}, nil
+}
+
+// StreamFollowerIDs sends the IDs of all followers of a user in chunks
+func (s *UsersServer) StreamFollowerIDs(req *pb.StreamFollowerIDsRequest, stream pb.UsersService_StreamFollowerIDsServer) error {
+	return s.streamFollowerIDs(stream.Context(), uint64(req.UserId), req.ChunkSize, func(ids []uint) error {
+		return stream.Send(&pb.FollowerIDsResponse{FollowerIds: toUint32s(ids)})
+	})
+}
+
+func (s *UsersServer) listFollows(ctx context.Context, req *pb.ListFollowsRequest, list listFollowsFunc, other func(*models.Follower) *models.User) (*pb.ListFollowsResponse, error) {
+	page, next, err := s.followsPage(ctx, list, uint64(req.UserId), req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListFollowsResponse{
+		Follows:       make([]*pb.Follow, len(page.Items)),
+		NextPageToken: next,
+		TotalCount:    int32(page.TotalCount),
+	}
+	for i, edge := range page.Items {
+		resp.Follows[i] = &pb.Follow{
+			FollowerId:    uint32(edge.FollowerID),
+			FollowedId:    uint32(edge.FollowedID),
+			FollowedSince: edge.FollowedSince.Format("2006-01-02T15:04:05Z07:00"),
+			User:          toUserResponse(other(edge)),
+		}
+	}
+	return resp, nil
+}
+
+// The helpers below are shared by every version of UsersService
+
+// listFollowsFunc lists a page of follow edges of a user
+type listFollowsFunc func(ctx context.Context, userID uint, args services.PageArgs) (*services.Page[*models.Follower], error)
+
+// followerOf and followedOf pick the user on the other side of an edge for
+// ListFollowers and ListFollowing
+func followerOf(edge *models.Follower) *models.User { return &edge.Follower }
+func followedOf(edge *models.Follower) *models.User { return &edge.Followed }
+
+// checkFollows reports, in order, whether each pair is a follow relationship
+func (b *backend) checkFollows(ctx context.Context, pairs []services.FollowPair) ([]bool, error) {
+	follows, err := b.svc.Follows.CheckFollows(ctx, pairs)
+	if err != nil {
+		return nil, statusError(ctx, err, "Failed to check follows")
+	}
+
+	results := make([]bool, len(pairs))
+	for i, pair := range pairs {
+		results[i] = follows[pair]
+	}
+	return results, nil
+}
+
+// followsPage returns a ListFollowers/ListFollowing page with the users of
+// both sides loaded, and the token of the next page (empty on the last one)
+func (b *backend) followsPage(ctx context.Context, list listFollowsFunc, userID uint64, pageSize int32, token string) (*services.Page[*models.Follower], string, error) {
+	after, err := parsePageToken(token)
+	if err != nil {
+		return nil, "", err
+	}
+
+	size := int(pageSize)
+	if size <= 0 {
+		size = services.DefaultPageSize
+	}
+	size = min(size, services.MaxPageSize)
+
+	page, err := list(ctx, uint(userID), services.PageArgs{First: &size, After: after})
+	if err != nil {
+		return nil, "", statusError(ctx, err, "Failed to list follows")
+	}
+	if err := b.svc.Follows.AttachUsers(ctx, page.Items); err != nil {
+		return nil, "", statusError(ctx, err, "Failed to load users")
+	}
+
+	next := ""
+	if page.HasNextPage {
+		next = pageToken(page.Items[len(page.Items)-1].ID)
+	}
+	return page, next, nil
+}
+
+// followerIDs returns the IDs of all followers of a user
+func (b *backend) followerIDs(ctx context.Context, userID uint64) ([]uint, error) {
+	ids, err := b.svc.Follows.FollowerIDs(ctx, uint(userID))
+	if err != nil {
+		return nil, statusError(ctx, err, "Failed to fetch follower IDs")
+	}
+	return ids, nil
+}
+
+// streamFollowerIDs passes the IDs of all followers of a user to send in
+// chunks of chunkSize (defaultChunkSize when unset)
+func (b *backend) streamFollowerIDs(ctx context.Context, userID uint64, chunkSize int32, send func(ids []uint) error) error {
+	size := int(chunkSize)
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+
+	err := b.svc.Follows.EachFollowerIDs(ctx, uint(userID), size, func(ids []uint) error {
+		// Stop reading from the database once the client is gone
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return send(ids)
+	})
+	if err != nil {
+		return statusError(ctx, err, "Failed to stream follower IDs")
+	}
+	return nil
+}
+
+// pageToken returns the opaque token of the page after the edge
+func pageToken(edgeID uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("follow:%d", edgeID)))
+}
+
+// parsePageToken returns the edge ID encoded in a page token, nil for the
+// first page
+func parsePageToken(token string) (*uint, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalidArgument("page_token", "not a next_page_token of this RPC")
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(string(raw), "follow:"), 10, 64)
+	if err != nil || !strings.HasPrefix(string(raw), "follow:") {
+		return nil, invalidArgument("page_token", "not a next_page_token of this RPC")
+	}
+
+	edgeID := uint(id)
+	return &edgeID, nil
+}
+
+func toUint32s(ids []uint) []uint32 {
+	out := make([]uint32, len(ids))
+	for i, id := range ids {
+		out[i] = uint32(id)
+	}
+	return out
+}
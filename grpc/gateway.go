@@ -0,0 +1,74 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/antoniocfetngnu/users-api/config"
+	pb "github.com/antoniocfetngnu/users-api/proto"
+	"github.com/antoniocfetngnu/users-api/services"
+)
+
+// gatewayBufferSize is the size of the in-memory connection between the
+// gateway and its gRPC server
+const gatewayBufferSize = 1 << 20
+
+// Gateway serves UsersService as JSON over HTTP under /internal/v1, as
+// mapped by the google.api.http annotations of proto/users.proto.
+//
+// Requests are forwarded over an in-memory connection to a gRPC server built
+// with the same interceptors as NewServer, so they are authenticated,
+// authorized and answered exactly like gRPC calls. The Authorization header
+// carries the service token or user JWT; there is no client certificate, so
+// mutual TLS doesn't apply.
+type Gateway struct {
+	http.Handler
+	server *grpc.Server
+	conn   *grpc.ClientConn
+}
+
+// NewGateway starts the gateway's gRPC server, Close stops it
+func NewGateway(ctx context.Context, cfg *config.Config, svc *services.Services) (*Gateway, error) {
+	server := grpc.NewServer(interceptors(cfg, svc)...)
+	pb.RegisterUsersServiceServer(server, &UsersServer{backend: backend{svc}})
+
+	lis := bufconn.Listen(gatewayBufferSize)
+	go server.Serve(lis)
+
+	conn, err := grpc.NewClient("passthrough:///gateway",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		server.Stop()
+		return nil, err
+	}
+
+	// Field names and zero values as in the REST API: snake_case, and
+	// "follows": false rather than no field at all
+	mux := runtime.NewServeMux(runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+		MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+		UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+	}))
+	if err := pb.RegisterUsersServiceHandler(ctx, mux, conn); err != nil {
+		conn.Close()
+		server.Stop()
+		return nil, err
+	}
+
+	return &Gateway{Handler: mux, server: server, conn: conn}, nil
+}
+
+// Close stops the gateway's gRPC server. Stop the HTTP server first so no
+// request is still being forwarded.
+func (g *Gateway) Close() {
+	g.conn.Close()
+	g.server.Stop()
+}
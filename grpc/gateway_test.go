@@ -0,0 +1,168 @@
+package grpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/antoniocfetngnu/users-api/config"
+)
+
+// serveGateway serves NewGateway(cfg) over HTTP
+func serveGateway(t *testing.T, cfg *config.Config) *httptest.Server {
+	t.Helper()
+
+	gateway, err := NewGateway(context.Background(), cfg, testServices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(gateway)
+	t.Cleanup(func() {
+		srv.Close()
+		gateway.Close()
+	})
+	return srv
+}
+
+// call sends a request to the gateway, decoding the JSON response into out
+func call(t *testing.T, srv *httptest.Server, method, path, token, body string, out any) int {
+	t.Helper()
+
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestGateway(t *testing.T) {
+	setupDB(t, 3)
+	srv := serveGateway(t, testConfig())
+
+	var user struct {
+		ID        uint32 `json:"id"`
+		Username  string `json:"username"`
+		FirstName string `json:"first_name"`
+	}
+	if code := call(t, srv, "GET", "/internal/v1/users/2", testServiceToken, "", &user); code != http.StatusOK {
+		t.Fatalf("GetUser: status %d", code)
+	}
+	if user.ID != 2 || user.Username != "user2" || user.FirstName != "user2" {
+		t.Fatalf("GetUser = %+v", user)
+	}
+
+	var users struct {
+		Users      []map[string]any `json:"users"`
+		MissingIDs []uint32         `json:"missing_ids"`
+	}
+	if code := call(t, srv, "POST", "/internal/v1/users:batchGet", testServiceToken, `{"user_ids": [3, 99, 1]}`, &users); code != http.StatusOK {
+		t.Fatalf("GetUsers: status %d", code)
+	}
+	if len(users.Users) != 2 || users.Users[0]["username"] != "user3" || len(users.MissingIDs) != 1 || users.MissingIDs[0] != 99 {
+		t.Fatalf("GetUsers = %+v", users)
+	}
+
+	// Zero values are sent, so a missing relationship reads "follows": false
+	var checks struct {
+		Results []map[string]any `json:"results"`
+	}
+	call(t, srv, "POST", "/internal/v1/follows:check", testServiceToken, `{"pairs": [{"follower_id": 2, "followed_id": 1}, {"follower_id": 1, "followed_id": 2}]}`, &checks)
+	if len(checks.Results) != 2 || checks.Results[0]["follows"] != true || checks.Results[1]["follows"] != false {
+		t.Fatalf("CheckFollows = %+v", checks)
+	}
+
+	var page struct {
+		Follows    []map[string]any `json:"follows"`
+		TotalCount int              `json:"total_count"`
+	}
+	call(t, srv, "GET", "/internal/v1/users/1/followers?page_size=1", testServiceToken, "", &page)
+	if len(page.Follows) != 1 || page.TotalCount != 2 {
+		t.Fatalf("ListFollowers = %+v", page)
+	}
+}
+
+func TestGatewayErrors(t *testing.T) {
+	setupDB(t, 1)
+	cfg := testConfig()
+	cfg.GRPCServiceACL = map[string][]string{"tests": {"GetUser"}}
+	srv := serveGateway(t, cfg)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"no credentials", "GET", "/internal/v1/users/1", "", http.StatusUnauthorized},
+		{"unknown token", "GET", "/internal/v1/users/1", "nope", http.StatusUnauthorized},
+		{"not in ACL", "GET", "/internal/v1/users/1/follower-ids", testServiceToken, http.StatusForbidden},
+		{"missing user", "GET", "/internal/v1/users/99", testServiceToken, http.StatusNotFound},
+		{"bad path parameter", "GET", "/internal/v1/users/abc", testServiceToken, http.StatusBadRequest},
+		{"unknown route", "GET", "/internal/v1/nothing", testServiceToken, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body struct {
+				Code    int    `json:"code"`
+				Message string `json:"message"`
+			}
+			if code := call(t, srv, tt.method, tt.path, tt.token, "", &body); code != tt.want {
+				t.Fatalf("status = %d, want %d (%+v)", code, tt.want, body)
+			}
+			if body.Message == "" {
+				t.Fatalf("error without message: %+v", body)
+			}
+		})
+	}
+}
+
+func TestGatewayStream(t *testing.T) {
+	setupDB(t, 5)
+	srv := serveGateway(t, testConfig())
+
+	req, _ := http.NewRequest("GET", srv.URL+"/internal/v1/users/1/follower-ids:stream?chunk_size=3", nil)
+	req.Header.Set("Authorization", "Bearer "+testServiceToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// One JSON object per chunk, one per line
+	var ids []uint32
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var chunk struct {
+			Result struct {
+				FollowerIDs []uint32 `json:"follower_ids"`
+			} `json:"result"`
+			Error any `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &chunk); err != nil || chunk.Error != nil {
+			t.Fatalf("chunk %s: %v", scanner.Text(), err)
+		}
+		ids = append(ids, chunk.Result.FollowerIDs...)
+	}
+	if len(ids) != 4 {
+		t.Fatalf("follower IDs = %v, want 4", ids)
+	}
+}
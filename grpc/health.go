@@ -0,0 +1,57 @@
+package grpc
+
+import (
+	"context"
+	"log"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	pb "github.com/antoniocfetngnu/users-api/proto"
+	usersv2 "github.com/antoniocfetngnu/users-api/proto/v2"
+)
+
+// healthCheckTimeout bounds each dependency check
+const healthCheckTimeout = 2 * time.Second
+
+// healthServices are the names health is reported under: the server as a
+// whole ("") and each version of UsersService
+var healthServices = []string{
+	"",
+	pb.UsersService_ServiceDesc.ServiceName,
+	usersv2.UsersService_ServiceDesc.ServiceName,
+}
+
+// MonitorHealth runs check every interval until ctx is done and reports
+// the result through the health service
+func (s *Server) MonitorHealth(ctx context.Context, interval time.Duration, check func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		err := check(checkCtx)
+		cancel()
+
+		status := healthpb.HealthCheckResponse_SERVING
+		if err != nil {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		if status != last {
+			if err != nil {
+				log.Printf("gRPC health: NOT_SERVING: %v", err)
+			}
+			for _, service := range healthServices {
+				s.Health.SetServingStatus(service, status)
+			}
+			last = status
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+
+	pb "github.com/antoniocfetngnu/users-api/proto"
+)
+
+func TestHealth(t *testing.T) {
+	srv, conn := serve(t, testConfig(), insecure.NewCredentials())
+	client := healthpb.NewHealthClient(conn)
+
+	// Health checks need no credentials
+	waitForStatus := func(want healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		for deadline := time.Now().Add(time.Second); ; {
+			resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: pb.UsersService_ServiceDesc.ServiceName})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Status == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("status = %v, want %v", resp.Status, want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	waitForStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	var dbErr error
+	failing := make(chan bool)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.MonitorHealth(ctx, 10*time.Millisecond, func(context.Context) error {
+		select {
+		case fail := <-failing:
+			if fail {
+				dbErr = errors.New("connection refused")
+			} else {
+				dbErr = nil
+			}
+		default:
+		}
+		return dbErr
+	})
+	waitForStatus(healthpb.HealthCheckResponse_SERVING)
+
+	failing <- true
+	waitForStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	failing <- false
+	waitForStatus(healthpb.HealthCheckResponse_SERVING)
+
+	// Draining reports NOT_SERVING before the server stops
+	srv.Health.Shutdown()
+	waitForStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+func TestReflection(t *testing.T) {
+	listServices := func(reflectionEnabled bool) ([]string, error) {
+		cfg := testConfig()
+		cfg.GRPCReflection = reflectionEnabled
+		_, conn := serve(t, cfg, insecure.NewCredentials())
+
+		stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		if err := stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		}); err != nil {
+			return nil, err
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+
+		var names []string
+		for _, service := range resp.GetListServicesResponse().GetService() {
+			names = append(names, service.Name)
+		}
+		return names, nil
+	}
+
+	if _, err := listServices(false); err == nil {
+		t.Fatal("reflection should be disabled by default")
+	}
+
+	names, err := listServices(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, name := range names {
+		found = found || name == pb.UsersService_ServiceDesc.ServiceName
+	}
+	if !found {
+		t.Fatalf("services = %v, want %s listed", names, pb.UsersService_ServiceDesc.ServiceName)
+	}
+}
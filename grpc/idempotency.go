@@ -0,0 +1,168 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/antoniocfetngnu/users-api/middleware"
+	"github.com/antoniocfetngnu/users-api/models"
+	usersv2 "github.com/antoniocfetngnu/users-api/proto/v2"
+	"github.com/antoniocfetngnu/users-api/services"
+)
+
+// IdempotencyKeyHeader is the metadata entry that makes a write RPC safe
+// to retry
+const IdempotencyKeyHeader = "idempotency-key"
+
+// idempotentMethods are the RPCs that honour IdempotencyKeyHeader
+var idempotentMethods = map[string]bool{
+	usersv2.UsersService_CreateUser_FullMethodName: true,
+	usersv2.UsersService_UpdateUser_FullMethodName: true,
+	usersv2.UsersService_DeleteUser_FullMethodName: true,
+	usersv2.UsersService_Follow_FullMethodName:     true,
+	usersv2.UsersService_Unfollow_FullMethodName:   true,
+}
+
+// Idempotency replays the stored response when a write RPC is retried with
+// the same idempotency key. Keys are scoped to the caller and kept for TTL;
+// failed calls are not stored, so they can be retried with the same key. A
+// call holds its key for Lease, after which a retry can take it over.
+type Idempotency struct {
+	TTL   time.Duration
+	Lease time.Duration
+	Keys  services.IdempotencyService
+}
+
+// UnaryInterceptor must run after the Authenticator's, it needs the caller
+func (i *Idempotency) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		key := idempotencyKey(ctx)
+		if key == "" || !idempotentMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		if len(key) > 255 {
+			return nil, invalidArgument(IdempotencyKeyHeader, "must be at most 255 characters")
+		}
+
+		caller, ok := CallerFromContext(ctx)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "missing credentials")
+		}
+
+		hash, err := requestHash(req)
+		if err != nil {
+			return nil, statusError(ctx, err, "Failed to process idempotency key")
+		}
+
+		record, claimed, err := i.Keys.Claim(ctx, idempotencyScope(caller), key, info.FullMethod, hash, i.TTL, i.Lease)
+		if err != nil {
+			return nil, statusError(ctx, err, "Failed to process idempotency key")
+		}
+		if !claimed {
+			return replay(ctx, record, info.FullMethod, hash)
+		}
+
+		defer func() {
+			if p := recover(); p != nil {
+				i.release(ctx, record.ID)
+				panic(p)
+			}
+		}()
+		resp, err := handler(ctx, req)
+		if err != nil {
+			i.release(ctx, record.ID)
+			return nil, err
+		}
+
+		stored, err := anypb.New(resp.(proto.Message))
+		if err == nil {
+			var raw []byte
+			if raw, err = proto.Marshal(stored); err == nil {
+				err = i.Keys.Complete(ctx, record.ID, raw, responseUserID(resp))
+			}
+		}
+		if err != nil {
+			// The write happened; a retry would repeat it, but failing the
+			// call now would make the client retry anyway
+			log.Printf("[%s] Storing the idempotent response: %v", middleware.RequestIDFromContext(ctx), err)
+			i.release(ctx, record.ID)
+		}
+		return resp, nil
+	}
+}
+
+// release gives up a claimed key. If that fails, retries with the key get
+// Aborted until the lease is over.
+func (i *Idempotency) release(ctx context.Context, id uint) {
+	if err := i.Keys.Release(ctx, id); err != nil {
+		log.Printf("[%s] Releasing idempotency key %d: %v", middleware.RequestIDFromContext(ctx), id, err)
+	}
+}
+
+// replay returns the stored response of an earlier call with the same key
+func replay(ctx context.Context, record *models.IdempotencyKey, fullMethod string, requestHash []byte) (any, error) {
+	if record.Method != fullMethod || !bytes.Equal(record.RequestHash, requestHash) {
+		return nil, invalidArgument(IdempotencyKeyHeader, "already used for a different request")
+	}
+	if len(record.Response) == 0 {
+		return nil, status.Error(codes.Aborted, "a request with this idempotency-key is in progress")
+	}
+
+	var stored anypb.Any
+	if err := proto.Unmarshal(record.Response, &stored); err != nil {
+		return nil, statusError(ctx, err, "Failed to process idempotency key")
+	}
+	resp, err := stored.UnmarshalNew()
+	if err != nil {
+		return nil, statusError(ctx, err, "Failed to process idempotency key")
+	}
+	return resp, nil
+}
+
+// idempotencyKey returns the idempotency key of the call, if any
+func idempotencyKey(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(IdempotencyKeyHeader); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// idempotencyScope keeps the keys of different callers apart
+func idempotencyScope(caller *Caller) string {
+	if caller.Principal != nil {
+		return services.UserScope(caller.Principal.UserID)
+	}
+	return "service:" + caller.Service
+}
+
+// responseUserID returns the user a response describes, 0 for none
+func responseUserID(resp any) uint {
+	switch resp := resp.(type) {
+	case *usersv2.User:
+		return uint(resp.GetId())
+	case *usersv2.FollowResponse:
+		return uint(resp.GetFollow().GetFollowedId())
+	}
+	return 0
+}
+
+// requestHash identifies the request a key was first used with
+func requestHash(req any) ([]byte, error) {
+	raw, err := proto.MarshalOptions{Deterministic: true}.Marshal(req.(proto.Message))
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(raw)
+	return sum[:], nil
+}
@@ -0,0 +1,100 @@
+package grpc
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/services"
+)
+
+// backend holds the service layer. It is embedded in every version of
+// UsersService, which only convert requests and responses.
+type backend struct {
+	svc *services.Services
+}
+
+// getUser returns the user with the given ID
+func (b *backend) getUser(ctx context.Context, id uint64) (*models.User, error) {
+	if id == 0 {
+		return nil, invalidArgument("user_id", "must be set")
+	}
+
+	user, err := b.svc.Users.GetUser(ctx, uint(id))
+	if err != nil {
+		return nil, statusError(ctx, err, "Failed to fetch user", userResource(idString(id)))
+	}
+	return user, nil
+}
+
+// getUserByUsername returns the user with the given username
+func (b *backend) getUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	if username == "" {
+		return nil, invalidArgument("username", "must be set")
+	}
+
+	user, err := b.svc.Users.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, statusError(ctx, err, "Failed to fetch user", userResource(username))
+	}
+	return user, nil
+}
+
+// usersByIDs returns the users with the given IDs in request order, and the
+// IDs without a user
+func (b *backend) usersByIDs(ctx context.Context, ids []uint64) ([]*models.User, []uint64, error) {
+	uintIDs := make([]uint, len(ids))
+	for i, id := range ids {
+		uintIDs[i] = uint(id)
+	}
+
+	users, err := b.svc.Users.GetUsersByIDs(ctx, uintIDs)
+	if err != nil {
+		return nil, nil, statusError(ctx, err, "Failed to fetch users")
+	}
+
+	found, missing := inRequestOrder(ids, users, func(user *models.User) uint64 { return uint64(user.ID) })
+	return found, missing, nil
+}
+
+// usersByUsernames returns the users with the given usernames in request
+// order, and the usernames without a user
+func (b *backend) usersByUsernames(ctx context.Context, usernames []string) ([]*models.User, []string, error) {
+	users, err := b.svc.Users.GetUsersByUsernames(ctx, usernames)
+	if err != nil {
+		return nil, nil, statusError(ctx, err, "Failed to fetch users")
+	}
+
+	found, missing := inRequestOrder(usernames, users, func(user *models.User) string { return user.Username })
+	return found, missing, nil
+}
+
+// inRequestOrder arranges users in the order of the requested keys, each
+// user at most once, and returns the keys that matched no user
+func inRequestOrder[K comparable](keys []K, users []*models.User, keyOf func(*models.User) K) ([]*models.User, []K) {
+	byKey := make(map[K]*models.User, len(users))
+	for _, user := range users {
+		byKey[keyOf(user)] = user
+	}
+
+	found := make([]*models.User, 0, len(users))
+	var missing []K
+	seen := make(map[K]bool, len(keys))
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if user, ok := byKey[key]; ok {
+			found = append(found, user)
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	return found, missing
+}
+
+func idString(id uint64) string {
+	return strconv.FormatUint(id, 10)
+}
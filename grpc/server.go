@@ -2,63 +2,163 @@ package grpc
 
 import (
 	"context"
-	"fmt"
+	"time"
 
-	"github.com/antoniocfetngnu/users-api/database"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/antoniocfetngnu/users-api/config"
 	"github.com/antoniocfetngnu/users-api/models"
 	pb "github.com/antoniocfetngnu/users-api/proto"
+	usersv2 "github.com/antoniocfetngnu/users-api/proto/v2"
+	"github.com/antoniocfetngnu/users-api/services"
 )
 
 // UsersServer implements the gRPC UsersService
 type UsersServer struct {
 	pb.UnimplementedUsersServiceServer
+	backend
 }
 
-// GetUser returns a single user by ID
-func (s *UsersServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.UserResponse, error) {
-	var user models.User
+// Server is the gRPC server with both versions of UsersService and the
+// standard health service registered
+type Server struct {
+	*grpc.Server
+	Health *health.Server
+}
 
-	if err := database.DB.First(&user, req.UserId).Error; err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+// NewServer builds the gRPC server. Every UsersService call must be
+// authenticated (see Authenticator) and allowed by the GRPC_SERVICE_ACL,
+// writes honour idempotency keys (see Idempotency), and TLS is used when a
+// certificate is configured. Health starts out NOT_SERVING until
+// MonitorHealth reports otherwise.
+func NewServer(cfg *config.Config, svc *services.Services) (*Server, error) {
+	creds, err := serverCredentials(cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	return &pb.UserResponse{
-		Id:        uint32(user.ID),
-		Username:  user.Username,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	}, nil
+	opts := interceptors(cfg, svc)
+	if creds != nil {
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	srv := &Server{Server: grpc.NewServer(opts...), Health: health.NewServer()}
+	pb.RegisterUsersServiceServer(srv, &UsersServer{backend: backend{svc}})
+	usersv2.RegisterUsersServiceServer(srv, &UsersServerV2{backend: backend{svc}})
+
+	for _, service := range healthServices {
+		srv.Health.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	healthpb.RegisterHealthServer(srv, srv.Health)
+
+	if cfg.GRPCReflection {
+		reflection.Register(srv)
+	}
+	return srv, nil
+}
+
+// interceptors returns the options every server of UsersService is built
+// with: authentication and authorization, then idempotency keys
+func interceptors(cfg *config.Config, svc *services.Services) []grpc.ServerOption {
+	auth := &Authenticator{
+		ServiceTokens: cfg.GRPCServiceTokens,
+		Authorize:     ACLAuthorizer(cfg.GRPCServiceACL),
+	}
+	idempotency := &Idempotency{TTL: cfg.GRPCIdempotencyTTL, Lease: cfg.GRPCIdempotencyLease, Keys: svc.Idempotency}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(auth.UnaryInterceptor(), idempotency.UnaryInterceptor()),
+		grpc.ChainStreamInterceptor(auth.StreamInterceptor()),
+	}
+}
+
+// GracefulStop marks the server NOT_SERVING so load balancers stop sending
+// traffic, then waits for in-flight RPCs to finish. After timeout the
+// remaining RPCs are cancelled.
+func (s *Server) GracefulStop(timeout time.Duration) {
+	s.Health.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.Server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		s.Server.Stop()
+	}
+}
+
+// GetUser returns a single user by ID
+func (s *UsersServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.UserResponse, error) {
+	user, err := s.getUser(ctx, uint64(req.UserId))
+	if err != nil {
+		return nil, err
+	}
+	return toUserResponse(user), nil
 }
 
-// GetUsers returns multiple users by IDs (batch request)
+// GetUsers returns multiple users by IDs (batch request). Users come back in
+// request order; IDs without a user are listed in missing_ids.
 func (s *UsersServer) GetUsers(ctx context.Context, req *pb.GetUsersRequest) (*pb.UsersResponse, error) {
-	if len(req.UserIds) == 0 {
-		return &pb.UsersResponse{Users: []*pb.UserResponse{}}, nil
+	ids := make([]uint64, len(req.UserIds))
+	for i, id := range req.UserIds {
+		ids[i] = uint64(id)
 	}
 
-	var users []models.User
+	users, missing, err := s.usersByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.UsersResponse{Users: toUserResponses(users)}
+	for _, id := range missing {
+		resp.MissingIds = append(resp.MissingIds, uint32(id))
+	}
+	return resp, nil
+}
 
-	// Query users with IN clause
-	if err := database.DB.Where("id IN ?", req.UserIds).Find(&users).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch users: %w", err)
+// GetUserByUsername returns a single user by username
+func (s *UsersServer) GetUserByUsername(ctx context.Context, req *pb.GetUserByUsernameRequest) (*pb.UserResponse, error) {
+	user, err := s.getUserByUsername(ctx, req.Username)
+	if err != nil {
+		return nil, err
 	}
+	return toUserResponse(user), nil
+}
 
-	// Convert to proto response
-	userResponses := make([]*pb.UserResponse, len(users))
+// GetUsersByUsernames returns multiple users by usernames (batch request).
+// Users come back in request order; unknown usernames are listed in
+// missing_usernames.
+func (s *UsersServer) GetUsersByUsernames(ctx context.Context, req *pb.GetUsersByUsernamesRequest) (*pb.UsersResponse, error) {
+	users, missing, err := s.usersByUsernames(ctx, req.Usernames)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UsersResponse{Users: toUserResponses(users), MissingUsernames: missing}, nil
+}
+
+func toUserResponses(users []*models.User) []*pb.UserResponse {
+	responses := make([]*pb.UserResponse, len(users))
 	for i, user := range users {
-		userResponses[i] = &pb.UserResponse{
-			Id:        uint32(user.ID),
-			Username:  user.Username,
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Email:     user.Email,
-			CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		}
+		responses[i] = toUserResponse(user)
 	}
+	return responses
+}
 
-	return &pb.UsersResponse{Users: userResponses}, nil
+// toUserResponse converts a user to its protobuf representation
+func toUserResponse(user *models.User) *pb.UserResponse {
+	return &pb.UserResponse{
+		Id:        uint32(user.ID),
+		Username:  user.Username,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
 }
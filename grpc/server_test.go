@@ -0,0 +1,279 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/gorm"
+
+	"github.com/antoniocfetngnu/users-api/config"
+	"github.com/antoniocfetngnu/users-api/models"
+	pb "github.com/antoniocfetngnu/users-api/proto"
+	"github.com/antoniocfetngnu/users-api/repository"
+	"github.com/antoniocfetngnu/users-api/repository/repositorytest"
+	"github.com/antoniocfetngnu/users-api/services"
+	"github.com/antoniocfetngnu/users-api/utils"
+)
+
+// testDB and testServices are set by setupDB
+var (
+	testDB       *gorm.DB
+	testServices *services.Services
+)
+
+// setupDB backs testServices with a fresh in-memory SQLite database with
+// `users` users where user1 is followed by every other user
+func setupDB(t *testing.T, users int) {
+	t.Helper()
+
+	db := repositorytest.OpenSQLite(t)
+
+	for i := 1; i <= users; i++ {
+		name := fmt.Sprintf("user%d", i)
+		if err := db.Create(&models.User{FirstName: name, LastName: name, Email: name + "@example.com", Username: name, Password: "x"}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 2; i <= users; i++ {
+		db.Create(&models.Follower{FollowerID: uint(i), FollowedID: 1, FollowedSince: time.Now()})
+	}
+
+	testDB = db
+	testServices = services.New(repository.NewGormStore(db), services.Config{})
+}
+
+// newClient serves the gRPC server over an in-memory connection, calling
+// it as a service
+func newClient(t *testing.T) pb.UsersServiceClient {
+	t.Helper()
+	return dial(t, testConfig(), insecure.NewCredentials(), grpc.WithPerRPCCredentials(bearer(testServiceToken)))
+}
+
+const testServiceToken = "service-secret"
+
+func testConfig() *config.Config {
+	cfg := &config.Config{
+		JWTSecret:            "test-secret",
+		GRPCServiceTokens:    map[string]string{"tests": testServiceToken},
+		GRPCIdempotencyTTL:   time.Hour,
+		GRPCIdempotencyLease: time.Minute,
+	}
+	utils.InitJWT(cfg)
+	return cfg
+}
+
+// dial serves NewServer(cfg, testServices) over an in-memory connection
+func dial(t *testing.T, cfg *config.Config, creds credentials.TransportCredentials, opts ...grpc.DialOption) pb.UsersServiceClient {
+	t.Helper()
+	_, conn := serve(t, cfg, creds, opts...)
+	return pb.NewUsersServiceClient(conn)
+}
+
+// serve starts NewServer(cfg, testServices) on an in-memory listener and connects to it
+func serve(t *testing.T, cfg *config.Config, creds credentials.TransportCredentials, opts ...grpc.DialOption) (*Server, *grpc.ClientConn) {
+	t.Helper()
+
+	srv, err := NewServer(cfg, testServices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	opts = append(opts,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(creds),
+	)
+	conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return srv, conn
+}
+
+// bearer sends a token in the authorization metadata
+type bearer string
+
+func (b bearer) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(b)}, nil
+}
+
+func (bearer) RequireTransportSecurity() bool {
+	return false
+}
+
+func TestGetUsersByUsernames(t *testing.T) {
+	setupDB(t, 3)
+	client := newClient(t)
+
+	user, err := client.GetUserByUsername(context.Background(), &pb.GetUserByUsernameRequest{Username: "user2"})
+	if err != nil || user.Id != 2 {
+		t.Fatalf("GetUserByUsername: %v %v", user, err)
+	}
+
+	resp, err := client.GetUsersByUsernames(context.Background(), &pb.GetUsersByUsernamesRequest{Usernames: []string{"user3", "missing", "user1", "user3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := usernames(resp.Users); got != "[user3 user1]" {
+		t.Fatalf("users = %s, want request order", got)
+	}
+	if fmt.Sprint(resp.MissingUsernames) != "[missing]" {
+		t.Fatalf("missing_usernames = %v", resp.MissingUsernames)
+	}
+}
+
+func TestGetUsersKeepsRequestOrder(t *testing.T) {
+	setupDB(t, 4)
+	testDB.Delete(&models.User{}, 2)
+
+	resp, err := newClient(t).GetUsers(context.Background(), &pb.GetUsersRequest{UserIds: []uint32{3, 2, 99, 1, 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := usernames(resp.Users); got != "[user3 user1]" {
+		t.Fatalf("users = %s, want request order", got)
+	}
+	if fmt.Sprint(resp.MissingIds) != "[2 99]" {
+		t.Fatalf("missing_ids = %v", resp.MissingIds)
+	}
+}
+
+func TestStatusCodes(t *testing.T) {
+	setupDB(t, 1)
+	client := newClient(t)
+
+	_, err := client.GetUser(context.Background(), &pb.GetUserRequest{UserId: 42})
+	st := status.Convert(err)
+	if st.Code() != codes.NotFound {
+		t.Fatalf("missing user: code = %v, want NotFound", st.Code())
+	}
+	if len(st.Details()) != 1 || st.Details()[0].(*errdetails.ResourceInfo).ResourceName != "42" {
+		t.Fatalf("missing user: details = %v", st.Details())
+	}
+
+	_, err = client.GetUserByUsername(context.Background(), &pb.GetUserByUsernameRequest{Username: "nobody"})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Fatalf("unknown username: code = %v, want NotFound", code)
+	}
+
+	_, err = client.GetUser(context.Background(), &pb.GetUserRequest{})
+	st = status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("unset user_id: code = %v, want InvalidArgument", st.Code())
+	}
+	if violations := st.Details()[0].(*errdetails.BadRequest).FieldViolations; violations[0].Field != "user_id" {
+		t.Fatalf("unset user_id: violations = %v", violations)
+	}
+}
+
+func TestCheckFollows(t *testing.T) {
+	setupDB(t, 3)
+
+	resp, err := newClient(t).CheckFollows(context.Background(), &pb.CheckFollowsRequest{Pairs: []*pb.FollowPair{
+		{FollowerId: 2, FollowedId: 1},
+		{FollowerId: 1, FollowedId: 2},
+		{FollowerId: 3, FollowedId: 1},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []bool{true, false, true}
+	for i, result := range resp.Results {
+		if result.Follows != want[i] {
+			t.Errorf("pair %d: follows = %v, want %v", i, result.Follows, want[i])
+		}
+	}
+}
+
+func TestListFollowersPages(t *testing.T) {
+	setupDB(t, 6)
+	client := newClient(t)
+
+	var followers []uint32
+	token := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("too many pages")
+		}
+		resp, err := client.ListFollowers(context.Background(), &pb.ListFollowsRequest{UserId: 1, PageSize: 2, PageToken: token})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.TotalCount != 5 {
+			t.Fatalf("total_count = %d, want 5", resp.TotalCount)
+		}
+		for _, follow := range resp.Follows {
+			if follow.User.Id != follow.FollowerId {
+				t.Fatalf("user should be the follower: %v", follow)
+			}
+			followers = append(followers, follow.FollowerId)
+		}
+		if token = resp.NextPageToken; token == "" {
+			break
+		}
+	}
+
+	if fmt.Sprint(followers) != "[2 3 4 5 6]" {
+		t.Fatalf("followers = %v", followers)
+	}
+
+	_, err := client.ListFollowers(context.Background(), &pb.ListFollowsRequest{UserId: 1, PageToken: "garbage"})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Fatalf("invalid page_token: code = %v, want InvalidArgument", code)
+	}
+}
+
+func TestFollowerIDs(t *testing.T) {
+	setupDB(t, 6)
+	client := newClient(t)
+
+	resp, err := client.GetFollowerIDs(context.Background(), &pb.GetFollowerIDsRequest{UserId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(resp.FollowerIds) != "[2 3 4 5 6]" {
+		t.Fatalf("follower_ids = %v", resp.FollowerIds)
+	}
+
+	stream, err := client.StreamFollowerIDs(context.Background(), &pb.StreamFollowerIDsRequest{UserId: 1, ChunkSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var chunks [][]uint32
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, chunk.FollowerIds)
+	}
+	if fmt.Sprint(chunks) != "[[2 3] [4 5] [6]]" {
+		t.Fatalf("chunks = %v", chunks)
+	}
+}
+
+func usernames(users []*pb.UserResponse) string {
+	names := make([]string, len(users))
+	for i, user := range users {
+		names[i] = user.Username
+	}
+	return fmt.Sprint(names)
+}
@@ -0,0 +1,215 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/antoniocfetngnu/users-api/models"
+	usersv2 "github.com/antoniocfetngnu/users-api/proto/v2"
+	"github.com/antoniocfetngnu/users-api/services"
+)
+
+// UsersServerV2 implements the gRPC users.v2.UsersService
+type UsersServerV2 struct {
+	usersv2.UnimplementedUsersServiceServer
+	backend
+}
+
+// GetUser returns a single user by ID
+func (s *UsersServerV2) GetUser(ctx context.Context, req *usersv2.GetUserRequest) (*usersv2.User, error) {
+	mask, err := parseReadMask(req.ReadMask)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.getUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return mask.apply(toUser(user)), nil
+}
+
+// GetUsers returns multiple users by IDs in request order; IDs without a
+// user are listed in missing_ids
+func (s *UsersServerV2) GetUsers(ctx context.Context, req *usersv2.GetUsersRequest) (*usersv2.GetUsersResponse, error) {
+	mask, err := parseReadMask(req.ReadMask)
+	if err != nil {
+		return nil, err
+	}
+
+	users, missing, err := s.usersByIDs(ctx, req.UserIds)
+	if err != nil {
+		return nil, err
+	}
+	return &usersv2.GetUsersResponse{Users: toUsers(users, mask), MissingIds: missing}, nil
+}
+
+// GetUserByUsername returns a single user by username
+func (s *UsersServerV2) GetUserByUsername(ctx context.Context, req *usersv2.GetUserByUsernameRequest) (*usersv2.User, error) {
+	mask, err := parseReadMask(req.ReadMask)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.getUserByUsername(ctx, req.Username)
+	if err != nil {
+		return nil, err
+	}
+	return mask.apply(toUser(user)), nil
+}
+
+// GetUsersByUsernames returns multiple users by usernames in request order;
+// unknown usernames are listed in missing_usernames
+func (s *UsersServerV2) GetUsersByUsernames(ctx context.Context, req *usersv2.GetUsersByUsernamesRequest) (*usersv2.GetUsersByUsernamesResponse, error) {
+	mask, err := parseReadMask(req.ReadMask)
+	if err != nil {
+		return nil, err
+	}
+
+	users, missing, err := s.usersByUsernames(ctx, req.Usernames)
+	if err != nil {
+		return nil, err
+	}
+	return &usersv2.GetUsersByUsernamesResponse{Users: toUsers(users, mask), MissingUsernames: missing}, nil
+}
+
+// CheckFollows reports which of the pairs are existing follow relationships
+func (s *UsersServerV2) CheckFollows(ctx context.Context, req *usersv2.CheckFollowsRequest) (*usersv2.CheckFollowsResponse, error) {
+	pairs := make([]services.FollowPair, len(req.Pairs))
+	for i, pair := range req.Pairs {
+		pairs[i] = services.FollowPair{FollowerID: uint(pair.FollowerId), FollowedID: uint(pair.FollowedId)}
+	}
+
+	follows, err := s.checkFollows(ctx, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*usersv2.FollowCheck, len(pairs))
+	for i, pair := range req.Pairs {
+		results[i] = &usersv2.FollowCheck{FollowerId: pair.FollowerId, FollowedId: pair.FollowedId, Follows: follows[i]}
+	}
+	return &usersv2.CheckFollowsResponse{Results: results}, nil
+}
+
+// ListFollowers returns a page of the followers of a user
+func (s *UsersServerV2) ListFollowers(ctx context.Context, req *usersv2.ListFollowsRequest) (*usersv2.ListFollowsResponse, error) {
+	return s.listFollows(ctx, req, s.svc.Follows.ListFollowersPage, followerOf)
+}
+
+// ListFollowing returns a page of the users a user follows
+func (s *UsersServerV2) ListFollowing(ctx context.Context, req *usersv2.ListFollowsRequest) (*usersv2.ListFollowsResponse, error) {
+	return s.listFollows(ctx, req, s.svc.Follows.ListFollowingPage, followedOf)
+}
+
+// GetFollowerIDs returns the IDs of all followers of a user. Use
+// StreamFollowerIDs for large follower sets.
+func (s *UsersServerV2) GetFollowerIDs(ctx context.Context, req *usersv2.GetFollowerIDsRequest) (*usersv2.FollowerIDsResponse, error) {
+	ids, err := s.followerIDs(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return &usersv2.FollowerIDsResponse{FollowerIds: toUint64s(ids)}, nil
+}
+
+// StreamFollowerIDs sends the IDs of all followers of a user in chunks
+func (s *UsersServerV2) StreamFollowerIDs(req *usersv2.StreamFollowerIDsRequest, stream usersv2.UsersService_StreamFollowerIDsServer) error {
+	return s.streamFollowerIDs(stream.Context(), req.UserId, req.ChunkSize, func(ids []uint) error {
+		return stream.Send(&usersv2.FollowerIDsResponse{FollowerIds: toUint64s(ids)})
+	})
+}
+
+func (s *UsersServerV2) listFollows(ctx context.Context, req *usersv2.ListFollowsRequest, list listFollowsFunc, other func(*models.Follower) *models.User) (*usersv2.ListFollowsResponse, error) {
+	mask, err := parseReadMask(req.ReadMask)
+	if err != nil {
+		return nil, err
+	}
+
+	page, next, err := s.followsPage(ctx, list, req.UserId, req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &usersv2.ListFollowsResponse{
+		Follows:       make([]*usersv2.Follow, len(page.Items)),
+		NextPageToken: next,
+		TotalCount:    int32(page.TotalCount),
+	}
+	for i, edge := range page.Items {
+		resp.Follows[i] = &usersv2.Follow{
+			FollowerId: uint64(edge.FollowerID),
+			FollowedId: uint64(edge.FollowedID),
+			FollowTime: timestamppb.New(edge.FollowedSince),
+			User:       mask.apply(toUser(other(edge))),
+		}
+	}
+	return resp, nil
+}
+
+// readMask is the set of User fields a request asked for, nil for all
+type readMask map[protoreflect.Name]bool
+
+// parseReadMask validates a read_mask against User. Only top-level fields
+// can be selected.
+func parseReadMask(mask *fieldmaskpb.FieldMask) (readMask, error) {
+	if len(mask.GetPaths()) == 0 {
+		return nil, nil
+	}
+	if !mask.IsValid(&usersv2.User{}) {
+		return nil, invalidArgument("read_mask", "paths must be User fields")
+	}
+
+	fields := make(readMask, len(mask.Paths))
+	for _, path := range mask.Paths {
+		fields[protoreflect.Name(path)] = true
+	}
+	return fields, nil
+}
+
+// apply clears the fields of user that are not in the mask
+func (m readMask) apply(user *usersv2.User) *usersv2.User {
+	if m == nil {
+		return user
+	}
+
+	msg := user.ProtoReflect()
+	msg.Range(func(field protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !m[field.Name()] {
+			msg.Clear(field)
+		}
+		return true
+	})
+	return user
+}
+
+// toUser converts a user to its v2 protobuf representation
+func toUser(user *models.User) *usersv2.User {
+	return &usersv2.User{
+		Id:         uint64(user.ID),
+		Username:   user.Username,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		Email:      user.Email,
+		CreateTime: timestamppb.New(user.CreatedAt),
+		UpdateTime: timestamppb.New(user.UpdatedAt),
+	}
+}
+
+func toUsers(users []*models.User, mask readMask) []*usersv2.User {
+	out := make([]*usersv2.User, len(users))
+	for i, user := range users {
+		out[i] = mask.apply(toUser(user))
+	}
+	return out
+}
+
+func toUint64s(ids []uint) []uint64 {
+	out := make([]uint64, len(ids))
+	for i, id := range ids {
+		out[i] = uint64(id)
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	pb "github.com/antoniocfetngnu/users-api/proto"
+	usersv2 "github.com/antoniocfetngnu/users-api/proto/v2"
+)
+
+// newClients connects to a server running both versions of UsersService
+func newClients(t *testing.T) (pb.UsersServiceClient, usersv2.UsersServiceClient) {
+	t.Helper()
+	_, conn := serve(t, testConfig(), insecure.NewCredentials(), grpc.WithPerRPCCredentials(bearer(testServiceToken)))
+	return pb.NewUsersServiceClient(conn), usersv2.NewUsersServiceClient(conn)
+}
+
+func TestV2GetUser(t *testing.T) {
+	setupDB(t, 2)
+	v1, v2 := newClients(t)
+
+	user, err := v2.GetUser(context.Background(), &usersv2.GetUserRequest{UserId: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Id != 2 || user.Email != "user2@example.com" || !user.CreateTime.IsValid() || user.CreateTime.AsTime().IsZero() {
+		t.Fatalf("user = %v", user)
+	}
+
+	// v1 keeps serving the same user side by side
+	old, err := v1.GetUser(context.Background(), &pb.GetUserRequest{UserId: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.Username != user.Username || old.CreatedAt == "" {
+		t.Fatalf("v1 user = %v", old)
+	}
+
+	_, err = v2.GetUser(context.Background(), &usersv2.GetUserRequest{UserId: 42})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Fatalf("missing user: code = %v, want NotFound", code)
+	}
+}
+
+func TestV2ReadMask(t *testing.T) {
+	setupDB(t, 3)
+	_, v2 := newClients(t)
+
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"id", "username"}}
+	resp, err := v2.GetUsers(context.Background(), &usersv2.GetUsersRequest{UserIds: []uint64{3, 99, 1}, ReadMask: mask})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Users) != 2 || resp.Users[0].Username != "user3" || resp.Users[1].Id != 1 {
+		t.Fatalf("users = %v", resp.Users)
+	}
+	for _, user := range resp.Users {
+		if user.Email != "" || user.FirstName != "" || user.CreateTime != nil {
+			t.Fatalf("fields outside the read_mask returned: %v", user)
+		}
+	}
+	if fmt.Sprint(resp.MissingIds) != "[99]" {
+		t.Fatalf("missing_ids = %v", resp.MissingIds)
+	}
+
+	follows, err := v2.ListFollowers(context.Background(), &usersv2.ListFollowsRequest{
+		UserId:   1,
+		ReadMask: &fieldmaskpb.FieldMask{Paths: []string{"username"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(follows.Follows) != 2 || follows.Follows[0].User.Username != "user2" || follows.Follows[0].User.Id != 0 {
+		t.Fatalf("follows = %v", follows.Follows)
+	}
+	if follows.Follows[0].FollowTime.AsTime().IsZero() {
+		t.Fatalf("follow_time not set: %v", follows.Follows[0])
+	}
+
+	_, err = v2.GetUser(context.Background(), &usersv2.GetUserRequest{UserId: 1, ReadMask: &fieldmaskpb.FieldMask{Paths: []string{"password"}}})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Fatalf("unknown read_mask path: code = %v, want InvalidArgument", code)
+	}
+}
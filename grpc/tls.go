@@ -0,0 +1,47 @@
+package grpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+
+	"github.com/antoniocfetngnu/users-api/config"
+)
+
+// serverCredentials returns the TLS credentials configured for the gRPC
+// server, or nil to serve plaintext
+func serverCredentials(cfg *config.Config) (credentials.TransportCredentials, error) {
+	if cfg.GRPCTLSCert == "" && cfg.GRPCTLSKey == "" {
+		if cfg.GRPCClientCA != "" {
+			return nil, fmt.Errorf("GRPC_CLIENT_CA requires GRPC_TLS_CERT and GRPC_TLS_KEY")
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.GRPCTLSCert, cfg.GRPCTLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load gRPC TLS certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if cfg.GRPCClientCA != "" {
+		pem, err := os.ReadFile(cfg.GRPCClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gRPC client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.GRPCClientCA)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
@@ -0,0 +1,150 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/antoniocfetngnu/users-api/events"
+	"github.com/antoniocfetngnu/users-api/models"
+	usersv2 "github.com/antoniocfetngnu/users-api/proto/v2"
+)
+
+const (
+	// watchBatchSize is how many changes WatchUsers reads from the log at once
+	watchBatchSize = 500
+
+	// watchPollInterval is how often WatchUsers reads the log without being
+	// notified, for changes made by other instances of the service
+	watchPollInterval = 5 * time.Second
+)
+
+var changeTypes = map[string]usersv2.UserChange_Type{
+	models.UserChangeCreated: usersv2.UserChange_CREATED,
+	models.UserChangeUpdated: usersv2.UserChange_UPDATED,
+	models.UserChangeDeleted: usersv2.UserChange_DELETED,
+}
+
+// WatchUsers streams the user change log from start_after on. The log
+// (models.UserChange) is the source of truth; user events only wake the
+// stream up, so a dropped event delays a change but never loses it.
+func (s *UsersServerV2) WatchUsers(req *usersv2.WatchUsersRequest, stream usersv2.UsersService_WatchUsersServer) error {
+	ctx := stream.Context()
+
+	mask, err := parseReadMask(req.ReadMask)
+	if err != nil {
+		return err
+	}
+
+	// Subscribe before reading the log so no change falls in between
+	notify, err := userChangeNotifications(ctx)
+	if err != nil {
+		return statusError(ctx, err, "Failed to watch users")
+	}
+
+	cursor := req.StartAfter
+	if cursor == 0 {
+		if cursor, err = s.svc.Users.LatestUserChange(ctx); err != nil {
+			return statusError(ctx, err, "Failed to watch users")
+		}
+	}
+
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if cursor, err = s.sendUserChanges(ctx, stream, cursor, mask); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-notify:
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendUserChanges sends every logged change after cursor and returns the
+// sequence of the last one sent
+func (s *UsersServerV2) sendUserChanges(ctx context.Context, stream usersv2.UsersService_WatchUsersServer, cursor uint64, mask readMask) (uint64, error) {
+	for {
+		changes, err := s.svc.Users.ListUserChanges(ctx, cursor, watchBatchSize)
+		if err != nil {
+			return cursor, statusError(ctx, err, "Failed to read user changes")
+		}
+		if len(changes) == 0 {
+			return cursor, nil
+		}
+
+		users, err := s.currentUsers(ctx, changes)
+		if err != nil {
+			return cursor, statusError(ctx, err, "Failed to fetch users")
+		}
+
+		for _, change := range changes {
+			msg := &usersv2.UserChange{
+				Sequence:   change.ID,
+				Type:       changeTypes[change.Action],
+				UserId:     uint64(change.UserID),
+				ChangeTime: timestamppb.New(change.CreatedAt),
+			}
+			if user, ok := users[change.UserID]; ok && change.Action != models.UserChangeDeleted {
+				msg.User = mask.apply(toUser(user))
+			}
+			if err := stream.Send(msg); err != nil {
+				return cursor, err
+			}
+			cursor = change.ID
+		}
+
+		if len(changes) < watchBatchSize {
+			return cursor, nil
+		}
+	}
+}
+
+// currentUsers loads the users the changes are about, deleted users are
+// left out
+func (s *UsersServerV2) currentUsers(ctx context.Context, changes []*models.UserChange) (map[uint]*models.User, error) {
+	ids := make([]uint, len(changes))
+	for i, change := range changes {
+		ids[i] = change.UserID
+	}
+
+	users, err := s.svc.Users.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]*models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+	return byID, nil
+}
+
+// userChangeNotifications signals whenever a user is created, updated or
+// deleted. Signals coalesce: a pending one stands for any number of changes.
+func userChangeNotifications(ctx context.Context) (<-chan struct{}, error) {
+	notify := make(chan struct{}, 1)
+
+	for _, eventType := range []events.Type{events.UserCreated, events.UserUpdated, events.UserDeleted} {
+		ch, err := events.Subscribe(ctx, eventType, events.AllUsers)
+		if err != nil {
+			return nil, err
+		}
+		go func() {
+			for range ch {
+				select {
+				case notify <- struct{}{}:
+				default:
+				}
+			}
+		}()
+	}
+	return notify, nil
+}
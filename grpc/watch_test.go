@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"github.com/antoniocfetngnu/users-api/models"
+	usersv2 "github.com/antoniocfetngnu/users-api/proto/v2"
+	"github.com/antoniocfetngnu/users-api/services"
+)
+
+func TestWatchUsers(t *testing.T) {
+	setupDB(t, 2)
+	_, client := newClients(t)
+	ctx := services.AsService(context.Background(), "tests")
+
+	rename := func(id uint, name string) {
+		t.Helper()
+		if _, err := testServices.Users.UpdateUser(ctx, id, models.UpdateUserRequest{FirstName: &name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	watch := func(startAfter uint64, mask *fieldmaskpb.FieldMask) (func() *usersv2.UserChange, context.CancelFunc) {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		stream, err := client.WatchUsers(ctx, &usersv2.WatchUsersRequest{StartAfter: startAfter, ReadMask: mask})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return func() *usersv2.UserChange {
+			t.Helper()
+			change, err := stream.Recv()
+			if err != nil {
+				t.Fatal(err)
+			}
+			return change
+		}, cancel
+	}
+
+	rename(1, "Ada")
+
+	recv, cancel := watch(1, nil)
+	defer cancel()
+
+	// Changes made while connecting are replayed from the log
+	if _, err := testServices.Users.Register(ctx, models.RegisterRequest{
+		FirstName: "New", LastName: "User", Email: "user3@example.com", Username: "user3", Password: "secret123",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := testServices.Users.DeleteUser(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	created := recv()
+	if created.Sequence != 2 || created.Type != usersv2.UserChange_CREATED || created.User.GetUsername() != "user3" {
+		t.Fatalf("created = %v", created)
+	}
+	deleted := recv()
+	if deleted.Sequence != 3 || deleted.Type != usersv2.UserChange_DELETED || deleted.UserId != 2 || deleted.User != nil {
+		t.Fatalf("deleted = %v", deleted)
+	}
+
+	// Live changes are pushed as they happen
+	rename(3, "Grace")
+	updated := recv()
+	if updated.Sequence != 4 || updated.Type != usersv2.UserChange_UPDATED || updated.User.GetFirstName() != "Grace" {
+		t.Fatalf("updated = %v", updated)
+	}
+	cancel()
+
+	// Reconnecting with the last received sequence resumes right after it
+	recv, cancel = watch(created.Sequence, &fieldmaskpb.FieldMask{Paths: []string{"username"}})
+	defer cancel()
+	if change := recv(); change.Sequence != 3 {
+		t.Fatalf("resumed at %v, want sequence 3", change)
+	}
+	if change := recv(); change.Sequence != 4 || change.User.GetUsername() != "user3" || change.User.GetFirstName() != "" {
+		t.Fatalf("change = %v", change)
+	}
+}
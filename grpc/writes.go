@@ -0,0 +1,108 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/antoniocfetngnu/users-api/models"
+	usersv2 "github.com/antoniocfetngnu/users-api/proto/v2"
+)
+
+// CreateUser creates a user account
+func (s *UsersServerV2) CreateUser(ctx context.Context, req *usersv2.CreateUserRequest) (*usersv2.User, error) {
+	if err := authorizeCreate(ctx); err != nil {
+		return nil, err
+	}
+
+	user, err := s.svc.Users.Register(ctx, models.RegisterRequest{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Email:     req.Email,
+		Username:  req.Username,
+		Password:  req.Password,
+	})
+	if err != nil {
+		return nil, statusError(ctx, err, "Failed to create user")
+	}
+	return toUser(user), nil
+}
+
+// UpdateUser updates the fields of a user that are set in the request
+func (s *UsersServerV2) UpdateUser(ctx context.Context, req *usersv2.UpdateUserRequest) (*usersv2.User, error) {
+	if req.UserId == 0 {
+		return nil, invalidArgument("user_id", "must be set")
+	}
+	user, err := s.svc.Users.UpdateUser(ctx, uint(req.UserId), models.UpdateUserRequest{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Email:     req.Email,
+		Username:  req.Username,
+		Password:  req.Password,
+	})
+	if err != nil {
+		return nil, statusError(ctx, err, "Failed to update user", userResource(idString(req.UserId)))
+	}
+	return toUser(user), nil
+}
+
+// DeleteUser soft-deletes a user
+func (s *UsersServerV2) DeleteUser(ctx context.Context, req *usersv2.DeleteUserRequest) (*emptypb.Empty, error) {
+	if req.UserId == 0 {
+		return nil, invalidArgument("user_id", "must be set")
+	}
+	if err := s.svc.Users.DeleteUser(ctx, uint(req.UserId)); err != nil {
+		return nil, statusError(ctx, err, "Failed to delete user", userResource(idString(req.UserId)))
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// Follow makes follower_id follow followed_id
+func (s *UsersServerV2) Follow(ctx context.Context, req *usersv2.FollowRequest) (*usersv2.FollowResponse, error) {
+	if req.FollowerId == 0 {
+		return nil, invalidArgument("follower_id", "must be set")
+	}
+	edge, created, err := s.svc.Follows.Follow(ctx, uint(req.FollowerId), uint(req.FollowedId))
+	if err != nil {
+		return nil, statusError(ctx, err, "Failed to follow user", userResource(idString(req.FollowedId)))
+	}
+
+	return &usersv2.FollowResponse{
+		Follow: &usersv2.Follow{
+			FollowerId: uint64(edge.FollowerID),
+			FollowedId: uint64(edge.FollowedID),
+			FollowTime: timestamppb.New(edge.FollowedSince),
+			User:       toUser(&edge.Followed),
+		},
+		Created: created,
+	}, nil
+}
+
+// Unfollow makes follower_id unfollow followed_id
+func (s *UsersServerV2) Unfollow(ctx context.Context, req *usersv2.UnfollowRequest) (*usersv2.UnfollowResponse, error) {
+	if req.FollowerId == 0 {
+		return nil, invalidArgument("follower_id", "must be set")
+	}
+	removed, err := s.svc.Follows.Unfollow(ctx, uint(req.FollowerId), uint(req.FollowedId))
+	if err != nil {
+		return nil, statusError(ctx, err, "Failed to unfollow user")
+	}
+	return &usersv2.UnfollowResponse{Removed: removed}, nil
+}
+
+// authorizeCreate lets services and admins create accounts. End users
+// create theirs through the REST register endpoint instead. Changes to
+// existing accounts are checked by the service layer.
+func authorizeCreate(ctx context.Context) error {
+	caller, ok := CallerFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "missing credentials")
+	}
+	if caller.Principal == nil || caller.Principal.IsAdmin() {
+		return nil
+	}
+	return status.Error(codes.PermissionDenied, "users may not create accounts")
+}
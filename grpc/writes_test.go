@@ -0,0 +1,265 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/antoniocfetngnu/users-api/models"
+	usersv2 "github.com/antoniocfetngnu/users-api/proto/v2"
+	"github.com/antoniocfetngnu/users-api/repository"
+	"github.com/antoniocfetngnu/users-api/services"
+	"github.com/antoniocfetngnu/users-api/utils"
+)
+
+// newUserClient calls the v2 service with the JWT of user id
+func newUserClient(t *testing.T, id uint, role string) usersv2.UsersServiceClient {
+	t.Helper()
+
+	cfg := testConfig()
+	token, err := utils.GenerateJWT(id, "", "", role)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, conn := serve(t, cfg, insecure.NewCredentials(), grpc.WithPerRPCCredentials(bearer(token)))
+	return usersv2.NewUsersServiceClient(conn)
+}
+
+func withIdempotencyKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), IdempotencyKeyHeader, key)
+}
+
+func TestCreateUser(t *testing.T) {
+	setupDB(t, 1)
+	_, client := newClients(t)
+
+	req := &usersv2.CreateUserRequest{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Username: "ada", Password: "secret123"}
+	user, err := client.CreateUser(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Id == 0 || user.Username != "ada" {
+		t.Fatalf("user = %v", user)
+	}
+
+	_, err = client.CreateUser(context.Background(), req)
+	if code := status.Code(err); code != codes.AlreadyExists {
+		t.Fatalf("duplicate user: code = %v, want AlreadyExists", code)
+	}
+
+	_, err = client.CreateUser(context.Background(), &usersv2.CreateUserRequest{FirstName: "X", LastName: "Y", Email: "not-an-email", Username: "x", Password: "secret123"})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Fatalf("invalid email: code = %v, want InvalidArgument", code)
+	}
+
+	// End users can't create accounts over gRPC
+	_, err = newUserClient(t, 1, models.RoleUser).CreateUser(context.Background(), &usersv2.CreateUserRequest{
+		FirstName: "Eve", LastName: "E", Email: "eve@example.com", Username: "eve", Password: "secret123",
+	})
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Fatalf("user creating an account: code = %v, want PermissionDenied", code)
+	}
+}
+
+func TestUserWritesOwnAccountOnly(t *testing.T) {
+	setupDB(t, 3)
+	client := newUserClient(t, 2, models.RoleUser)
+	name := "Renamed"
+
+	user, err := client.UpdateUser(context.Background(), &usersv2.UpdateUserRequest{UserId: 2, FirstName: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.FirstName != name || user.LastName != "user2" {
+		t.Fatalf("user = %v", user)
+	}
+
+	_, err = client.UpdateUser(context.Background(), &usersv2.UpdateUserRequest{UserId: 3, FirstName: &name})
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Fatalf("updating another user: code = %v, want PermissionDenied", code)
+	}
+	_, err = client.Follow(context.Background(), &usersv2.FollowRequest{FollowerId: 3, FollowedId: 2})
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Fatalf("following on behalf of another user: code = %v, want PermissionDenied", code)
+	}
+
+	unfollowed, err := client.Unfollow(context.Background(), &usersv2.UnfollowRequest{FollowerId: 2, FollowedId: 1})
+	if err != nil || !unfollowed.Removed {
+		t.Fatalf("Unfollow: %v %v", unfollowed, err)
+	}
+
+	// Admins may write any account
+	admin := newUserClient(t, 1, models.RoleAdmin)
+	if _, err := admin.DeleteUser(context.Background(), &usersv2.DeleteUserRequest{UserId: 3}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = admin.DeleteUser(context.Background(), &usersv2.DeleteUserRequest{UserId: 3})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Fatalf("deleting a deleted user: code = %v, want NotFound", code)
+	}
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	setupDB(t, 2)
+	_, client := newClients(t)
+
+	// A retried follow returns the first response, even though the edge
+	// now exists
+	testDB.Where("follower_id = ?", 2).Delete(&models.Follower{})
+	first, err := client.Follow(withIdempotencyKey("follow-1"), &usersv2.FollowRequest{FollowerId: 2, FollowedId: 1})
+	if err != nil || !first.Created {
+		t.Fatalf("Follow: %v %v", first, err)
+	}
+	retry, err := client.Follow(withIdempotencyKey("follow-1"), &usersv2.FollowRequest{FollowerId: 2, FollowedId: 1})
+	if err != nil || !retry.Created || retry.Follow.FollowTime.AsTime() != first.Follow.FollowTime.AsTime() {
+		t.Fatalf("retried Follow: %v %v", retry, err)
+	}
+	again, err := client.Follow(withIdempotencyKey("follow-2"), &usersv2.FollowRequest{FollowerId: 2, FollowedId: 1})
+	if err != nil || again.Created {
+		t.Fatalf("Follow with a new key: %v %v", again, err)
+	}
+
+	// Reusing a key for another request is an error
+	_, err = client.Follow(withIdempotencyKey("follow-1"), &usersv2.FollowRequest{FollowerId: 1, FollowedId: 2})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Fatalf("reused key: code = %v, want InvalidArgument", code)
+	}
+
+	// Failed calls are not remembered
+	req := &usersv2.CreateUserRequest{FirstName: "Ada", LastName: "L", Email: "user1@example.com", Username: "ada", Password: "secret123"}
+	_, err = client.CreateUser(withIdempotencyKey("create-1"), req)
+	if code := status.Code(err); code != codes.AlreadyExists {
+		t.Fatalf("CreateUser: code = %v, want AlreadyExists", code)
+	}
+	testDB.Unscoped().Delete(&models.User{}, 1)
+	if _, err := client.CreateUser(withIdempotencyKey("create-1"), req); err != nil {
+		t.Fatalf("CreateUser retried after a failure: %v", err)
+	}
+
+	var keys int64
+	testDB.Model(&models.IdempotencyKey{}).Count(&keys)
+	if keys != 3 {
+		t.Fatalf("stored keys = %d, want 3", keys)
+	}
+}
+
+func TestIdempotencyKeyLease(t *testing.T) {
+	setupDB(t, 2)
+	_, client := newClients(t)
+	testDB.Where("follower_id = ?", 2).Delete(&models.Follower{})
+
+	// A call that claimed the key and never finished, as if the process
+	// died during it
+	req := &usersv2.FollowRequest{FollowerId: 2, FollowedId: 1}
+	hash, err := requestHash(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stuck, claimed, err := testServices.Idempotency.Claim(context.Background(), "service:tests", "stuck", usersv2.UsersService_Follow_FullMethodName, hash, time.Hour, time.Minute)
+	if err != nil || !claimed {
+		t.Fatalf("Claim = %v, %v", claimed, err)
+	}
+
+	_, err = client.Follow(withIdempotencyKey("stuck"), req)
+	if code := status.Code(err); code != codes.Aborted {
+		t.Fatalf("Follow during the lease: code = %v, want Aborted", code)
+	}
+
+	// Once the lease is over, a retry of the same request takes the key over
+	testDB.Model(&models.IdempotencyKey{}).Where("id = ?", stuck.ID).Update("claimed_at", time.Now().Add(-2*time.Minute))
+	_, err = client.Follow(withIdempotencyKey("stuck"), &usersv2.FollowRequest{FollowerId: 1, FollowedId: 2})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Fatalf("another request after the lease: code = %v, want InvalidArgument", code)
+	}
+	first, err := client.Follow(withIdempotencyKey("stuck"), req)
+	if err != nil || !first.Created {
+		t.Fatalf("Follow after the lease: %v %v", first, err)
+	}
+	retry, err := client.Follow(withIdempotencyKey("stuck"), req)
+	if err != nil || !retry.Created {
+		t.Fatalf("retried Follow: %v %v", retry, err)
+	}
+}
+
+// releasingKeys releases every key right before it is read, as a failed
+// call holding it would
+type releasingKeys struct {
+	repository.IdempotencyKeyRepository
+}
+
+func (k releasingKeys) Get(ctx context.Context, scope, key string) (*models.IdempotencyKey, error) {
+	if record, err := k.IdempotencyKeyRepository.Get(ctx, scope, key); err == nil {
+		k.Delete(ctx, record.ID)
+	}
+	return k.IdempotencyKeyRepository.Get(ctx, scope, key)
+}
+
+type releasingStore struct {
+	repository.Store
+}
+
+func (s releasingStore) IdempotencyKeys() repository.IdempotencyKeyRepository {
+	return releasingKeys{s.Store.IdempotencyKeys()}
+}
+
+func TestIdempotencyKeyReleasedDuringClaim(t *testing.T) {
+	store := repository.NewMemoryStore()
+	keys := services.New(releasingStore{store}, services.Config{}).Idempotency
+	ctx := context.Background()
+
+	if _, claimed, err := keys.Claim(ctx, "service:tests", "k", "/m", []byte{1}, time.Hour, time.Minute); err != nil || !claimed {
+		t.Fatalf("first Claim = %v, %v", claimed, err)
+	}
+	// The key is taken, but released before Claim reads it back
+	if _, claimed, err := keys.Claim(ctx, "service:tests", "k", "/m", []byte{1}, time.Hour, time.Minute); err != nil || !claimed {
+		t.Fatalf("Claim of a key released meanwhile = %v, %v", claimed, err)
+	}
+}
+
+func TestErasureForgetsIdempotentResponses(t *testing.T) {
+	setupDB(t, 3)
+	_, client := newClients(t)
+	name := "Renamed"
+
+	// Responses about user2, and a key of user2's own
+	if _, err := client.Follow(withIdempotencyKey("follow"), &usersv2.FollowRequest{FollowerId: 3, FollowedId: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.UpdateUser(withIdempotencyKey("update"), &usersv2.UpdateUserRequest{UserId: 2, FirstName: &name}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newUserClient(t, 2, models.RoleUser).Unfollow(withIdempotencyKey("own"), &usersv2.UnfollowRequest{FollowerId: 2, FollowedId: 1}); err != nil {
+		t.Fatal(err)
+	}
+	// and one about user3
+	if _, err := client.UpdateUser(withIdempotencyKey("other"), &usersv2.UpdateUserRequest{UserId: 3, FirstName: &name}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := testServices.Admin.EraseUser(services.AsService(context.Background(), "tests"), 2); err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []models.IdempotencyKey
+	testDB.Find(&keys)
+	if len(keys) != 1 || keys[0].Key != "other" {
+		t.Fatalf("keys after erasing user2 = %+v", keys)
+	}
+	var events int64
+	testDB.Model(&models.FollowEvent{}).Where("follower_id = ? OR followed_id = ?", 2, 2).Count(&events)
+	if events != 0 {
+		t.Fatalf("follow events naming user2 = %d", events)
+	}
+
+	// A retry runs the call again instead of replaying the old response
+	user, err := client.UpdateUser(withIdempotencyKey("update"), &usersv2.UpdateUserRequest{UserId: 2, FirstName: &name})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Fatalf("retried update of an erased user: %v, %v", user, err)
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminEraseUser godoc
+// @Summary Erase user (admin)
+// @Description Erase the personal data of any account for good, deleted or not (right to erasure). The ID stays as a tombstone and the follow relationships are removed.
+// @Tags admin
+// @Produce json
+// @Security CookieAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} models.ErasureReceipt
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/admin/users/{id}/erase [post]
+func (h *Handler) AdminEraseUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	receipt, err := h.admin.EraseUser(c.Request.Context(), uint(id))
+	if err != nil {
+		respondError(c, err, "Failed to erase user")
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+}
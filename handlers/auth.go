@@ -3,7 +3,6 @@ package handlers
 import (
 	"net/http"
 
-	"github.com/antoniocfetngnu/users-api/database"
 	"github.com/antoniocfetngnu/users-api/models"
 	"github.com/antoniocfetngnu/users-api/utils"
 	"github.com/gin-gonic/gin"
@@ -20,7 +19,7 @@ import (
 // @Failure 400 {object} map[string]string
 // @Failure 409 {object} map[string]string
 // @Router /api/auth/register [post]
-func Register(c *gin.Context) {
+func (h *Handler) Register(c *gin.Context) {
 	var req models.RegisterRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -28,31 +27,9 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	// Check if user already exists
-	var existingUser models.User
-	if err := database.DB.Where("username = ? OR email = ?", req.Username, req.Email).First(&existingUser).Error; err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Username or email already exists"})
-		return
-	}
-
-	// Hash password
-	hashedPassword, err := utils.HashPassword(req.Password)
+	user, err := h.users.Register(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
-		return
-	}
-
-	// Create user
-	user := models.User{
-		FirstName: req.FirstName,
-		LastName:  req.LastName,
-		Email:     req.Email,
-		Username:  req.Username,
-		Password:  hashedPassword,
-	}
-
-	if err := database.DB.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		respondError(c, err, "Failed to create user")
 		return
 	}
 
@@ -72,8 +49,9 @@ func Register(c *gin.Context) {
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Router /api/auth/login [post]
-func Login(c *gin.Context) {
+func (h *Handler) Login(c *gin.Context) {
 	var req models.LoginRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -81,39 +59,15 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	// Find user
-	var user models.User
-	if err := database.DB.Where("username = ?", req.Username).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
-		return
-	}
-
-	// Verify password
-	if err := utils.CheckPassword(user.Password, req.Password); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
-		return
-	}
-
-	// Generate JWT
-	token, err := utils.GenerateJWT(user.ID, user.Username, user.Email)
+	// Verify credentials and generate JWT
+	user, token, err := h.users.Login(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		respondError(c, err, "Failed to generate token")
 		return
 	}
 
 	// Set HTTP-only cookie
-	c.SetCookie(
-		"auth_token", // name
-		token,        // value
-		86400,        // maxAge (24 hours in seconds)
-		"/",          // path
-		"",           // domain
-		false,        // secure (true in production with HTTPS)
-		true,         // httpOnly
-	)
-
-	// Set SameSite attribute
-	c.SetSameSite(http.SameSiteLaxMode)
+	utils.SetAuthCookie(c, token)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Login successful",
@@ -128,16 +82,8 @@ func Login(c *gin.Context) {
 // @Produce json
 // @Success 200 {object} map[string]string
 // @Router /api/auth/logout [post]
-func Logout(c *gin.Context) {
-	c.SetCookie(
-		"auth_token",
-		"",
-		-1, // maxAge -1 deletes the cookie
-		"/",
-		"",
-		false,
-		true,
-	)
+func (h *Handler) Logout(c *gin.Context) {
+	utils.ClearAuthCookie(c)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Logout successful"})
 }
@@ -152,7 +98,7 @@ func Logout(c *gin.Context) {
 // @Failure 401 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /api/auth/me [get]
-func Me(c *gin.Context) {
+func (h *Handler) Me(c *gin.Context) {
 	// Get user ID from context (set by AuthMiddleware)
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -161,9 +107,9 @@ func Me(c *gin.Context) {
 	}
 
 	// Fetch user from database
-	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	user, err := h.users.GetUser(c.Request.Context(), userID.(uint))
+	if err != nil {
+		respondError(c, err, "Failed to fetch user")
 		return
 	}
 
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/antoniocfetngnu/users-api/middleware"
+	"github.com/antoniocfetngnu/users-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// statusByKind maps domain error kinds to HTTP status codes
+var statusByKind = map[services.Kind]int{
+	services.KindInvalid:         http.StatusBadRequest,
+	services.KindUnauthenticated: http.StatusUnauthorized,
+	services.KindForbidden:       http.StatusForbidden,
+	services.KindNotFound:        http.StatusNotFound,
+	services.KindConflict:        http.StatusConflict,
+}
+
+// respondError writes a domain error with its status code, or a 500 with
+// internalMessage for anything that is not a domain error (the details are
+// only logged, with the request ID)
+func respondError(c *gin.Context, err error, internalMessage string) {
+	if status, ok := statusByKind[services.KindOf(err)]; ok {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("[%s] %s: %v", middleware.RequestIDFromContext(c.Request.Context()), internalMessage, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": internalMessage})
+}
@@ -3,27 +3,26 @@ package handlers
 import (
 	"net/http"
 	"strconv"
-	"time"
 
-	"github.com/antoniocfetngnu/users-api/database"
 	"github.com/antoniocfetngnu/users-api/models"
 	"github.com/gin-gonic/gin"
 )
 
 // FollowUser godoc
 // @Summary Follow a user
-// @Description Current user follows another user
+// @Description Current user follows another user. Following a user that is already followed is a no-op and returns the existing relationship.
 // @Tags followers
 // @Accept json
 // @Produce json
 // @Security CookieAuth
 // @Param followRequest body models.FollowRequest true "User to follow"
+// @Success 200 {object} models.FollowerResponse
 // @Success 201 {object} models.FollowerResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
-// @Failure 409 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Router /api/followers/follow [post]
-func FollowUser(c *gin.Context) {
+func (h *Handler) FollowUser(c *gin.Context) {
 	// Get current user from context (set by auth middleware)
 	followerID, exists := c.Get("userID")
 	if !exists {
@@ -37,42 +36,20 @@ func FollowUser(c *gin.Context) {
 		return
 	}
 
-	// Can't follow yourself
-	if followerID.(uint) == req.FollowedID {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot follow yourself"})
-		return
-	}
-
-	// Check if user to follow exists
-	var followedUser models.User
-	if err := database.DB.First(&followedUser, req.FollowedID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User to follow not found"})
+	follower, created, err := h.follows.Follow(c.Request.Context(), followerID.(uint), req.FollowedID)
+	if err != nil {
+		respondError(c, err, "Failed to follow user")
 		return
 	}
 
-	// Check if already following
-	var existingFollow models.Follower
-	err := database.DB.Where("follower_id = ? AND followed_id = ?", followerID, req.FollowedID).First(&existingFollow).Error
-	if err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Already following this user"})
+	if !created {
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "Already following this user",
+			"follower": follower.ToResponse(),
+		})
 		return
 	}
 
-	// Create follow relationship
-	follower := models.Follower{
-		FollowerID:    followerID.(uint),
-		FollowedID:    req.FollowedID,
-		FollowedSince: time.Now(),
-	}
-
-	if err := database.DB.Create(&follower).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to follow user"})
-		return
-	}
-
-	// Load relations for response
-	database.DB.Preload("Follower").Preload("Followed").First(&follower, follower.ID)
-
 	c.JSON(http.StatusCreated, gin.H{
 		"message":  "Successfully followed user",
 		"follower": follower.ToResponse(),
@@ -81,7 +58,7 @@ func FollowUser(c *gin.Context) {
 
 // UnfollowUser godoc
 // @Summary Unfollow a user
-// @Description Current user unfollows another user
+// @Description Current user unfollows another user. Unfollowing a user that is not followed is a no-op.
 // @Tags followers
 // @Produce json
 // @Security CookieAuth
@@ -89,9 +66,8 @@ func FollowUser(c *gin.Context) {
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
 // @Router /api/followers/unfollow/{id} [delete]
-func UnfollowUser(c *gin.Context) {
+func (h *Handler) UnfollowUser(c *gin.Context) {
 	// Get current user from context
 	followerID, exists := c.Get("userID")
 	if !exists {
@@ -105,17 +81,14 @@ func UnfollowUser(c *gin.Context) {
 		return
 	}
 
-	// Find follow relationship
-	var follower models.Follower
-	err = database.DB.Where("follower_id = ? AND followed_id = ?", followerID, followedID).First(&follower).Error
+	removed, err := h.follows.Unfollow(c.Request.Context(), followerID.(uint), uint(followedID))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Not following this user"})
+		respondError(c, err, "Failed to unfollow user")
 		return
 	}
 
-	// Delete relationship
-	if err := database.DB.Delete(&follower).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unfollow user"})
+	if !removed {
+		c.JSON(http.StatusOK, gin.H{"message": "Not following this user"})
 		return
 	}
 
@@ -131,19 +104,18 @@ func UnfollowUser(c *gin.Context) {
 // @Success 200 {array} models.FollowerResponse
 // @Failure 401 {object} map[string]string
 // @Router /api/followers/my-followers [get]
-func GetMyFollowers(c *gin.Context) {
+func (h *Handler) GetMyFollowers(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	var followers []models.Follower
-	if err := database.DB.
-		Preload("Follower").
-		Preload("Followed").
-		Where("followed_id = ?", userID).
-		Find(&followers).Error; err != nil {
+	followers, err := h.follows.ListFollowers(c.Request.Context(), userID.(uint))
+	if err == nil {
+		err = h.follows.AttachUsers(c.Request.Context(), followers)
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch followers"})
 		return
 	}
@@ -165,19 +137,18 @@ func GetMyFollowers(c *gin.Context) {
 // @Success 200 {array} models.FollowerResponse
 // @Failure 401 {object} map[string]string
 // @Router /api/followers/my-following [get]
-func GetMyFollowing(c *gin.Context) {
+func (h *Handler) GetMyFollowing(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	var following []models.Follower
-	if err := database.DB.
-		Preload("Follower").
-		Preload("Followed").
-		Where("follower_id = ?", userID).
-		Find(&following).Error; err != nil {
+	following, err := h.follows.ListFollowing(c.Request.Context(), userID.(uint))
+	if err == nil {
+		err = h.follows.AttachUsers(c.Request.Context(), following)
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch following"})
 		return
 	}
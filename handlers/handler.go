@@ -0,0 +1,16 @@
+package handlers
+
+import "github.com/antoniocfetngnu/users-api/services"
+
+// Handler serves the REST routes. Handlers only bind requests and map
+// responses, the rules live in the services.
+type Handler struct {
+	users   services.UserService
+	follows services.FollowService
+	admin   services.AdminService
+}
+
+// New returns the REST handlers over svc
+func New(svc *services.Services) *Handler {
+	return &Handler{users: svc.Users, follows: svc.Follows, admin: svc.Admin}
+}
@@ -4,40 +4,74 @@ import (
 	"net/http"
 	"strconv"
 
-	"github.com/antoniocfetngnu/users-api/database"
 	"github.com/antoniocfetngnu/users-api/models"
-	"github.com/antoniocfetngnu/users-api/utils"
+	"github.com/antoniocfetngnu/users-api/services"
 	"github.com/gin-gonic/gin"
 )
 
 // GetUsers godoc
 // @Summary Get all users
-// @Description Retrieve a list of all users (requires authentication)
+// @Description Retrieve every user ordered by ID, or a page of them with limit or after (requires authentication). Emails are only included for the current user (all of them for admins).
 // @Tags users
 // @Produce json
 // @Security CookieAuth
+// @Param limit query int false "Page size (max 100; 20 when only after is given)"
+// @Param after query int false "Only users with a greater ID (the last ID of the previous page)"
 // @Success 200 {array} models.UserResponse
+// @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Router /api/users [get]
-func GetUsers(c *gin.Context) {
-	var users []models.User
+func (h *Handler) GetUsers(c *gin.Context) {
+	// Without paging parameters the list stays whole, as it always was
+	if c.Query("limit") == "" && c.Query("after") == "" {
+		users, err := h.users.ListAllUsers(c.Request.Context())
+		if err != nil {
+			respondError(c, err, "Failed to fetch users")
+			return
+		}
+		c.JSON(http.StatusOK, userResponses(users))
+		return
+	}
 
-	if err := database.DB.Find(&users).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+	var args services.PageArgs
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		args.First = &n
+	}
+	if after := c.Query("after"); after != "" {
+		id, err := strconv.ParseUint(after, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid after"})
+			return
+		}
+		afterID := uint(id)
+		args.After = &afterID
+	}
+
+	page, err := h.users.ListUsers(c.Request.Context(), args)
+	if err != nil {
+		respondError(c, err, "Failed to fetch users")
 		return
 	}
 
+	c.JSON(http.StatusOK, userResponses(page.Items))
+}
+
+func userResponses(users []*models.User) []models.UserResponse {
 	responses := make([]models.UserResponse, len(users))
 	for i, user := range users {
 		responses[i] = user.ToResponse()
 	}
-
-	c.JSON(http.StatusOK, responses)
+	return responses
 }
 
 // GetUser godoc
 // @Summary Get user by ID
-// @Description Retrieve a specific user by ID (requires authentication)
+// @Description Retrieve a specific user by ID (requires authentication). The email is only included for the current user (and admins).
 // @Tags users
 // @Produce json
 // @Security CookieAuth
@@ -46,16 +80,16 @@ func GetUsers(c *gin.Context) {
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /api/users/{id} [get]
-func GetUser(c *gin.Context) {
+func (h *Handler) GetUser(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
 	}
 
-	var user models.User
-	if err := database.DB.First(&user, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	user, err := h.users.GetUser(c.Request.Context(), uint(id))
+	if err != nil {
+		respondError(c, err, "Failed to fetch user")
 		return
 	}
 
@@ -64,7 +98,7 @@ func GetUser(c *gin.Context) {
 
 // UpdateUser godoc
 // @Summary Update user
-// @Description Update user information (requires authentication)
+// @Description Update user information (requires authentication, only your own account unless you are an admin)
 // @Tags users
 // @Accept json
 // @Produce json
@@ -73,86 +107,134 @@ func GetUser(c *gin.Context) {
 // @Param user body models.UpdateUserRequest true "Updated user details"
 // @Success 200 {object} models.UserResponse
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /api/users/{id} [put]
-func UpdateUser(c *gin.Context) {
+func (h *Handler) UpdateUser(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
 	}
 
-	var user models.User
-	if err := database.DB.First(&user, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-		return
-	}
-
 	var req models.UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Update fields if provided
-	if req.FirstName != nil {
-		user.FirstName = *req.FirstName
+	user, err := h.users.UpdateUser(c.Request.Context(), uint(id), req)
+	if err != nil {
+		respondError(c, err, "Failed to update user")
+		return
 	}
-	if req.LastName != nil {
-		user.LastName = *req.LastName
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// DeleteUser godoc
+// @Summary Delete user
+// @Description Soft delete a user (requires authentication, only your own account unless you are an admin)
+// @Tags users
+// @Produce json
+// @Security CookieAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/users/{id} [delete]
+func (h *Handler) DeleteUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
 	}
-	if req.Email != nil {
-		user.Email = *req.Email
+
+	// Soft delete
+	if err := h.users.DeleteUser(c.Request.Context(), uint(id)); err != nil {
+		respondError(c, err, "Failed to delete user")
+		return
 	}
-	if req.Username != nil {
-		user.Username = *req.Username
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+}
+
+// RestoreUser godoc
+// @Summary Restore user
+// @Description Undelete an account within the deletion grace period. Without a session as the account (or an admin), the account's password is required.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body models.RestoreUserRequest false "Password of the account"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/users/{id}/restore [post]
+func (h *Handler) RestoreUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
 	}
-	if req.Password != nil {
-		hashedPassword, err := utils.HashPassword(*req.Password)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+
+	// The body is optional with a session
+	var req models.RestoreUserRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		user.Password = hashedPassword
 	}
 
-	if err := database.DB.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+	user, err := h.users.RestoreAccount(c.Request.Context(), uint(id), req.Password)
+	if err != nil {
+		respondError(c, err, "Failed to restore user")
 		return
 	}
 
 	c.JSON(http.StatusOK, user.ToResponse())
 }
 
-// DeleteUser godoc
-// @Summary Delete user
-// @Description Soft delete a user (requires authentication)
+// EraseUser godoc
+// @Summary Erase user
+// @Description Erase the personal data of your own account for good, deleted or not (right to erasure). The ID stays as a tombstone and the follow relationships are removed. Confirm with the account's password.
 // @Tags users
+// @Accept json
 // @Produce json
 // @Security CookieAuth
 // @Param id path int true "User ID"
-// @Success 200 {object} map[string]string
+// @Param request body models.EraseUserRequest true "Password of the account"
+// @Success 200 {object} models.ErasureReceipt
 // @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
-// @Router /api/users/{id} [delete]
-func DeleteUser(c *gin.Context) {
+// @Failure 409 {object} map[string]string
+// @Router /api/users/{id}/erase [post]
+func (h *Handler) EraseUser(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
 	}
 
-	var user models.User
-	if err := database.DB.First(&user, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	var req models.EraseUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Soft delete
-	if err := database.DB.Delete(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+	receipt, err := h.users.EraseAccount(c.Request.Context(), uint(id), req.Password)
+	if err != nil {
+		respondError(c, err, "Failed to erase user")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+	c.JSON(http.StatusOK, receipt)
 }
@@ -1,14 +1,18 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"google.golang.org/grpc"
 
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -20,7 +24,8 @@ import (
 	grpcServer "github.com/antoniocfetngnu/users-api/grpc"
 	"github.com/antoniocfetngnu/users-api/handlers"
 	"github.com/antoniocfetngnu/users-api/middleware"
-	pb "github.com/antoniocfetngnu/users-api/proto"
+	"github.com/antoniocfetngnu/users-api/repository"
+	"github.com/antoniocfetngnu/users-api/services"
 	"github.com/antoniocfetngnu/users-api/utils"
 )
 
@@ -34,27 +39,133 @@ import (
 // @name auth_token
 func main() {
 	cfg := config.LoadConfig()
+
+	// Commands such as `users-api migrate up` run instead of the server
+	if len(os.Args) > 1 {
+		if err := runCommand(cfg, os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	utils.InitJWT(cfg)
 
 	if err := database.Connect(cfg); err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
+	svc := newServices(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	// Start gRPC server in a separate goroutine
-	go startGRPCServer()
+	grpcSrv := startGRPCServer(ctx, cfg, svc)
+
+	// Purge accounts whose deletion grace period is over and forget
+	// expired idempotency keys
+	if cfg.PurgeInterval > 0 {
+		go housekeeping(ctx, cfg, svc)
+	}
 
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// The gateway runs its own in-memory gRPC server, stopped on shutdown
+	gateway, err := grpcServer.NewGateway(ctx, cfg, svc)
+	if err != nil {
+		log.Fatal("Failed to set up the gRPC gateway:", err)
+	}
+	defer gateway.Close()
+
+	r, err := newRouter(cfg, svc, gateway)
+	if err != nil {
+		log.Fatal("Failed to set up GraphQL:", err)
+	}
+
+	log.Printf("🚀 Server running on http://localhost:%s", cfg.Port)
+	log.Printf("📊 Health check: http://localhost:%s/health", cfg.Port)
+	log.Printf("📚 Swagger UI: http://localhost:%s/swagger/index.html", cfg.Port)
+	if playgroundEnabled(cfg) {
+		log.Printf("🎮 GraphQL Playground: http://localhost:%s/playground", cfg.Port)
+	}
+
+	srv := &http.Server{Addr: ":" + cfg.Port, Handler: r}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to serve HTTP: %v", err)
+		}
+	}()
+
+	// Drain in-flight requests on SIGTERM (and Ctrl+C)
+	<-ctx.Done()
+	stop()
+	log.Println("🛑 Shutting down...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	httpDone := make(chan struct{})
+	go func() {
+		defer close(httpDone)
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("HTTP shutdown: %v", err)
+		}
+	}()
+	grpcSrv.GracefulStop(cfg.ShutdownTimeout)
+	<-httpDone
+}
+
+// newServices returns the services over the database
+func newServices(cfg *config.Config) *services.Services {
+	return services.New(repository.NewGormStore(database.DB), services.Config{
+		DeletionGracePeriod: cfg.DeletionGracePeriod,
+	})
+}
+
+// housekeeping runs Admin.PurgeDeletedUsers and Idempotency.DeleteExpired
+// every cfg.PurgeInterval until ctx is done
+func housekeeping(ctx context.Context, cfg *config.Config, svc *services.Services) {
+	ctx = services.AsService(ctx, "purger")
+	ticker := time.NewTicker(cfg.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := svc.Admin.PurgeDeletedUsers(ctx)
+		if err != nil {
+			log.Printf("Purging deleted users: %v", err)
+		} else if purged > 0 {
+			log.Printf("🧹 Purged %d deleted users", purged)
+		}
+
+		expired, err := svc.Idempotency.DeleteExpired(ctx, cfg.GRPCIdempotencyTTL)
+		if err != nil {
+			log.Printf("Deleting expired idempotency keys: %v", err)
+		} else if expired > 0 {
+			log.Printf("🧹 Deleted %d expired idempotency keys", expired)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// newRouter wires the REST, GraphQL, gateway and Swagger routes over the
+// services
+func newRouter(cfg *config.Config, svc *services.Services, gateway http.Handler) (*gin.Engine, error) {
+	h := handlers.New(svc)
+
 	r := gin.Default()
+	r.Use(middleware.RequestID())
 
 	// CORS configuration
 	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:5173", "http://localhost:8000"},
+		AllowOrigins:     cfg.AllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "HEAD"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", middleware.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", middleware.RequestIDHeader},
 		AllowCredentials: true,
 	}))
 
@@ -70,80 +181,105 @@ func main() {
 	})
 
 	// Public auth routes
-	r.POST("/api/auth/register", handlers.Register)
-	r.POST("/api/auth/login", handlers.Login)
-	r.POST("/api/auth/logout", handlers.Logout)
+	r.POST("/api/auth/register", h.Register)
+	r.POST("/api/auth/login", h.Login)
+	r.POST("/api/auth/logout", h.Logout)
 
 	// Protected auth routes
 	authProtected := r.Group("/api/auth")
 	authProtected.Use(middleware.AuthMiddleware())
 	{
-		authProtected.GET("/me", handlers.Me)
+		authProtected.GET("/me", h.Me)
 	}
 
 	// Protected user routes
 	authorized := r.Group("/api/users")
 	authorized.Use(middleware.AuthMiddleware())
 	{
-		authorized.GET("", handlers.GetUsers)
-		authorized.GET("/:id", handlers.GetUser)
-		authorized.PUT("/:id", handlers.UpdateUser)
-		authorized.DELETE("/:id", handlers.DeleteUser)
+		authorized.GET("", h.GetUsers)
+		authorized.GET("/:id", h.GetUser)
+		authorized.PUT("/:id", h.UpdateUser)
+		authorized.DELETE("/:id", h.DeleteUser)
+		authorized.POST("/:id/erase", h.EraseUser)
+	}
+
+	// Deleted accounts have no session to restore them with, the owner can
+	// give the password instead. Kong lets it through unauthenticated, so
+	// the token is verified here.
+	r.POST("/api/users/:id/restore", middleware.VerifiedOptionalAuthMiddleware(), h.RestoreUser)
+
+	// Admin routes (the services check the role)
+	admin := r.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware())
+	{
+		admin.POST("/users/:id/erase", h.AdminEraseUser)
 	}
 
 	// Follower routes (protected)
 	followers := r.Group("/api/followers")
 	followers.Use(middleware.AuthMiddleware())
 	{
-		followers.POST("/follow", handlers.FollowUser)
-		followers.DELETE("/unfollow/:id", handlers.UnfollowUser)
-		followers.GET("/my-followers", handlers.GetMyFollowers)
-		followers.GET("/my-following", handlers.GetMyFollowing)
+		followers.POST("/follow", h.FollowUser)
+		followers.DELETE("/unfollow/:id", h.UnfollowUser)
+		followers.GET("/my-followers", h.GetMyFollowers)
+		followers.GET("/my-following", h.GetMyFollowing)
 	}
 
 	// GraphQL setup
-	gqlResolver := &graphql.Resolver{}
-	gqlServer := handler.NewDefaultServer(
-		graphql.NewExecutableSchema(graphql.Config{Resolvers: gqlResolver}),
-	)
+	gqlServer, err := graphql.NewServer(cfg, svc)
+	if err != nil {
+		return nil, err
+	}
 
-	// GraphQL endpoint (protected)
-	r.POST("/graphql", middleware.AuthMiddleware(), func(c *gin.Context) {
+	// GraphQL endpoint (register/login/logout are public, everything else
+	// is rejected by graphql.RequireAuth without a valid auth_token). Kong
+	// lets it through unauthenticated, so the token is verified here.
+	// GET also serves the WebSocket upgrade for subscriptions.
+	r.Match([]string{"GET", "POST"}, "/graphql", middleware.VerifiedOptionalAuthMiddleware(), middleware.GinContextToContext(), func(c *gin.Context) {
 		gqlServer.ServeHTTP(c.Writer, c.Request)
 	})
 
-	// GraphQL playground (for development)
-	if cfg.Environment == "development" {
+	// GraphQL playground
+	if playgroundEnabled(cfg) {
 		r.GET("/playground", func(c *gin.Context) {
 			playground.Handler("GraphQL Playground", "/graphql").ServeHTTP(c.Writer, c.Request)
 		})
 	}
 
+	// JSON gateway to the gRPC UsersService for internal callers, with the
+	// same authentication and ACL as gRPC
+	r.Any("/internal/v1/*path", gin.WrapH(gateway))
+
 	// Swagger documentation
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	log.Printf("🚀 Server running on http://localhost:%s", cfg.Port)
-	log.Printf("📊 Health check: http://localhost:%s/health", cfg.Port)
-	log.Printf("📚 Swagger UI: http://localhost:%s/swagger/index.html", cfg.Port)
-	if cfg.Environment == "development" {
-		log.Printf("🎮 GraphQL Playground: http://localhost:%s/playground", cfg.Port)
-	}
+	return r, nil
+}
 
-	r.Run(":" + cfg.Port)
+// playgroundEnabled reports whether the GraphQL playground is served. It
+// is for development, and can't run ad-hoc queries in allowlist mode.
+func playgroundEnabled(cfg *config.Config) bool {
+	return cfg.Environment == "development" && !cfg.GraphQLAllowlistOnly()
 }
 
-func startGRPCServer() {
-	lis, err := net.Listen("tcp", ":50051")
+func startGRPCServer(ctx context.Context, cfg *config.Config, svc *services.Services) *grpcServer.Server {
+	lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
 	if err != nil {
-		log.Fatalf("Failed to listen on port 50051: %v", err)
+		log.Fatalf("Failed to listen on port %s: %v", cfg.GRPCPort, err)
 	}
 
-	grpcSrv := grpc.NewServer()
-	pb.RegisterUsersServiceServer(grpcSrv, &grpcServer.UsersServer{})
+	grpcSrv, err := grpcServer.NewServer(cfg, svc)
+	if err != nil {
+		log.Fatalf("Failed to create gRPC server: %v", err)
+	}
+	go grpcSrv.MonitorHealth(ctx, 10*time.Second, database.Ping)
 
-	log.Println("🔌 gRPC server running on port 50051")
+	log.Printf("🔌 gRPC server running on port %s", cfg.GRPCPort)
 
-	if err := grpcSrv.Serve(lis); err != nil {
-		log.Fatalf("Failed to serve gRPC: %v", err)
-	}
+	go func() {
+		if err := grpcSrv.Serve(lis); err != nil {
+			log.Fatalf("Failed to serve gRPC: %v", err)
+		}
+	}()
+	return grpcSrv
 }
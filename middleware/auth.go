@@ -1,8 +1,9 @@
 package middleware
 
 import (
-	"github.com/antoniocfetngnu/users-api/utils"
 	"github.com/gin-gonic/gin"
+
+	"github.com/antoniocfetngnu/users-api/utils"
 )
 
 // AuthMiddleware extracts user info from JWT (Kong already validated it)
@@ -17,7 +18,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		// Extract user info from JWT payload (no signature verification needed)
-		userID, username, email, err := utils.DecodeJWTPayload(cookie)
+		principal, err := PrincipalFromToken(cookie)
 		if err != nil {
 			c.JSON(401, gin.H{"error": "Invalid token format"})
 			c.Abort()
@@ -25,25 +26,22 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		// Store user info in context
-		c.Set("userID", userID)
-		c.Set("username", username)
-		c.Set("email", email)
+		setPrincipal(c, principal)
 		c.Next()
 	}
 }
 
-// OptionalAuthMiddleware tries to extract user info but doesn't fail if missing
-func OptionalAuthMiddleware() gin.HandlerFunc {
+// VerifiedOptionalAuthMiddleware extracts user info if there is any, for
+// public routes that Kong doesn't authenticate: the token's signature is
+// checked here, and a token that fails the check is treated as no token
+func VerifiedOptionalAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		cookie, err := c.Cookie("auth_token")
-		if err == nil && cookie != "" {
-			userID, username, email, err := utils.DecodeJWTPayload(cookie)
-			if err == nil {
-				c.Set("userID", userID)
-				c.Set("username", username)
-				c.Set("email", email)
+		if err == nil && cookie != "" && utils.VerifyJWT(cookie) == nil {
+			if principal, err := PrincipalFromToken(cookie); err == nil {
+				setPrincipal(c, principal)
 			}
 		}
-		c.Next() // Continue even if not authenticated
+		c.Next()
 	}
 }
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ginContextKey struct{}
+
+// GinContextToContext stores the gin context in the request context so
+// handlers that only receive a context.Context (GraphQL resolvers) can read
+// the auth values and set cookies
+func GinContextToContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), ginContextKey{}, c)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// GinContextFromContext returns the gin context stored by GinContextToContext
+func GinContextFromContext(ctx context.Context) (*gin.Context, error) {
+	c, ok := ctx.Value(ginContextKey{}).(*gin.Context)
+	if !ok {
+		return nil, fmt.Errorf("gin context not found in request context")
+	}
+	return c, nil
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Principal is the authenticated user making the request
+type Principal struct {
+	UserID   uint
+	Username string
+	Email    string
+	Role     string
+}
+
+// IsAdmin reports whether the principal has the admin role
+func (p *Principal) IsAdmin() bool {
+	return p.Role == models.RoleAdmin
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal stored by the auth middlewares
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// PrincipalFromToken extracts the principal from a JWT (Kong already
+// validated it, only the payload is read)
+func PrincipalFromToken(token string) (*Principal, error) {
+	userID, username, email, role, err := utils.DecodeJWTPayload(token)
+	if err != nil {
+		return nil, err
+	}
+	// Tokens issued before roles existed carry none
+	if role == "" {
+		role = models.RoleUser
+	}
+	return &Principal{UserID: userID, Username: username, Email: email, Role: role}, nil
+}
+
+// setPrincipal stores the user info both in the gin context (for REST
+// handlers) and in the request context (for GraphQL resolvers)
+func setPrincipal(c *gin.Context, p *Principal) {
+	c.Set("userID", p.UserID)
+	c.Set("username", p.Username)
+	c.Set("email", p.Email)
+	c.Set("role", p.Role)
+	c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), p))
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Audit actions
+const (
+	AuditUserCreate       = "user.create"
+	AuditUserSetPassword  = "user.set_password"
+	AuditUserSetRole      = "user.set_role"
+	AuditUserDisable      = "user.disable"
+	AuditUserRestore      = "user.restore"
+	AuditUserPurge        = "user.purge"
+	AuditUserErase        = "user.erase"
+	AuditFollowersRebuild = "followers.rebuild_counts"
+)
+
+// AuditEntry is an append-only record of an operator action. Actor is who
+// did it ("service:<name>" or "user:<id>"), UserID the account it was done
+// to (nil for actions on no single account) and Details a JSON object.
+type AuditEntry struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Actor     string    `gorm:"size:255;not null" json:"actor"`
+	Action    string    `gorm:"size:64;not null" json:"action"`
+	UserID    *uint     `gorm:"index" json:"userId,omitempty"`
+	Details   string    `gorm:"not null;default:'{}'" json:"details"`
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+}
+
+// ErasureReceipt proves that the personal data of an account was erased. It
+// is the AuditUserErase entry, which only holds the user ID.
+type ErasureReceipt struct {
+	ID          uint      `json:"id"` // Of the audit entry
+	UserID      uint      `json:"userId"`
+	Actor       string    `json:"actor"`
+	FollowEdges int       `json:"followEdges"` // Removed with the account
+	ErasedAt    time.Time `json:"erasedAt"`
+}
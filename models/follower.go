@@ -2,24 +2,43 @@ package models
 
 import (
 	"time"
-
-	"gorm.io/gorm"
 )
 
+// Follower is a live edge of the follow graph. A pair can only appear once
+// (enforced by idx_followers_pair); unfollowing removes the row and history
+// is kept in FollowEvent instead.
 type Follower struct {
-	ID            uint           `gorm:"primarykey" json:"id"`
-	FollowerID    uint           `gorm:"not null;index:idx_follower_followed" json:"followerId"` // User who follows
-	FollowedID    uint           `gorm:"not null;index:idx_follower_followed" json:"followedId"` // User being followed
-	FollowedSince time.Time      `gorm:"not null" json:"followedSince"`
-	CreatedAt     time.Time      `json:"createdAt"`
-	UpdatedAt     time.Time      `json:"updatedAt"`
-	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+	ID            uint      `gorm:"primarykey" json:"id"`
+	FollowerID    uint      `gorm:"not null;uniqueIndex:idx_followers_pair" json:"followerId"`       // User who follows
+	FollowedID    uint      `gorm:"not null;uniqueIndex:idx_followers_pair;index" json:"followedId"` // User being followed
+	FollowedSince time.Time `gorm:"not null" json:"followedSince"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
 
 	// Relations
 	Follower User `gorm:"foreignKey:FollowerID" json:"follower"`
 	Followed User `gorm:"foreignKey:FollowedID" json:"followed"`
 }
 
+// Follow event actions
+const (
+	FollowActionFollow   = "follow"
+	FollowActionUnfollow = "unfollow"
+)
+
+// ErasedUserID replaces the ID of an erased user in FollowEvent, which
+// keeps the history of the other user without linking it to the erased one
+const ErasedUserID = 0
+
+// FollowEvent is an append-only record of follow/unfollow transitions
+type FollowEvent struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	FollowerID uint      `gorm:"not null;index:idx_follow_events_pair" json:"followerId"`
+	FollowedID uint      `gorm:"not null;index:idx_follow_events_pair" json:"followedId"`
+	Action     string    `gorm:"size:16;not null" json:"action"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
 // FollowRequest DTO
 type FollowRequest struct {
 	FollowedID uint `json:"followedId" binding:"required"`
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// IdempotencyKey records a write made with an idempotency key, so a retry
+// with the same key gets the first response instead of repeating the
+// write. Response is empty while the first call is in progress, which
+// holds the key for a lease from ClaimedAt. UserID is the user the response
+// describes, if any, so erasing them removes it.
+type IdempotencyKey struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	Scope       string    `gorm:"size:255;not null;uniqueIndex:idx_idempotency_keys_scope_key" json:"scope"` // Who made the call
+	Key         string    `gorm:"size:255;not null;uniqueIndex:idx_idempotency_keys_scope_key" json:"key"`
+	Method      string    `gorm:"size:255;not null" json:"method"`
+	RequestHash []byte    `gorm:"not null" json:"-"`
+	Response    []byte    `json:"-"`
+	UserID      *uint     `gorm:"index" json:"userId,omitempty"`
+	ClaimedAt   time.Time `json:"claimedAt"`
+	CreatedAt   time.Time `gorm:"index" json:"createdAt"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -10,14 +11,74 @@ type User struct {
 	ID        uint           `gorm:"primarykey" json:"id"`
 	FirstName string         `gorm:"not null" json:"firstName" binding:"required"`
 	LastName  string         `gorm:"not null" json:"lastName" binding:"required"`
-	Email     string         `gorm:"uniqueIndex;not null" json:"email" binding:"required,email"`
-	Username  string         `gorm:"uniqueIndex;not null" json:"username" binding:"required"`
+	Email     string         `gorm:"uniqueIndex:idx_users_email,where:deleted_at IS NULL;not null" json:"email" binding:"required,email"`
+	Username  string         `gorm:"uniqueIndex:idx_users_username,where:deleted_at IS NULL;not null" json:"username" binding:"required"`
 	Password  string         `gorm:"not null" json:"-"` // Never expose in JSON
+	Role      string         `gorm:"size:16;not null;default:user" json:"role"`
 	CreatedAt time.Time      `json:"createdAt"`
 	UpdatedAt time.Time      `json:"updatedAt"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// DisabledAt is set while an operator has disabled the account, which
+	// can't log in then
+	DisabledAt *time.Time `json:"-"`
+
+	// ErasedAt is set once the personal data of the account was erased,
+	// see Erase
+	ErasedAt *time.Time `json:"-"`
 }
 
+// TombstonePrefix starts the username of every erased account, so no one
+// else may use it
+const TombstonePrefix = "erased-"
+
+// TombstoneUsername is the handle an erased account is left with
+func TombstoneUsername(id uint) string {
+	return fmt.Sprintf("%s%d", TombstonePrefix, id)
+}
+
+// Erase scrubs the personal data of the user for good. The row stays as a
+// deleted tombstone so the ID, which other services hold, is never reused
+// or resolved to someone else.
+func (u *User) Erase(now time.Time) {
+	u.FirstName = ""
+	u.LastName = ""
+	u.Email = ""
+	u.Username = TombstoneUsername(u.ID)
+	u.Password = "" // Matches no password
+	u.DisabledAt = nil
+	u.ErasedAt = &now
+	if !u.DeletedAt.Valid {
+		u.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	}
+}
+
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// User change actions
+const (
+	UserChangeCreated = "created"
+	UserChangeUpdated = "updated"
+	UserChangeDeleted = "deleted"
+)
+
+// UserChange is an append-only log of user creations, updates and
+// deletions. The ID is the sequence gRPC WatchUsers clients resume from.
+type UserChange struct {
+	ID        uint64    `gorm:"primarykey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"userId"`
+	Action    string    `gorm:"size:16;not null" json:"action"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// IsEntity marks User as an Apollo Federation entity for the GraphQL
+// subgraph (gqlgen's fedruntime.Entity)
+func (User) IsEntity() {}
+
 // Request DTOs
 type RegisterRequest struct {
 	FirstName string `json:"firstName" binding:"required"`
@@ -40,12 +101,34 @@ type UpdateUserRequest struct {
 	Password  *string `json:"password"`
 }
 
+// RestoreUserRequest proves ownership of a deleted account without a
+// session
+type RestoreUserRequest struct {
+	Password string `json:"password"`
+}
+
+// EraseUserRequest confirms the erasure of an account with its password
+type EraseUserRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required,min=6"`
+}
+
+// SetPasswordRequest is an operator setting a user's password, with the
+// same rule as RegisterRequest
+type SetPasswordRequest struct {
+	Password string `json:"password" binding:"required,min=6"`
+}
+
 // Response DTOs
 type UserResponse struct {
 	ID        uint      `json:"id"`
 	FirstName string    `json:"firstName"`
 	LastName  string    `json:"lastName"`
-	Email     string    `json:"email"`
+	Email     string    `json:"email,omitempty"` // Only for the user themselves and admins
 	Username  string    `json:"username"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
@@ -7,6 +7,7 @@
 package proto
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -202,10 +203,15 @@ func (x *UserResponse) GetUpdatedAt() string {
 }
 
 type UsersResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Users         []*UserResponse        `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Found users in request order, each requested user at most once
+	Users []*UserResponse `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	// Requested IDs with no (or a deleted) user, set by GetUsers
+	MissingIds []uint32 `protobuf:"varint,2,rep,packed,name=missing_ids,json=missingIds,proto3" json:"missing_ids,omitempty"`
+	// Requested usernames with no (or a deleted) user, set by GetUsersByUsernames
+	MissingUsernames []string `protobuf:"bytes,3,rep,name=missing_usernames,json=missingUsernames,proto3" json:"missing_usernames,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *UsersResponse) Reset() {
@@ -245,11 +251,648 @@ func (x *UsersResponse) GetUsers() []*UserResponse {
 	return nil
 }
 
+func (x *UsersResponse) GetMissingIds() []uint32 {
+	if x != nil {
+		return x.MissingIds
+	}
+	return nil
+}
+
+func (x *UsersResponse) GetMissingUsernames() []string {
+	if x != nil {
+		return x.MissingUsernames
+	}
+	return nil
+}
+
+type GetUserByUsernameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserByUsernameRequest) Reset() {
+	*x = GetUserByUsernameRequest{}
+	mi := &file_proto_users_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserByUsernameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserByUsernameRequest) ProtoMessage() {}
+
+func (x *GetUserByUsernameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_users_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserByUsernameRequest.ProtoReflect.Descriptor instead.
+func (*GetUserByUsernameRequest) Descriptor() ([]byte, []int) {
+	return file_proto_users_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetUserByUsernameRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+type GetUsersByUsernamesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Usernames     []string               `protobuf:"bytes,1,rep,name=usernames,proto3" json:"usernames,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsersByUsernamesRequest) Reset() {
+	*x = GetUsersByUsernamesRequest{}
+	mi := &file_proto_users_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsersByUsernamesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsersByUsernamesRequest) ProtoMessage() {}
+
+func (x *GetUsersByUsernamesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_users_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsersByUsernamesRequest.ProtoReflect.Descriptor instead.
+func (*GetUsersByUsernamesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_users_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetUsersByUsernamesRequest) GetUsernames() []string {
+	if x != nil {
+		return x.Usernames
+	}
+	return nil
+}
+
+type FollowPair struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FollowerId    uint32                 `protobuf:"varint,1,opt,name=follower_id,json=followerId,proto3" json:"follower_id,omitempty"`
+	FollowedId    uint32                 `protobuf:"varint,2,opt,name=followed_id,json=followedId,proto3" json:"followed_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FollowPair) Reset() {
+	*x = FollowPair{}
+	mi := &file_proto_users_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FollowPair) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FollowPair) ProtoMessage() {}
+
+func (x *FollowPair) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_users_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FollowPair.ProtoReflect.Descriptor instead.
+func (*FollowPair) Descriptor() ([]byte, []int) {
+	return file_proto_users_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *FollowPair) GetFollowerId() uint32 {
+	if x != nil {
+		return x.FollowerId
+	}
+	return 0
+}
+
+func (x *FollowPair) GetFollowedId() uint32 {
+	if x != nil {
+		return x.FollowedId
+	}
+	return 0
+}
+
+type CheckFollowsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pairs         []*FollowPair          `protobuf:"bytes,1,rep,name=pairs,proto3" json:"pairs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckFollowsRequest) Reset() {
+	*x = CheckFollowsRequest{}
+	mi := &file_proto_users_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckFollowsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckFollowsRequest) ProtoMessage() {}
+
+func (x *CheckFollowsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_users_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckFollowsRequest.ProtoReflect.Descriptor instead.
+func (*CheckFollowsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_users_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CheckFollowsRequest) GetPairs() []*FollowPair {
+	if x != nil {
+		return x.Pairs
+	}
+	return nil
+}
+
+type FollowCheck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FollowerId    uint32                 `protobuf:"varint,1,opt,name=follower_id,json=followerId,proto3" json:"follower_id,omitempty"`
+	FollowedId    uint32                 `protobuf:"varint,2,opt,name=followed_id,json=followedId,proto3" json:"followed_id,omitempty"`
+	Follows       bool                   `protobuf:"varint,3,opt,name=follows,proto3" json:"follows,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FollowCheck) Reset() {
+	*x = FollowCheck{}
+	mi := &file_proto_users_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FollowCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FollowCheck) ProtoMessage() {}
+
+func (x *FollowCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_users_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FollowCheck.ProtoReflect.Descriptor instead.
+func (*FollowCheck) Descriptor() ([]byte, []int) {
+	return file_proto_users_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *FollowCheck) GetFollowerId() uint32 {
+	if x != nil {
+		return x.FollowerId
+	}
+	return 0
+}
+
+func (x *FollowCheck) GetFollowedId() uint32 {
+	if x != nil {
+		return x.FollowedId
+	}
+	return 0
+}
+
+func (x *FollowCheck) GetFollows() bool {
+	if x != nil {
+		return x.Follows
+	}
+	return false
+}
+
+type CheckFollowsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One result per requested pair, in request order
+	Results       []*FollowCheck `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckFollowsResponse) Reset() {
+	*x = CheckFollowsResponse{}
+	mi := &file_proto_users_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckFollowsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckFollowsResponse) ProtoMessage() {}
+
+func (x *CheckFollowsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_users_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckFollowsResponse.ProtoReflect.Descriptor instead.
+func (*CheckFollowsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_users_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CheckFollowsResponse) GetResults() []*FollowCheck {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type ListFollowsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId uint32                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Defaults to 20, at most 100
+	PageSize int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token of the previous page, empty for the first page
+	PageToken     string `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFollowsRequest) Reset() {
+	*x = ListFollowsRequest{}
+	mi := &file_proto_users_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFollowsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFollowsRequest) ProtoMessage() {}
+
+func (x *ListFollowsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_users_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFollowsRequest.ProtoReflect.Descriptor instead.
+func (*ListFollowsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_users_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListFollowsRequest) GetUserId() uint32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ListFollowsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListFollowsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type Follow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FollowerId    uint32                 `protobuf:"varint,1,opt,name=follower_id,json=followerId,proto3" json:"follower_id,omitempty"`
+	FollowedId    uint32                 `protobuf:"varint,2,opt,name=followed_id,json=followedId,proto3" json:"followed_id,omitempty"`
+	FollowedSince string                 `protobuf:"bytes,3,opt,name=followed_since,json=followedSince,proto3" json:"followed_since,omitempty"`
+	// The other user: the follower for ListFollowers, the followed user for
+	// ListFollowing
+	User          *UserResponse `protobuf:"bytes,4,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Follow) Reset() {
+	*x = Follow{}
+	mi := &file_proto_users_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Follow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Follow) ProtoMessage() {}
+
+func (x *Follow) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_users_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Follow.ProtoReflect.Descriptor instead.
+func (*Follow) Descriptor() ([]byte, []int) {
+	return file_proto_users_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Follow) GetFollowerId() uint32 {
+	if x != nil {
+		return x.FollowerId
+	}
+	return 0
+}
+
+func (x *Follow) GetFollowedId() uint32 {
+	if x != nil {
+		return x.FollowedId
+	}
+	return 0
+}
+
+func (x *Follow) GetFollowedSince() string {
+	if x != nil {
+		return x.FollowedSince
+	}
+	return ""
+}
+
+func (x *Follow) GetUser() *UserResponse {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+type ListFollowsResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Follows []*Follow              `protobuf:"bytes,1,rep,name=follows,proto3" json:"follows,omitempty"`
+	// Empty on the last page
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	TotalCount    int32  `protobuf:"varint,3,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFollowsResponse) Reset() {
+	*x = ListFollowsResponse{}
+	mi := &file_proto_users_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFollowsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFollowsResponse) ProtoMessage() {}
+
+func (x *ListFollowsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_users_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFollowsResponse.ProtoReflect.Descriptor instead.
+func (*ListFollowsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_users_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListFollowsResponse) GetFollows() []*Follow {
+	if x != nil {
+		return x.Follows
+	}
+	return nil
+}
+
+func (x *ListFollowsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListFollowsResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+type GetFollowerIDsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint32                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFollowerIDsRequest) Reset() {
+	*x = GetFollowerIDsRequest{}
+	mi := &file_proto_users_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFollowerIDsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFollowerIDsRequest) ProtoMessage() {}
+
+func (x *GetFollowerIDsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_users_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFollowerIDsRequest.ProtoReflect.Descriptor instead.
+func (*GetFollowerIDsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_users_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetFollowerIDsRequest) GetUserId() uint32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type StreamFollowerIDsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId uint32                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// IDs per message, defaults to 1000
+	ChunkSize     int32 `protobuf:"varint,2,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamFollowerIDsRequest) Reset() {
+	*x = StreamFollowerIDsRequest{}
+	mi := &file_proto_users_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamFollowerIDsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamFollowerIDsRequest) ProtoMessage() {}
+
+func (x *StreamFollowerIDsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_users_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamFollowerIDsRequest.ProtoReflect.Descriptor instead.
+func (*StreamFollowerIDsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_users_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *StreamFollowerIDsRequest) GetUserId() uint32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *StreamFollowerIDsRequest) GetChunkSize() int32 {
+	if x != nil {
+		return x.ChunkSize
+	}
+	return 0
+}
+
+type FollowerIDsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FollowerIds   []uint32               `protobuf:"varint,1,rep,packed,name=follower_ids,json=followerIds,proto3" json:"follower_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FollowerIDsResponse) Reset() {
+	*x = FollowerIDsResponse{}
+	mi := &file_proto_users_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FollowerIDsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FollowerIDsResponse) ProtoMessage() {}
+
+func (x *FollowerIDsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_users_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FollowerIDsResponse.ProtoReflect.Descriptor instead.
+func (*FollowerIDsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_users_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *FollowerIDsResponse) GetFollowerIds() []uint32 {
+	if x != nil {
+		return x.FollowerIds
+	}
+	return nil
+}
+
 var File_proto_users_proto protoreflect.FileDescriptor
 
 const file_proto_users_proto_rawDesc = "" +
 	"\n" +
-	"\x11proto/users.proto\x12\x05users\")\n" +
+	"\x11proto/users.proto\x12\x05users\x1a\x1cgoogle/api/annotations.proto\")\n" +
 	"\x0eGetUserRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\rR\x06userId\",\n" +
 	"\x0fGetUsersRequest\x12\x19\n" +
@@ -264,12 +907,67 @@ const file_proto_users_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\x06 \x01(\tR\tcreatedAt\x12\x1d\n" +
 	"\n" +
-	"updated_at\x18\a \x01(\tR\tupdatedAt\":\n" +
+	"updated_at\x18\a \x01(\tR\tupdatedAt\"\x88\x01\n" +
 	"\rUsersResponse\x12)\n" +
-	"\x05users\x18\x01 \x03(\v2\x13.users.UserResponseR\x05users2\x7f\n" +
-	"\fUsersService\x125\n" +
-	"\aGetUser\x12\x15.users.GetUserRequest\x1a\x13.users.UserResponse\x128\n" +
-	"\bGetUsers\x12\x16.users.GetUsersRequest\x1a\x14.users.UsersResponseB,Z*github.com/antoniocfetngnu/users-api/protob\x06proto3"
+	"\x05users\x18\x01 \x03(\v2\x13.users.UserResponseR\x05users\x12\x1f\n" +
+	"\vmissing_ids\x18\x02 \x03(\rR\n" +
+	"missingIds\x12+\n" +
+	"\x11missing_usernames\x18\x03 \x03(\tR\x10missingUsernames\"6\n" +
+	"\x18GetUserByUsernameRequest\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\":\n" +
+	"\x1aGetUsersByUsernamesRequest\x12\x1c\n" +
+	"\tusernames\x18\x01 \x03(\tR\tusernames\"N\n" +
+	"\n" +
+	"FollowPair\x12\x1f\n" +
+	"\vfollower_id\x18\x01 \x01(\rR\n" +
+	"followerId\x12\x1f\n" +
+	"\vfollowed_id\x18\x02 \x01(\rR\n" +
+	"followedId\">\n" +
+	"\x13CheckFollowsRequest\x12'\n" +
+	"\x05pairs\x18\x01 \x03(\v2\x11.users.FollowPairR\x05pairs\"i\n" +
+	"\vFollowCheck\x12\x1f\n" +
+	"\vfollower_id\x18\x01 \x01(\rR\n" +
+	"followerId\x12\x1f\n" +
+	"\vfollowed_id\x18\x02 \x01(\rR\n" +
+	"followedId\x12\x18\n" +
+	"\afollows\x18\x03 \x01(\bR\afollows\"D\n" +
+	"\x14CheckFollowsResponse\x12,\n" +
+	"\aresults\x18\x01 \x03(\v2\x12.users.FollowCheckR\aresults\"i\n" +
+	"\x12ListFollowsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\rR\x06userId\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"\x9a\x01\n" +
+	"\x06Follow\x12\x1f\n" +
+	"\vfollower_id\x18\x01 \x01(\rR\n" +
+	"followerId\x12\x1f\n" +
+	"\vfollowed_id\x18\x02 \x01(\rR\n" +
+	"followedId\x12%\n" +
+	"\x0efollowed_since\x18\x03 \x01(\tR\rfollowedSince\x12'\n" +
+	"\x04user\x18\x04 \x01(\v2\x13.users.UserResponseR\x04user\"\x87\x01\n" +
+	"\x13ListFollowsResponse\x12'\n" +
+	"\afollows\x18\x01 \x03(\v2\r.users.FollowR\afollows\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x1f\n" +
+	"\vtotal_count\x18\x03 \x01(\x05R\n" +
+	"totalCount\"0\n" +
+	"\x15GetFollowerIDsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\rR\x06userId\"R\n" +
+	"\x18StreamFollowerIDsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\rR\x06userId\x12\x1d\n" +
+	"\n" +
+	"chunk_size\x18\x02 \x01(\x05R\tchunkSize\"8\n" +
+	"\x13FollowerIDsResponse\x12!\n" +
+	"\ffollower_ids\x18\x01 \x03(\rR\vfollowerIds2\xad\b\n" +
+	"\fUsersService\x12[\n" +
+	"\aGetUser\x12\x15.users.GetUserRequest\x1a\x13.users.UserResponse\"$\x82\xd3\xe4\x93\x02\x1e\x12\x1c/internal/v1/users/{user_id}\x12`\n" +
+	"\bGetUsers\x12\x16.users.GetUsersRequest\x1a\x14.users.UsersResponse\"&\x82\xd3\xe4\x93\x02 :\x01*\"\x1b/internal/v1/users:batchGet\x12t\n" +
+	"\x11GetUserByUsername\x12\x1f.users.GetUserByUsernameRequest\x1a\x13.users.UserResponse\")\x82\xd3\xe4\x93\x02#\x12!/internal/v1/usernames/{username}\x12z\n" +
+	"\x13GetUsersByUsernames\x12!.users.GetUsersByUsernamesRequest\x1a\x14.users.UsersResponse\"*\x82\xd3\xe4\x93\x02$:\x01*\"\x1f/internal/v1/usernames:batchGet\x12n\n" +
+	"\fCheckFollows\x12\x1a.users.CheckFollowsRequest\x1a\x1b.users.CheckFollowsResponse\"%\x82\xd3\xe4\x93\x02\x1f:\x01*\"\x1a/internal/v1/follows:check\x12v\n" +
+	"\rListFollowers\x12\x19.users.ListFollowsRequest\x1a\x1a.users.ListFollowsResponse\".\x82\xd3\xe4\x93\x02(\x12&/internal/v1/users/{user_id}/followers\x12v\n" +
+	"\rListFollowing\x12\x19.users.ListFollowsRequest\x1a\x1a.users.ListFollowsResponse\".\x82\xd3\xe4\x93\x02(\x12&/internal/v1/users/{user_id}/following\x12}\n" +
+	"\x0eGetFollowerIDs\x12\x1c.users.GetFollowerIDsRequest\x1a\x1a.users.FollowerIDsResponse\"1\x82\xd3\xe4\x93\x02+\x12)/internal/v1/users/{user_id}/follower-ids\x12\x8c\x01\n" +
+	"\x11StreamFollowerIDs\x12\x1f.users.StreamFollowerIDsRequest\x1a\x1a.users.FollowerIDsResponse\"8\x82\xd3\xe4\x93\x022\x120/internal/v1/users/{user_id}/follower-ids:stream0\x01B,Z*github.com/antoniocfetngnu/users-api/protob\x06proto3"
 
 var (
 	file_proto_users_proto_rawDescOnce sync.Once
@@ -283,24 +981,54 @@ func file_proto_users_proto_rawDescGZIP() []byte {
 	return file_proto_users_proto_rawDescData
 }
 
-var file_proto_users_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_users_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
 var file_proto_users_proto_goTypes = []any{
-	(*GetUserRequest)(nil),  // 0: users.GetUserRequest
-	(*GetUsersRequest)(nil), // 1: users.GetUsersRequest
-	(*UserResponse)(nil),    // 2: users.UserResponse
-	(*UsersResponse)(nil),   // 3: users.UsersResponse
+	(*GetUserRequest)(nil),             // 0: users.GetUserRequest
+	(*GetUsersRequest)(nil),            // 1: users.GetUsersRequest
+	(*UserResponse)(nil),               // 2: users.UserResponse
+	(*UsersResponse)(nil),              // 3: users.UsersResponse
+	(*GetUserByUsernameRequest)(nil),   // 4: users.GetUserByUsernameRequest
+	(*GetUsersByUsernamesRequest)(nil), // 5: users.GetUsersByUsernamesRequest
+	(*FollowPair)(nil),                 // 6: users.FollowPair
+	(*CheckFollowsRequest)(nil),        // 7: users.CheckFollowsRequest
+	(*FollowCheck)(nil),                // 8: users.FollowCheck
+	(*CheckFollowsResponse)(nil),       // 9: users.CheckFollowsResponse
+	(*ListFollowsRequest)(nil),         // 10: users.ListFollowsRequest
+	(*Follow)(nil),                     // 11: users.Follow
+	(*ListFollowsResponse)(nil),        // 12: users.ListFollowsResponse
+	(*GetFollowerIDsRequest)(nil),      // 13: users.GetFollowerIDsRequest
+	(*StreamFollowerIDsRequest)(nil),   // 14: users.StreamFollowerIDsRequest
+	(*FollowerIDsResponse)(nil),        // 15: users.FollowerIDsResponse
 }
 var file_proto_users_proto_depIdxs = []int32{
-	2, // 0: users.UsersResponse.users:type_name -> users.UserResponse
-	0, // 1: users.UsersService.GetUser:input_type -> users.GetUserRequest
-	1, // 2: users.UsersService.GetUsers:input_type -> users.GetUsersRequest
-	2, // 3: users.UsersService.GetUser:output_type -> users.UserResponse
-	3, // 4: users.UsersService.GetUsers:output_type -> users.UsersResponse
-	3, // [3:5] is the sub-list for method output_type
-	1, // [1:3] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	2,  // 0: users.UsersResponse.users:type_name -> users.UserResponse
+	6,  // 1: users.CheckFollowsRequest.pairs:type_name -> users.FollowPair
+	8,  // 2: users.CheckFollowsResponse.results:type_name -> users.FollowCheck
+	2,  // 3: users.Follow.user:type_name -> users.UserResponse
+	11, // 4: users.ListFollowsResponse.follows:type_name -> users.Follow
+	0,  // 5: users.UsersService.GetUser:input_type -> users.GetUserRequest
+	1,  // 6: users.UsersService.GetUsers:input_type -> users.GetUsersRequest
+	4,  // 7: users.UsersService.GetUserByUsername:input_type -> users.GetUserByUsernameRequest
+	5,  // 8: users.UsersService.GetUsersByUsernames:input_type -> users.GetUsersByUsernamesRequest
+	7,  // 9: users.UsersService.CheckFollows:input_type -> users.CheckFollowsRequest
+	10, // 10: users.UsersService.ListFollowers:input_type -> users.ListFollowsRequest
+	10, // 11: users.UsersService.ListFollowing:input_type -> users.ListFollowsRequest
+	13, // 12: users.UsersService.GetFollowerIDs:input_type -> users.GetFollowerIDsRequest
+	14, // 13: users.UsersService.StreamFollowerIDs:input_type -> users.StreamFollowerIDsRequest
+	2,  // 14: users.UsersService.GetUser:output_type -> users.UserResponse
+	3,  // 15: users.UsersService.GetUsers:output_type -> users.UsersResponse
+	2,  // 16: users.UsersService.GetUserByUsername:output_type -> users.UserResponse
+	3,  // 17: users.UsersService.GetUsersByUsernames:output_type -> users.UsersResponse
+	9,  // 18: users.UsersService.CheckFollows:output_type -> users.CheckFollowsResponse
+	12, // 19: users.UsersService.ListFollowers:output_type -> users.ListFollowsResponse
+	12, // 20: users.UsersService.ListFollowing:output_type -> users.ListFollowsResponse
+	15, // 21: users.UsersService.GetFollowerIDs:output_type -> users.FollowerIDsResponse
+	15, // 22: users.UsersService.StreamFollowerIDs:output_type -> users.FollowerIDsResponse
+	14, // [14:23] is the sub-list for method output_type
+	5,  // [5:14] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_proto_users_proto_init() }
@@ -314,7 +1042,7 @@ func file_proto_users_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_users_proto_rawDesc), len(file_proto_users_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   16,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,8 +19,15 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	UsersService_GetUser_FullMethodName  = "/users.UsersService/GetUser"
-	UsersService_GetUsers_FullMethodName = "/users.UsersService/GetUsers"
+	UsersService_GetUser_FullMethodName             = "/users.UsersService/GetUser"
+	UsersService_GetUsers_FullMethodName            = "/users.UsersService/GetUsers"
+	UsersService_GetUserByUsername_FullMethodName   = "/users.UsersService/GetUserByUsername"
+	UsersService_GetUsersByUsernames_FullMethodName = "/users.UsersService/GetUsersByUsernames"
+	UsersService_CheckFollows_FullMethodName        = "/users.UsersService/CheckFollows"
+	UsersService_ListFollowers_FullMethodName       = "/users.UsersService/ListFollowers"
+	UsersService_ListFollowing_FullMethodName       = "/users.UsersService/ListFollowing"
+	UsersService_GetFollowerIDs_FullMethodName      = "/users.UsersService/GetFollowerIDs"
+	UsersService_StreamFollowerIDs_FullMethodName   = "/users.UsersService/StreamFollowerIDs"
 )
 
 // UsersServiceClient is the client API for UsersService service.
@@ -31,6 +38,20 @@ type UsersServiceClient interface {
 	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*UserResponse, error)
 	// Get multiple users by IDs (batch)
 	GetUsers(ctx context.Context, in *GetUsersRequest, opts ...grpc.CallOption) (*UsersResponse, error)
+	// Get single user by username
+	GetUserByUsername(ctx context.Context, in *GetUserByUsernameRequest, opts ...grpc.CallOption) (*UserResponse, error)
+	// Get multiple users by usernames (batch)
+	GetUsersByUsernames(ctx context.Context, in *GetUsersByUsernamesRequest, opts ...grpc.CallOption) (*UsersResponse, error)
+	// Check which follow relationships exist (batch)
+	CheckFollows(ctx context.Context, in *CheckFollowsRequest, opts ...grpc.CallOption) (*CheckFollowsResponse, error)
+	// List the followers of a user, one page at a time
+	ListFollowers(ctx context.Context, in *ListFollowsRequest, opts ...grpc.CallOption) (*ListFollowsResponse, error)
+	// List the users a user follows, one page at a time
+	ListFollowing(ctx context.Context, in *ListFollowsRequest, opts ...grpc.CallOption) (*ListFollowsResponse, error)
+	// Get the IDs of all followers of a user
+	GetFollowerIDs(ctx context.Context, in *GetFollowerIDsRequest, opts ...grpc.CallOption) (*FollowerIDsResponse, error)
+	// Stream the IDs of all followers of a user in chunks (large follower sets)
+	StreamFollowerIDs(ctx context.Context, in *StreamFollowerIDsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FollowerIDsResponse], error)
 }
 
 type usersServiceClient struct {
@@ -61,6 +82,85 @@ func (c *usersServiceClient) GetUsers(ctx context.Context, in *GetUsersRequest,
 	return out, nil
 }
 
+func (c *usersServiceClient) GetUserByUsername(ctx context.Context, in *GetUserByUsernameRequest, opts ...grpc.CallOption) (*UserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UserResponse)
+	err := c.cc.Invoke(ctx, UsersService_GetUserByUsername_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersServiceClient) GetUsersByUsernames(ctx context.Context, in *GetUsersByUsernamesRequest, opts ...grpc.CallOption) (*UsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UsersResponse)
+	err := c.cc.Invoke(ctx, UsersService_GetUsersByUsernames_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersServiceClient) CheckFollows(ctx context.Context, in *CheckFollowsRequest, opts ...grpc.CallOption) (*CheckFollowsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckFollowsResponse)
+	err := c.cc.Invoke(ctx, UsersService_CheckFollows_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersServiceClient) ListFollowers(ctx context.Context, in *ListFollowsRequest, opts ...grpc.CallOption) (*ListFollowsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFollowsResponse)
+	err := c.cc.Invoke(ctx, UsersService_ListFollowers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersServiceClient) ListFollowing(ctx context.Context, in *ListFollowsRequest, opts ...grpc.CallOption) (*ListFollowsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFollowsResponse)
+	err := c.cc.Invoke(ctx, UsersService_ListFollowing_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersServiceClient) GetFollowerIDs(ctx context.Context, in *GetFollowerIDsRequest, opts ...grpc.CallOption) (*FollowerIDsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FollowerIDsResponse)
+	err := c.cc.Invoke(ctx, UsersService_GetFollowerIDs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersServiceClient) StreamFollowerIDs(ctx context.Context, in *StreamFollowerIDsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FollowerIDsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &UsersService_ServiceDesc.Streams[0], UsersService_StreamFollowerIDs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamFollowerIDsRequest, FollowerIDsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type UsersService_StreamFollowerIDsClient = grpc.ServerStreamingClient[FollowerIDsResponse]
+
 // UsersServiceServer is the server API for UsersService service.
 // All implementations must embed UnimplementedUsersServiceServer
 // for forward compatibility.
@@ -69,6 +169,20 @@ type UsersServiceServer interface {
 	GetUser(context.Context, *GetUserRequest) (*UserResponse, error)
 	// Get multiple users by IDs (batch)
 	GetUsers(context.Context, *GetUsersRequest) (*UsersResponse, error)
+	// Get single user by username
+	GetUserByUsername(context.Context, *GetUserByUsernameRequest) (*UserResponse, error)
+	// Get multiple users by usernames (batch)
+	GetUsersByUsernames(context.Context, *GetUsersByUsernamesRequest) (*UsersResponse, error)
+	// Check which follow relationships exist (batch)
+	CheckFollows(context.Context, *CheckFollowsRequest) (*CheckFollowsResponse, error)
+	// List the followers of a user, one page at a time
+	ListFollowers(context.Context, *ListFollowsRequest) (*ListFollowsResponse, error)
+	// List the users a user follows, one page at a time
+	ListFollowing(context.Context, *ListFollowsRequest) (*ListFollowsResponse, error)
+	// Get the IDs of all followers of a user
+	GetFollowerIDs(context.Context, *GetFollowerIDsRequest) (*FollowerIDsResponse, error)
+	// Stream the IDs of all followers of a user in chunks (large follower sets)
+	StreamFollowerIDs(*StreamFollowerIDsRequest, grpc.ServerStreamingServer[FollowerIDsResponse]) error
 	mustEmbedUnimplementedUsersServiceServer()
 }
 
@@ -85,6 +199,27 @@ func (UnimplementedUsersServiceServer) GetUser(context.Context, *GetUserRequest)
 func (UnimplementedUsersServiceServer) GetUsers(context.Context, *GetUsersRequest) (*UsersResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUsers not implemented")
 }
+func (UnimplementedUsersServiceServer) GetUserByUsername(context.Context, *GetUserByUsernameRequest) (*UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserByUsername not implemented")
+}
+func (UnimplementedUsersServiceServer) GetUsersByUsernames(context.Context, *GetUsersByUsernamesRequest) (*UsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsersByUsernames not implemented")
+}
+func (UnimplementedUsersServiceServer) CheckFollows(context.Context, *CheckFollowsRequest) (*CheckFollowsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckFollows not implemented")
+}
+func (UnimplementedUsersServiceServer) ListFollowers(context.Context, *ListFollowsRequest) (*ListFollowsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFollowers not implemented")
+}
+func (UnimplementedUsersServiceServer) ListFollowing(context.Context, *ListFollowsRequest) (*ListFollowsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFollowing not implemented")
+}
+func (UnimplementedUsersServiceServer) GetFollowerIDs(context.Context, *GetFollowerIDsRequest) (*FollowerIDsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFollowerIDs not implemented")
+}
+func (UnimplementedUsersServiceServer) StreamFollowerIDs(*StreamFollowerIDsRequest, grpc.ServerStreamingServer[FollowerIDsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamFollowerIDs not implemented")
+}
 func (UnimplementedUsersServiceServer) mustEmbedUnimplementedUsersServiceServer() {}
 func (UnimplementedUsersServiceServer) testEmbeddedByValue()                      {}
 
@@ -142,6 +277,125 @@ func _UsersService_GetUsers_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UsersService_GetUserByUsername_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserByUsernameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServiceServer).GetUserByUsername(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UsersService_GetUserByUsername_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServiceServer).GetUserByUsername(ctx, req.(*GetUserByUsernameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UsersService_GetUsersByUsernames_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUsersByUsernamesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServiceServer).GetUsersByUsernames(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UsersService_GetUsersByUsernames_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServiceServer).GetUsersByUsernames(ctx, req.(*GetUsersByUsernamesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UsersService_CheckFollows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckFollowsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServiceServer).CheckFollows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UsersService_CheckFollows_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServiceServer).CheckFollows(ctx, req.(*CheckFollowsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UsersService_ListFollowers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFollowsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServiceServer).ListFollowers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UsersService_ListFollowers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServiceServer).ListFollowers(ctx, req.(*ListFollowsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UsersService_ListFollowing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFollowsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServiceServer).ListFollowing(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UsersService_ListFollowing_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServiceServer).ListFollowing(ctx, req.(*ListFollowsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UsersService_GetFollowerIDs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFollowerIDsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServiceServer).GetFollowerIDs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UsersService_GetFollowerIDs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServiceServer).GetFollowerIDs(ctx, req.(*GetFollowerIDsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UsersService_StreamFollowerIDs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamFollowerIDsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UsersServiceServer).StreamFollowerIDs(m, &grpc.GenericServerStream[StreamFollowerIDsRequest, FollowerIDsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type UsersService_StreamFollowerIDsServer = grpc.ServerStreamingServer[FollowerIDsResponse]
+
 // UsersService_ServiceDesc is the grpc.ServiceDesc for UsersService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -157,7 +411,37 @@ var UsersService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUsers",
 			Handler:    _UsersService_GetUsers_Handler,
 		},
+		{
+			MethodName: "GetUserByUsername",
+			Handler:    _UsersService_GetUserByUsername_Handler,
+		},
+		{
+			MethodName: "GetUsersByUsernames",
+			Handler:    _UsersService_GetUsersByUsernames_Handler,
+		},
+		{
+			MethodName: "CheckFollows",
+			Handler:    _UsersService_CheckFollows_Handler,
+		},
+		{
+			MethodName: "ListFollowers",
+			Handler:    _UsersService_ListFollowers_Handler,
+		},
+		{
+			MethodName: "ListFollowing",
+			Handler:    _UsersService_ListFollowing_Handler,
+		},
+		{
+			MethodName: "GetFollowerIDs",
+			Handler:    _UsersService_GetFollowerIDs_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamFollowerIDs",
+			Handler:       _UsersService_StreamFollowerIDs_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/users.proto",
 }
@@ -0,0 +1,1853 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v6.32.0
+// source: proto/v2/users.proto
+
+package usersv2
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type UserChange_Type int32
+
+const (
+	UserChange_TYPE_UNSPECIFIED UserChange_Type = 0
+	UserChange_CREATED          UserChange_Type = 1
+	UserChange_UPDATED          UserChange_Type = 2
+	UserChange_DELETED          UserChange_Type = 3
+)
+
+// Enum value maps for UserChange_Type.
+var (
+	UserChange_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "CREATED",
+		2: "UPDATED",
+		3: "DELETED",
+	}
+	UserChange_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"CREATED":          1,
+		"UPDATED":          2,
+		"DELETED":          3,
+	}
+)
+
+func (x UserChange_Type) Enum() *UserChange_Type {
+	p := new(UserChange_Type)
+	*p = x
+	return p
+}
+
+func (x UserChange_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UserChange_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_v2_users_proto_enumTypes[0].Descriptor()
+}
+
+func (UserChange_Type) Type() protoreflect.EnumType {
+	return &file_proto_v2_users_proto_enumTypes[0]
+}
+
+func (x UserChange_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UserChange_Type.Descriptor instead.
+func (UserChange_Type) EnumDescriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{18, 0}
+}
+
+type User struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Username      string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	FirstName     string                 `protobuf:"bytes,3,opt,name=first_name,json=firstName,proto3" json:"first_name,omitempty"`
+	LastName      string                 `protobuf:"bytes,4,opt,name=last_name,json=lastName,proto3" json:"last_name,omitempty"`
+	Email         string                 `protobuf:"bytes,5,opt,name=email,proto3" json:"email,omitempty"`
+	CreateTime    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	UpdateTime    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_proto_v2_users_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *User) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *User) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *User) GetFirstName() string {
+	if x != nil {
+		return x.FirstName
+	}
+	return ""
+}
+
+func (x *User) GetLastName() string {
+	if x != nil {
+		return x.LastName
+	}
+	return ""
+}
+
+func (x *User) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *User) GetCreateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateTime
+	}
+	return nil
+}
+
+func (x *User) GetUpdateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdateTime
+	}
+	return nil
+}
+
+type GetUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ReadMask      *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=read_mask,json=readMask,proto3" json:"read_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserRequest) Reset() {
+	*x = GetUserRequest{}
+	mi := &file_proto_v2_users_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserRequest) ProtoMessage() {}
+
+func (x *GetUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserRequest.ProtoReflect.Descriptor instead.
+func (*GetUserRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetUserRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *GetUserRequest) GetReadMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.ReadMask
+	}
+	return nil
+}
+
+type GetUsersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserIds       []uint64               `protobuf:"varint,1,rep,packed,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"`
+	ReadMask      *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=read_mask,json=readMask,proto3" json:"read_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsersRequest) Reset() {
+	*x = GetUsersRequest{}
+	mi := &file_proto_v2_users_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsersRequest) ProtoMessage() {}
+
+func (x *GetUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsersRequest.ProtoReflect.Descriptor instead.
+func (*GetUsersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetUsersRequest) GetUserIds() []uint64 {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+func (x *GetUsersRequest) GetReadMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.ReadMask
+	}
+	return nil
+}
+
+type GetUsersResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Found users in request order, each requested user at most once
+	Users []*User `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	// Requested IDs with no (or a deleted) user
+	MissingIds    []uint64 `protobuf:"varint,2,rep,packed,name=missing_ids,json=missingIds,proto3" json:"missing_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsersResponse) Reset() {
+	*x = GetUsersResponse{}
+	mi := &file_proto_v2_users_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsersResponse) ProtoMessage() {}
+
+func (x *GetUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsersResponse.ProtoReflect.Descriptor instead.
+func (*GetUsersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetUsersResponse) GetUsers() []*User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *GetUsersResponse) GetMissingIds() []uint64 {
+	if x != nil {
+		return x.MissingIds
+	}
+	return nil
+}
+
+type GetUserByUsernameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	ReadMask      *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=read_mask,json=readMask,proto3" json:"read_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserByUsernameRequest) Reset() {
+	*x = GetUserByUsernameRequest{}
+	mi := &file_proto_v2_users_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserByUsernameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserByUsernameRequest) ProtoMessage() {}
+
+func (x *GetUserByUsernameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserByUsernameRequest.ProtoReflect.Descriptor instead.
+func (*GetUserByUsernameRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetUserByUsernameRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *GetUserByUsernameRequest) GetReadMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.ReadMask
+	}
+	return nil
+}
+
+type GetUsersByUsernamesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Usernames     []string               `protobuf:"bytes,1,rep,name=usernames,proto3" json:"usernames,omitempty"`
+	ReadMask      *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=read_mask,json=readMask,proto3" json:"read_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsersByUsernamesRequest) Reset() {
+	*x = GetUsersByUsernamesRequest{}
+	mi := &file_proto_v2_users_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsersByUsernamesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsersByUsernamesRequest) ProtoMessage() {}
+
+func (x *GetUsersByUsernamesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsersByUsernamesRequest.ProtoReflect.Descriptor instead.
+func (*GetUsersByUsernamesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetUsersByUsernamesRequest) GetUsernames() []string {
+	if x != nil {
+		return x.Usernames
+	}
+	return nil
+}
+
+func (x *GetUsersByUsernamesRequest) GetReadMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.ReadMask
+	}
+	return nil
+}
+
+type GetUsersByUsernamesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Found users in request order, each requested user at most once
+	Users []*User `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	// Requested usernames with no (or a deleted) user
+	MissingUsernames []string `protobuf:"bytes,2,rep,name=missing_usernames,json=missingUsernames,proto3" json:"missing_usernames,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *GetUsersByUsernamesResponse) Reset() {
+	*x = GetUsersByUsernamesResponse{}
+	mi := &file_proto_v2_users_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsersByUsernamesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsersByUsernamesResponse) ProtoMessage() {}
+
+func (x *GetUsersByUsernamesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsersByUsernamesResponse.ProtoReflect.Descriptor instead.
+func (*GetUsersByUsernamesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetUsersByUsernamesResponse) GetUsers() []*User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *GetUsersByUsernamesResponse) GetMissingUsernames() []string {
+	if x != nil {
+		return x.MissingUsernames
+	}
+	return nil
+}
+
+type FollowPair struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FollowerId    uint64                 `protobuf:"varint,1,opt,name=follower_id,json=followerId,proto3" json:"follower_id,omitempty"`
+	FollowedId    uint64                 `protobuf:"varint,2,opt,name=followed_id,json=followedId,proto3" json:"followed_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FollowPair) Reset() {
+	*x = FollowPair{}
+	mi := &file_proto_v2_users_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FollowPair) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FollowPair) ProtoMessage() {}
+
+func (x *FollowPair) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FollowPair.ProtoReflect.Descriptor instead.
+func (*FollowPair) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FollowPair) GetFollowerId() uint64 {
+	if x != nil {
+		return x.FollowerId
+	}
+	return 0
+}
+
+func (x *FollowPair) GetFollowedId() uint64 {
+	if x != nil {
+		return x.FollowedId
+	}
+	return 0
+}
+
+type CheckFollowsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pairs         []*FollowPair          `protobuf:"bytes,1,rep,name=pairs,proto3" json:"pairs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckFollowsRequest) Reset() {
+	*x = CheckFollowsRequest{}
+	mi := &file_proto_v2_users_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckFollowsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckFollowsRequest) ProtoMessage() {}
+
+func (x *CheckFollowsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckFollowsRequest.ProtoReflect.Descriptor instead.
+func (*CheckFollowsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CheckFollowsRequest) GetPairs() []*FollowPair {
+	if x != nil {
+		return x.Pairs
+	}
+	return nil
+}
+
+type FollowCheck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FollowerId    uint64                 `protobuf:"varint,1,opt,name=follower_id,json=followerId,proto3" json:"follower_id,omitempty"`
+	FollowedId    uint64                 `protobuf:"varint,2,opt,name=followed_id,json=followedId,proto3" json:"followed_id,omitempty"`
+	Follows       bool                   `protobuf:"varint,3,opt,name=follows,proto3" json:"follows,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FollowCheck) Reset() {
+	*x = FollowCheck{}
+	mi := &file_proto_v2_users_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FollowCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FollowCheck) ProtoMessage() {}
+
+func (x *FollowCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FollowCheck.ProtoReflect.Descriptor instead.
+func (*FollowCheck) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *FollowCheck) GetFollowerId() uint64 {
+	if x != nil {
+		return x.FollowerId
+	}
+	return 0
+}
+
+func (x *FollowCheck) GetFollowedId() uint64 {
+	if x != nil {
+		return x.FollowedId
+	}
+	return 0
+}
+
+func (x *FollowCheck) GetFollows() bool {
+	if x != nil {
+		return x.Follows
+	}
+	return false
+}
+
+type CheckFollowsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One result per requested pair, in request order
+	Results       []*FollowCheck `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckFollowsResponse) Reset() {
+	*x = CheckFollowsResponse{}
+	mi := &file_proto_v2_users_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckFollowsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckFollowsResponse) ProtoMessage() {}
+
+func (x *CheckFollowsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckFollowsResponse.ProtoReflect.Descriptor instead.
+func (*CheckFollowsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CheckFollowsResponse) GetResults() []*FollowCheck {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type ListFollowsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Defaults to 20, at most 100
+	PageSize int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token of the previous page, empty for the first page
+	PageToken string `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Fields of Follow.user to return
+	ReadMask      *fieldmaskpb.FieldMask `protobuf:"bytes,4,opt,name=read_mask,json=readMask,proto3" json:"read_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFollowsRequest) Reset() {
+	*x = ListFollowsRequest{}
+	mi := &file_proto_v2_users_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFollowsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFollowsRequest) ProtoMessage() {}
+
+func (x *ListFollowsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFollowsRequest.ProtoReflect.Descriptor instead.
+func (*ListFollowsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListFollowsRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ListFollowsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListFollowsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListFollowsRequest) GetReadMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.ReadMask
+	}
+	return nil
+}
+
+type Follow struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	FollowerId uint64                 `protobuf:"varint,1,opt,name=follower_id,json=followerId,proto3" json:"follower_id,omitempty"`
+	FollowedId uint64                 `protobuf:"varint,2,opt,name=followed_id,json=followedId,proto3" json:"followed_id,omitempty"`
+	FollowTime *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=follow_time,json=followTime,proto3" json:"follow_time,omitempty"`
+	// The other user: the follower for ListFollowers, the followed user for
+	// ListFollowing
+	User          *User `protobuf:"bytes,4,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Follow) Reset() {
+	*x = Follow{}
+	mi := &file_proto_v2_users_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Follow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Follow) ProtoMessage() {}
+
+func (x *Follow) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Follow.ProtoReflect.Descriptor instead.
+func (*Follow) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Follow) GetFollowerId() uint64 {
+	if x != nil {
+		return x.FollowerId
+	}
+	return 0
+}
+
+func (x *Follow) GetFollowedId() uint64 {
+	if x != nil {
+		return x.FollowedId
+	}
+	return 0
+}
+
+func (x *Follow) GetFollowTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FollowTime
+	}
+	return nil
+}
+
+func (x *Follow) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+type ListFollowsResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Follows []*Follow              `protobuf:"bytes,1,rep,name=follows,proto3" json:"follows,omitempty"`
+	// Empty on the last page
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	TotalCount    int32  `protobuf:"varint,3,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFollowsResponse) Reset() {
+	*x = ListFollowsResponse{}
+	mi := &file_proto_v2_users_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFollowsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFollowsResponse) ProtoMessage() {}
+
+func (x *ListFollowsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFollowsResponse.ProtoReflect.Descriptor instead.
+func (*ListFollowsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListFollowsResponse) GetFollows() []*Follow {
+	if x != nil {
+		return x.Follows
+	}
+	return nil
+}
+
+func (x *ListFollowsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListFollowsResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+type GetFollowerIDsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFollowerIDsRequest) Reset() {
+	*x = GetFollowerIDsRequest{}
+	mi := &file_proto_v2_users_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFollowerIDsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFollowerIDsRequest) ProtoMessage() {}
+
+func (x *GetFollowerIDsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFollowerIDsRequest.ProtoReflect.Descriptor instead.
+func (*GetFollowerIDsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetFollowerIDsRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type StreamFollowerIDsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// IDs per message, defaults to 1000
+	ChunkSize     int32 `protobuf:"varint,2,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamFollowerIDsRequest) Reset() {
+	*x = StreamFollowerIDsRequest{}
+	mi := &file_proto_v2_users_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamFollowerIDsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamFollowerIDsRequest) ProtoMessage() {}
+
+func (x *StreamFollowerIDsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamFollowerIDsRequest.ProtoReflect.Descriptor instead.
+func (*StreamFollowerIDsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *StreamFollowerIDsRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *StreamFollowerIDsRequest) GetChunkSize() int32 {
+	if x != nil {
+		return x.ChunkSize
+	}
+	return 0
+}
+
+type FollowerIDsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FollowerIds   []uint64               `protobuf:"varint,1,rep,packed,name=follower_ids,json=followerIds,proto3" json:"follower_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FollowerIDsResponse) Reset() {
+	*x = FollowerIDsResponse{}
+	mi := &file_proto_v2_users_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FollowerIDsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FollowerIDsResponse) ProtoMessage() {}
+
+func (x *FollowerIDsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FollowerIDsResponse.ProtoReflect.Descriptor instead.
+func (*FollowerIDsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *FollowerIDsResponse) GetFollowerIds() []uint64 {
+	if x != nil {
+		return x.FollowerIds
+	}
+	return nil
+}
+
+type WatchUsersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Sequence of the last change the client received. 0 starts with the
+	// changes made after the call.
+	StartAfter uint64 `protobuf:"varint,1,opt,name=start_after,json=startAfter,proto3" json:"start_after,omitempty"`
+	// Fields of UserChange.user to return
+	ReadMask      *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=read_mask,json=readMask,proto3" json:"read_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchUsersRequest) Reset() {
+	*x = WatchUsersRequest{}
+	mi := &file_proto_v2_users_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchUsersRequest) ProtoMessage() {}
+
+func (x *WatchUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchUsersRequest.ProtoReflect.Descriptor instead.
+func (*WatchUsersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *WatchUsersRequest) GetStartAfter() uint64 {
+	if x != nil {
+		return x.StartAfter
+	}
+	return 0
+}
+
+func (x *WatchUsersRequest) GetReadMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.ReadMask
+	}
+	return nil
+}
+
+type UserChange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Increases with every change, the cursor to resume from
+	Sequence uint64          `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Type     UserChange_Type `protobuf:"varint,2,opt,name=type,proto3,enum=users.v2.UserChange_Type" json:"type,omitempty"`
+	UserId   uint64          `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// The current state of the user; unset for DELETED and when the user no
+	// longer exists
+	User          *User                  `protobuf:"bytes,4,opt,name=user,proto3" json:"user,omitempty"`
+	ChangeTime    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=change_time,json=changeTime,proto3" json:"change_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserChange) Reset() {
+	*x = UserChange{}
+	mi := &file_proto_v2_users_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserChange) ProtoMessage() {}
+
+func (x *UserChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserChange.ProtoReflect.Descriptor instead.
+func (*UserChange) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *UserChange) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *UserChange) GetType() UserChange_Type {
+	if x != nil {
+		return x.Type
+	}
+	return UserChange_TYPE_UNSPECIFIED
+}
+
+func (x *UserChange) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *UserChange) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *UserChange) GetChangeTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ChangeTime
+	}
+	return nil
+}
+
+type CreateUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FirstName     string                 `protobuf:"bytes,1,opt,name=first_name,json=firstName,proto3" json:"first_name,omitempty"`
+	LastName      string                 `protobuf:"bytes,2,opt,name=last_name,json=lastName,proto3" json:"last_name,omitempty"`
+	Email         string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	Username      string                 `protobuf:"bytes,4,opt,name=username,proto3" json:"username,omitempty"`
+	Password      string                 `protobuf:"bytes,5,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateUserRequest) Reset() {
+	*x = CreateUserRequest{}
+	mi := &file_proto_v2_users_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateUserRequest) ProtoMessage() {}
+
+func (x *CreateUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateUserRequest.ProtoReflect.Descriptor instead.
+func (*CreateUserRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CreateUserRequest) GetFirstName() string {
+	if x != nil {
+		return x.FirstName
+	}
+	return ""
+}
+
+func (x *CreateUserRequest) GetLastName() string {
+	if x != nil {
+		return x.LastName
+	}
+	return ""
+}
+
+func (x *CreateUserRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *CreateUserRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *CreateUserRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type UpdateUserRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Only the fields that are set change
+	FirstName     *string `protobuf:"bytes,2,opt,name=first_name,json=firstName,proto3,oneof" json:"first_name,omitempty"`
+	LastName      *string `protobuf:"bytes,3,opt,name=last_name,json=lastName,proto3,oneof" json:"last_name,omitempty"`
+	Email         *string `protobuf:"bytes,4,opt,name=email,proto3,oneof" json:"email,omitempty"`
+	Username      *string `protobuf:"bytes,5,opt,name=username,proto3,oneof" json:"username,omitempty"`
+	Password      *string `protobuf:"bytes,6,opt,name=password,proto3,oneof" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateUserRequest) Reset() {
+	*x = UpdateUserRequest{}
+	mi := &file_proto_v2_users_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateUserRequest) ProtoMessage() {}
+
+func (x *UpdateUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateUserRequest.ProtoReflect.Descriptor instead.
+func (*UpdateUserRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *UpdateUserRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *UpdateUserRequest) GetFirstName() string {
+	if x != nil && x.FirstName != nil {
+		return *x.FirstName
+	}
+	return ""
+}
+
+func (x *UpdateUserRequest) GetLastName() string {
+	if x != nil && x.LastName != nil {
+		return *x.LastName
+	}
+	return ""
+}
+
+func (x *UpdateUserRequest) GetEmail() string {
+	if x != nil && x.Email != nil {
+		return *x.Email
+	}
+	return ""
+}
+
+func (x *UpdateUserRequest) GetUsername() string {
+	if x != nil && x.Username != nil {
+		return *x.Username
+	}
+	return ""
+}
+
+func (x *UpdateUserRequest) GetPassword() string {
+	if x != nil && x.Password != nil {
+		return *x.Password
+	}
+	return ""
+}
+
+type DeleteUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteUserRequest) Reset() {
+	*x = DeleteUserRequest{}
+	mi := &file_proto_v2_users_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUserRequest) ProtoMessage() {}
+
+func (x *DeleteUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteUserRequest.ProtoReflect.Descriptor instead.
+func (*DeleteUserRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DeleteUserRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type FollowRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FollowerId    uint64                 `protobuf:"varint,1,opt,name=follower_id,json=followerId,proto3" json:"follower_id,omitempty"`
+	FollowedId    uint64                 `protobuf:"varint,2,opt,name=followed_id,json=followedId,proto3" json:"followed_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FollowRequest) Reset() {
+	*x = FollowRequest{}
+	mi := &file_proto_v2_users_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FollowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FollowRequest) ProtoMessage() {}
+
+func (x *FollowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FollowRequest.ProtoReflect.Descriptor instead.
+func (*FollowRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *FollowRequest) GetFollowerId() uint64 {
+	if x != nil {
+		return x.FollowerId
+	}
+	return 0
+}
+
+func (x *FollowRequest) GetFollowedId() uint64 {
+	if x != nil {
+		return x.FollowedId
+	}
+	return 0
+}
+
+type FollowResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Follow *Follow                `protobuf:"bytes,1,opt,name=follow,proto3" json:"follow,omitempty"`
+	// False when follower_id already followed followed_id
+	Created       bool `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FollowResponse) Reset() {
+	*x = FollowResponse{}
+	mi := &file_proto_v2_users_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FollowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FollowResponse) ProtoMessage() {}
+
+func (x *FollowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FollowResponse.ProtoReflect.Descriptor instead.
+func (*FollowResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *FollowResponse) GetFollow() *Follow {
+	if x != nil {
+		return x.Follow
+	}
+	return nil
+}
+
+func (x *FollowResponse) GetCreated() bool {
+	if x != nil {
+		return x.Created
+	}
+	return false
+}
+
+type UnfollowRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FollowerId    uint64                 `protobuf:"varint,1,opt,name=follower_id,json=followerId,proto3" json:"follower_id,omitempty"`
+	FollowedId    uint64                 `protobuf:"varint,2,opt,name=followed_id,json=followedId,proto3" json:"followed_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnfollowRequest) Reset() {
+	*x = UnfollowRequest{}
+	mi := &file_proto_v2_users_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnfollowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnfollowRequest) ProtoMessage() {}
+
+func (x *UnfollowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnfollowRequest.ProtoReflect.Descriptor instead.
+func (*UnfollowRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *UnfollowRequest) GetFollowerId() uint64 {
+	if x != nil {
+		return x.FollowerId
+	}
+	return 0
+}
+
+func (x *UnfollowRequest) GetFollowedId() uint64 {
+	if x != nil {
+		return x.FollowedId
+	}
+	return 0
+}
+
+type UnfollowResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// False when follower_id did not follow followed_id
+	Removed       bool `protobuf:"varint,1,opt,name=removed,proto3" json:"removed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnfollowResponse) Reset() {
+	*x = UnfollowResponse{}
+	mi := &file_proto_v2_users_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnfollowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnfollowResponse) ProtoMessage() {}
+
+func (x *UnfollowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_users_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnfollowResponse.ProtoReflect.Descriptor instead.
+func (*UnfollowResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v2_users_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *UnfollowResponse) GetRemoved() bool {
+	if x != nil {
+		return x.Removed
+	}
+	return false
+}
+
+var File_proto_v2_users_proto protoreflect.FileDescriptor
+
+const file_proto_v2_users_proto_rawDesc = "" +
+	"\n" +
+	"\x14proto/v2/users.proto\x12\busers.v2\x1a\x1bgoogle/protobuf/empty.proto\x1a google/protobuf/field_mask.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xfe\x01\n" +
+	"\x04User\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x1d\n" +
+	"\n" +
+	"first_name\x18\x03 \x01(\tR\tfirstName\x12\x1b\n" +
+	"\tlast_name\x18\x04 \x01(\tR\blastName\x12\x14\n" +
+	"\x05email\x18\x05 \x01(\tR\x05email\x12;\n" +
+	"\vcreate_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"createTime\x12;\n" +
+	"\vupdate_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"updateTime\"b\n" +
+	"\x0eGetUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x127\n" +
+	"\tread_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\breadMask\"e\n" +
+	"\x0fGetUsersRequest\x12\x19\n" +
+	"\buser_ids\x18\x01 \x03(\x04R\auserIds\x127\n" +
+	"\tread_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\breadMask\"Y\n" +
+	"\x10GetUsersResponse\x12$\n" +
+	"\x05users\x18\x01 \x03(\v2\x0e.users.v2.UserR\x05users\x12\x1f\n" +
+	"\vmissing_ids\x18\x02 \x03(\x04R\n" +
+	"missingIds\"o\n" +
+	"\x18GetUserByUsernameRequest\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x127\n" +
+	"\tread_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\breadMask\"s\n" +
+	"\x1aGetUsersByUsernamesRequest\x12\x1c\n" +
+	"\tusernames\x18\x01 \x03(\tR\tusernames\x127\n" +
+	"\tread_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\breadMask\"p\n" +
+	"\x1bGetUsersByUsernamesResponse\x12$\n" +
+	"\x05users\x18\x01 \x03(\v2\x0e.users.v2.UserR\x05users\x12+\n" +
+	"\x11missing_usernames\x18\x02 \x03(\tR\x10missingUsernames\"N\n" +
+	"\n" +
+	"FollowPair\x12\x1f\n" +
+	"\vfollower_id\x18\x01 \x01(\x04R\n" +
+	"followerId\x12\x1f\n" +
+	"\vfollowed_id\x18\x02 \x01(\x04R\n" +
+	"followedId\"A\n" +
+	"\x13CheckFollowsRequest\x12*\n" +
+	"\x05pairs\x18\x01 \x03(\v2\x14.users.v2.FollowPairR\x05pairs\"i\n" +
+	"\vFollowCheck\x12\x1f\n" +
+	"\vfollower_id\x18\x01 \x01(\x04R\n" +
+	"followerId\x12\x1f\n" +
+	"\vfollowed_id\x18\x02 \x01(\x04R\n" +
+	"followedId\x12\x18\n" +
+	"\afollows\x18\x03 \x01(\bR\afollows\"G\n" +
+	"\x14CheckFollowsResponse\x12/\n" +
+	"\aresults\x18\x01 \x03(\v2\x15.users.v2.FollowCheckR\aresults\"\xa2\x01\n" +
+	"\x12ListFollowsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\x127\n" +
+	"\tread_mask\x18\x04 \x01(\v2\x1a.google.protobuf.FieldMaskR\breadMask\"\xab\x01\n" +
+	"\x06Follow\x12\x1f\n" +
+	"\vfollower_id\x18\x01 \x01(\x04R\n" +
+	"followerId\x12\x1f\n" +
+	"\vfollowed_id\x18\x02 \x01(\x04R\n" +
+	"followedId\x12;\n" +
+	"\vfollow_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"followTime\x12\"\n" +
+	"\x04user\x18\x04 \x01(\v2\x0e.users.v2.UserR\x04user\"\x8a\x01\n" +
+	"\x13ListFollowsResponse\x12*\n" +
+	"\afollows\x18\x01 \x03(\v2\x10.users.v2.FollowR\afollows\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x1f\n" +
+	"\vtotal_count\x18\x03 \x01(\x05R\n" +
+	"totalCount\"0\n" +
+	"\x15GetFollowerIDsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\"R\n" +
+	"\x18StreamFollowerIDsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x1d\n" +
+	"\n" +
+	"chunk_size\x18\x02 \x01(\x05R\tchunkSize\"8\n" +
+	"\x13FollowerIDsResponse\x12!\n" +
+	"\ffollower_ids\x18\x01 \x03(\x04R\vfollowerIds\"m\n" +
+	"\x11WatchUsersRequest\x12\x1f\n" +
+	"\vstart_after\x18\x01 \x01(\x04R\n" +
+	"startAfter\x127\n" +
+	"\tread_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\breadMask\"\x96\x02\n" +
+	"\n" +
+	"UserChange\x12\x1a\n" +
+	"\bsequence\x18\x01 \x01(\x04R\bsequence\x12-\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x19.users.v2.UserChange.TypeR\x04type\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\x04R\x06userId\x12\"\n" +
+	"\x04user\x18\x04 \x01(\v2\x0e.users.v2.UserR\x04user\x12;\n" +
+	"\vchange_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"changeTime\"C\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\v\n" +
+	"\aCREATED\x10\x01\x12\v\n" +
+	"\aUPDATED\x10\x02\x12\v\n" +
+	"\aDELETED\x10\x03\"\x9d\x01\n" +
+	"\x11CreateUserRequest\x12\x1d\n" +
+	"\n" +
+	"first_name\x18\x01 \x01(\tR\tfirstName\x12\x1b\n" +
+	"\tlast_name\x18\x02 \x01(\tR\blastName\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\x12\x1a\n" +
+	"\busername\x18\x04 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\x05 \x01(\tR\bpassword\"\x90\x02\n" +
+	"\x11UpdateUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\"\n" +
+	"\n" +
+	"first_name\x18\x02 \x01(\tH\x00R\tfirstName\x88\x01\x01\x12 \n" +
+	"\tlast_name\x18\x03 \x01(\tH\x01R\blastName\x88\x01\x01\x12\x19\n" +
+	"\x05email\x18\x04 \x01(\tH\x02R\x05email\x88\x01\x01\x12\x1f\n" +
+	"\busername\x18\x05 \x01(\tH\x03R\busername\x88\x01\x01\x12\x1f\n" +
+	"\bpassword\x18\x06 \x01(\tH\x04R\bpassword\x88\x01\x01B\r\n" +
+	"\v_first_nameB\f\n" +
+	"\n" +
+	"_last_nameB\b\n" +
+	"\x06_emailB\v\n" +
+	"\t_usernameB\v\n" +
+	"\t_password\",\n" +
+	"\x11DeleteUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\"Q\n" +
+	"\rFollowRequest\x12\x1f\n" +
+	"\vfollower_id\x18\x01 \x01(\x04R\n" +
+	"followerId\x12\x1f\n" +
+	"\vfollowed_id\x18\x02 \x01(\x04R\n" +
+	"followedId\"T\n" +
+	"\x0eFollowResponse\x12(\n" +
+	"\x06follow\x18\x01 \x01(\v2\x10.users.v2.FollowR\x06follow\x12\x18\n" +
+	"\acreated\x18\x02 \x01(\bR\acreated\"S\n" +
+	"\x0fUnfollowRequest\x12\x1f\n" +
+	"\vfollower_id\x18\x01 \x01(\x04R\n" +
+	"followerId\x12\x1f\n" +
+	"\vfollowed_id\x18\x02 \x01(\x04R\n" +
+	"followedId\",\n" +
+	"\x10UnfollowResponse\x12\x18\n" +
+	"\aremoved\x18\x01 \x01(\bR\aremoved2\xc6\b\n" +
+	"\fUsersService\x123\n" +
+	"\aGetUser\x12\x18.users.v2.GetUserRequest\x1a\x0e.users.v2.User\x12A\n" +
+	"\bGetUsers\x12\x19.users.v2.GetUsersRequest\x1a\x1a.users.v2.GetUsersResponse\x12G\n" +
+	"\x11GetUserByUsername\x12\".users.v2.GetUserByUsernameRequest\x1a\x0e.users.v2.User\x12b\n" +
+	"\x13GetUsersByUsernames\x12$.users.v2.GetUsersByUsernamesRequest\x1a%.users.v2.GetUsersByUsernamesResponse\x12M\n" +
+	"\fCheckFollows\x12\x1d.users.v2.CheckFollowsRequest\x1a\x1e.users.v2.CheckFollowsResponse\x12L\n" +
+	"\rListFollowers\x12\x1c.users.v2.ListFollowsRequest\x1a\x1d.users.v2.ListFollowsResponse\x12L\n" +
+	"\rListFollowing\x12\x1c.users.v2.ListFollowsRequest\x1a\x1d.users.v2.ListFollowsResponse\x12P\n" +
+	"\x0eGetFollowerIDs\x12\x1f.users.v2.GetFollowerIDsRequest\x1a\x1d.users.v2.FollowerIDsResponse\x12X\n" +
+	"\x11StreamFollowerIDs\x12\".users.v2.StreamFollowerIDsRequest\x1a\x1d.users.v2.FollowerIDsResponse0\x01\x12A\n" +
+	"\n" +
+	"WatchUsers\x12\x1b.users.v2.WatchUsersRequest\x1a\x14.users.v2.UserChange0\x01\x129\n" +
+	"\n" +
+	"CreateUser\x12\x1b.users.v2.CreateUserRequest\x1a\x0e.users.v2.User\x129\n" +
+	"\n" +
+	"UpdateUser\x12\x1b.users.v2.UpdateUserRequest\x1a\x0e.users.v2.User\x12A\n" +
+	"\n" +
+	"DeleteUser\x12\x1b.users.v2.DeleteUserRequest\x1a\x16.google.protobuf.Empty\x12;\n" +
+	"\x06Follow\x12\x17.users.v2.FollowRequest\x1a\x18.users.v2.FollowResponse\x12A\n" +
+	"\bUnfollow\x12\x19.users.v2.UnfollowRequest\x1a\x1a.users.v2.UnfollowResponseB7Z5github.com/antoniocfetngnu/users-api/proto/v2;usersv2b\x06proto3"
+
+var (
+	file_proto_v2_users_proto_rawDescOnce sync.Once
+	file_proto_v2_users_proto_rawDescData []byte
+)
+
+func file_proto_v2_users_proto_rawDescGZIP() []byte {
+	file_proto_v2_users_proto_rawDescOnce.Do(func() {
+		file_proto_v2_users_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_v2_users_proto_rawDesc), len(file_proto_v2_users_proto_rawDesc)))
+	})
+	return file_proto_v2_users_proto_rawDescData
+}
+
+var file_proto_v2_users_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_v2_users_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
+var file_proto_v2_users_proto_goTypes = []any{
+	(UserChange_Type)(0),                // 0: users.v2.UserChange.Type
+	(*User)(nil),                        // 1: users.v2.User
+	(*GetUserRequest)(nil),              // 2: users.v2.GetUserRequest
+	(*GetUsersRequest)(nil),             // 3: users.v2.GetUsersRequest
+	(*GetUsersResponse)(nil),            // 4: users.v2.GetUsersResponse
+	(*GetUserByUsernameRequest)(nil),    // 5: users.v2.GetUserByUsernameRequest
+	(*GetUsersByUsernamesRequest)(nil),  // 6: users.v2.GetUsersByUsernamesRequest
+	(*GetUsersByUsernamesResponse)(nil), // 7: users.v2.GetUsersByUsernamesResponse
+	(*FollowPair)(nil),                  // 8: users.v2.FollowPair
+	(*CheckFollowsRequest)(nil),         // 9: users.v2.CheckFollowsRequest
+	(*FollowCheck)(nil),                 // 10: users.v2.FollowCheck
+	(*CheckFollowsResponse)(nil),        // 11: users.v2.CheckFollowsResponse
+	(*ListFollowsRequest)(nil),          // 12: users.v2.ListFollowsRequest
+	(*Follow)(nil),                      // 13: users.v2.Follow
+	(*ListFollowsResponse)(nil),         // 14: users.v2.ListFollowsResponse
+	(*GetFollowerIDsRequest)(nil),       // 15: users.v2.GetFollowerIDsRequest
+	(*StreamFollowerIDsRequest)(nil),    // 16: users.v2.StreamFollowerIDsRequest
+	(*FollowerIDsResponse)(nil),         // 17: users.v2.FollowerIDsResponse
+	(*WatchUsersRequest)(nil),           // 18: users.v2.WatchUsersRequest
+	(*UserChange)(nil),                  // 19: users.v2.UserChange
+	(*CreateUserRequest)(nil),           // 20: users.v2.CreateUserRequest
+	(*UpdateUserRequest)(nil),           // 21: users.v2.UpdateUserRequest
+	(*DeleteUserRequest)(nil),           // 22: users.v2.DeleteUserRequest
+	(*FollowRequest)(nil),               // 23: users.v2.FollowRequest
+	(*FollowResponse)(nil),              // 24: users.v2.FollowResponse
+	(*UnfollowRequest)(nil),             // 25: users.v2.UnfollowRequest
+	(*UnfollowResponse)(nil),            // 26: users.v2.UnfollowResponse
+	(*timestamppb.Timestamp)(nil),       // 27: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil),       // 28: google.protobuf.FieldMask
+	(*emptypb.Empty)(nil),               // 29: google.protobuf.Empty
+}
+var file_proto_v2_users_proto_depIdxs = []int32{
+	27, // 0: users.v2.User.create_time:type_name -> google.protobuf.Timestamp
+	27, // 1: users.v2.User.update_time:type_name -> google.protobuf.Timestamp
+	28, // 2: users.v2.GetUserRequest.read_mask:type_name -> google.protobuf.FieldMask
+	28, // 3: users.v2.GetUsersRequest.read_mask:type_name -> google.protobuf.FieldMask
+	1,  // 4: users.v2.GetUsersResponse.users:type_name -> users.v2.User
+	28, // 5: users.v2.GetUserByUsernameRequest.read_mask:type_name -> google.protobuf.FieldMask
+	28, // 6: users.v2.GetUsersByUsernamesRequest.read_mask:type_name -> google.protobuf.FieldMask
+	1,  // 7: users.v2.GetUsersByUsernamesResponse.users:type_name -> users.v2.User
+	8,  // 8: users.v2.CheckFollowsRequest.pairs:type_name -> users.v2.FollowPair
+	10, // 9: users.v2.CheckFollowsResponse.results:type_name -> users.v2.FollowCheck
+	28, // 10: users.v2.ListFollowsRequest.read_mask:type_name -> google.protobuf.FieldMask
+	27, // 11: users.v2.Follow.follow_time:type_name -> google.protobuf.Timestamp
+	1,  // 12: users.v2.Follow.user:type_name -> users.v2.User
+	13, // 13: users.v2.ListFollowsResponse.follows:type_name -> users.v2.Follow
+	28, // 14: users.v2.WatchUsersRequest.read_mask:type_name -> google.protobuf.FieldMask
+	0,  // 15: users.v2.UserChange.type:type_name -> users.v2.UserChange.Type
+	1,  // 16: users.v2.UserChange.user:type_name -> users.v2.User
+	27, // 17: users.v2.UserChange.change_time:type_name -> google.protobuf.Timestamp
+	13, // 18: users.v2.FollowResponse.follow:type_name -> users.v2.Follow
+	2,  // 19: users.v2.UsersService.GetUser:input_type -> users.v2.GetUserRequest
+	3,  // 20: users.v2.UsersService.GetUsers:input_type -> users.v2.GetUsersRequest
+	5,  // 21: users.v2.UsersService.GetUserByUsername:input_type -> users.v2.GetUserByUsernameRequest
+	6,  // 22: users.v2.UsersService.GetUsersByUsernames:input_type -> users.v2.GetUsersByUsernamesRequest
+	9,  // 23: users.v2.UsersService.CheckFollows:input_type -> users.v2.CheckFollowsRequest
+	12, // 24: users.v2.UsersService.ListFollowers:input_type -> users.v2.ListFollowsRequest
+	12, // 25: users.v2.UsersService.ListFollowing:input_type -> users.v2.ListFollowsRequest
+	15, // 26: users.v2.UsersService.GetFollowerIDs:input_type -> users.v2.GetFollowerIDsRequest
+	16, // 27: users.v2.UsersService.StreamFollowerIDs:input_type -> users.v2.StreamFollowerIDsRequest
+	18, // 28: users.v2.UsersService.WatchUsers:input_type -> users.v2.WatchUsersRequest
+	20, // 29: users.v2.UsersService.CreateUser:input_type -> users.v2.CreateUserRequest
+	21, // 30: users.v2.UsersService.UpdateUser:input_type -> users.v2.UpdateUserRequest
+	22, // 31: users.v2.UsersService.DeleteUser:input_type -> users.v2.DeleteUserRequest
+	23, // 32: users.v2.UsersService.Follow:input_type -> users.v2.FollowRequest
+	25, // 33: users.v2.UsersService.Unfollow:input_type -> users.v2.UnfollowRequest
+	1,  // 34: users.v2.UsersService.GetUser:output_type -> users.v2.User
+	4,  // 35: users.v2.UsersService.GetUsers:output_type -> users.v2.GetUsersResponse
+	1,  // 36: users.v2.UsersService.GetUserByUsername:output_type -> users.v2.User
+	7,  // 37: users.v2.UsersService.GetUsersByUsernames:output_type -> users.v2.GetUsersByUsernamesResponse
+	11, // 38: users.v2.UsersService.CheckFollows:output_type -> users.v2.CheckFollowsResponse
+	14, // 39: users.v2.UsersService.ListFollowers:output_type -> users.v2.ListFollowsResponse
+	14, // 40: users.v2.UsersService.ListFollowing:output_type -> users.v2.ListFollowsResponse
+	17, // 41: users.v2.UsersService.GetFollowerIDs:output_type -> users.v2.FollowerIDsResponse
+	17, // 42: users.v2.UsersService.StreamFollowerIDs:output_type -> users.v2.FollowerIDsResponse
+	19, // 43: users.v2.UsersService.WatchUsers:output_type -> users.v2.UserChange
+	1,  // 44: users.v2.UsersService.CreateUser:output_type -> users.v2.User
+	1,  // 45: users.v2.UsersService.UpdateUser:output_type -> users.v2.User
+	29, // 46: users.v2.UsersService.DeleteUser:output_type -> google.protobuf.Empty
+	24, // 47: users.v2.UsersService.Follow:output_type -> users.v2.FollowResponse
+	26, // 48: users.v2.UsersService.Unfollow:output_type -> users.v2.UnfollowResponse
+	34, // [34:49] is the sub-list for method output_type
+	19, // [19:34] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
+}
+
+func init() { file_proto_v2_users_proto_init() }
+func file_proto_v2_users_proto_init() {
+	if File_proto_v2_users_proto != nil {
+		return
+	}
+	file_proto_v2_users_proto_msgTypes[20].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_v2_users_proto_rawDesc), len(file_proto_v2_users_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   26,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_v2_users_proto_goTypes,
+		DependencyIndexes: file_proto_v2_users_proto_depIdxs,
+		EnumInfos:         file_proto_v2_users_proto_enumTypes,
+		MessageInfos:      file_proto_v2_users_proto_msgTypes,
+	}.Build()
+	File_proto_v2_users_proto = out.File
+	file_proto_v2_users_proto_goTypes = nil
+	file_proto_v2_users_proto_depIdxs = nil
+}
@@ -0,0 +1,494 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/antoniocfetngnu/users-api/models"
+)
+
+// userChangesLock is the Postgres advisory lock serializing appends to
+// user_changes. Without it a transaction could commit sequence N after
+// another one committed N+1, and a reader that already moved past N+1
+// would never see N.
+const userChangesLock = 0x75736572 // "user"
+
+// NewGormStore returns a Store over db (Postgres in production)
+func NewGormStore(db *gorm.DB) Store {
+	return &gormStore{db: db}
+}
+
+type gormStore struct {
+	db *gorm.DB
+}
+
+func (s *gormStore) Users() UserRepository                     { return gormUsers{s.db} }
+func (s *gormStore) Follows() FollowRepository                 { return gormFollows{s.db} }
+func (s *gormStore) UserChanges() UserChangeRepository         { return gormUserChanges{s.db} }
+func (s *gormStore) IdempotencyKeys() IdempotencyKeyRepository { return gormIdempotencyKeys{s.db} }
+func (s *gormStore) AuditLog() AuditRepository                 { return gormAuditLog{s.db} }
+
+func (s *gormStore) Transaction(ctx context.Context, fn func(tx Store) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&gormStore{db: tx})
+	})
+}
+
+// first loads the single row matched by query into dest
+func first(query *gorm.DB, dest any) error {
+	if err := query.First(dest).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// window applies w to a query ordered by id and returns the rows of the
+// window together with the total count of query
+func window[T any](query *gorm.DB, w Window) ([]T, int, error) {
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	windowed := query.Session(&gorm.Session{})
+	if w.AfterID != nil {
+		windowed = windowed.Where("id > ?", *w.AfterID)
+	}
+	if w.BeforeID != nil {
+		windowed = windowed.Where("id < ?", *w.BeforeID)
+	}
+	if w.Limit > 0 {
+		windowed = windowed.Limit(w.Limit)
+	}
+	order := "id ASC"
+	if w.Descending {
+		order = "id DESC"
+	}
+
+	var items []T
+	if err := windowed.Order(order).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, int(total), nil
+}
+
+type gormUsers struct {
+	db *gorm.DB
+}
+
+func (r gormUsers) Get(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := first(r.db.WithContext(ctx).Where("id = ?", id), &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r gormUsers) GetWithDeleted(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := first(r.db.WithContext(ctx).Unscoped().Where("id = ?", id), &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r gormUsers) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	if err := first(r.db.WithContext(ctx).Where("username = ?", username), &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r gormUsers) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	if err := first(r.db.WithContext(ctx).Where("email = ?", email), &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r gormUsers) GetByIDs(ctx context.Context, ids []uint) ([]*models.User, error) {
+	users := []*models.User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
+func (r gormUsers) GetByUsernames(ctx context.Context, usernames []string) ([]*models.User, error) {
+	users := []*models.User{}
+	if len(usernames) == 0 {
+		return users, nil
+	}
+	err := r.db.WithContext(ctx).Where("username IN ?", usernames).Find(&users).Error
+	return users, err
+}
+
+func (r gormUsers) List(ctx context.Context, query string, w Window) ([]*models.User, int, error) {
+	q := r.db.WithContext(ctx).Model(&models.User{})
+	if query != "" {
+		pattern := "%" + strings.ToLower(query) + "%"
+		q = q.Where("LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ? OR LOWER(username) LIKE ?", pattern, pattern, pattern)
+	}
+	return window[*models.User](q, w)
+}
+
+func (r gormUsers) Taken(ctx context.Context, username, email string, exceptID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("(username = ? OR email = ?) AND id <> ?", username, email, exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r gormUsers) Create(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+func (r gormUsers) Update(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Save(user).Error
+}
+
+func (r gormUsers) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.User{}, id).Error
+}
+
+func (r gormUsers) Restore(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&models.User{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+func (r gormUsers) ListDeletedBefore(ctx context.Context, before time.Time, limit int) ([]*models.User, error) {
+	users := []*models.User{}
+	err := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ? AND erased_at IS NULL", before).
+		Order("deleted_at, id").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+func (r gormUsers) Purge(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Unscoped().Delete(&models.User{}, id)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return result.Error
+}
+
+func (r gormUsers) Erase(ctx context.Context, user *models.User) error {
+	// Not Save, which inserts the user when the row is gone
+	result := r.db.WithContext(ctx).Unscoped().Select("*").Omit("id", "created_at").Updates(user)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return result.Error
+}
+
+type gormFollows struct {
+	db *gorm.DB
+}
+
+func (r gormFollows) Get(ctx context.Context, id uint) (*models.Follower, error) {
+	var edge models.Follower
+	if err := first(r.db.WithContext(ctx).Where("id = ?", id), &edge); err != nil {
+		return nil, err
+	}
+	return &edge, nil
+}
+
+func (r gormFollows) GetPair(ctx context.Context, pair FollowPair) (*models.Follower, error) {
+	var edge models.Follower
+	query := r.db.WithContext(ctx).Where("follower_id = ? AND followed_id = ?", pair.FollowerID, pair.FollowedID)
+	if err := first(query, &edge); err != nil {
+		return nil, err
+	}
+	return &edge, nil
+}
+
+func (r gormFollows) Create(ctx context.Context, edge *models.Follower) (bool, error) {
+	// The unique pair index makes concurrent follows race-free: the loser of
+	// the race inserts nothing
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "follower_id"}, {Name: "followed_id"}},
+		DoNothing: true,
+	}).Create(edge)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r gormFollows) Delete(ctx context.Context, pair FollowPair) (*models.Follower, error) {
+	var edge models.Follower
+	if err := r.db.WithContext(ctx).
+		Where("follower_id = ? AND followed_id = ?", pair.FollowerID, pair.FollowedID).
+		Limit(1).
+		Find(&edge).Error; err != nil || edge.ID == 0 {
+		return nil, err
+	}
+
+	// A concurrent delete may have removed it in the meantime
+	result := r.db.WithContext(ctx).Delete(&edge)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &edge, nil
+}
+
+func (r gormFollows) DeleteByUser(ctx context.Context, userID uint) ([]*models.Follower, error) {
+	return r.deleteWhere(ctx, "follower_id = ? OR followed_id = ?", userID, userID)
+}
+
+func (r gormFollows) DeleteDangling(ctx context.Context) ([]*models.Follower, error) {
+	// The users query leaves soft-deleted rows out
+	live := r.db.WithContext(ctx).Model(&models.User{}).Select("id")
+	return r.deleteWhere(ctx, "follower_id NOT IN (?) OR followed_id NOT IN (?)", live, live)
+}
+
+// deleteWhere removes the edges matching the condition and returns them,
+// oldest first
+func (r gormFollows) deleteWhere(ctx context.Context, query string, args ...any) ([]*models.Follower, error) {
+	edges := []*models.Follower{}
+	if err := r.db.WithContext(ctx).Clauses(clause.Returning{}).Where(query, args...).Delete(&edges).Error; err != nil {
+		return nil, err
+	}
+	slices.SortFunc(edges, func(a, b *models.Follower) int { return int(a.ID) - int(b.ID) })
+	return edges, nil
+}
+
+func (r gormFollows) RecordEvent(ctx context.Context, event *models.FollowEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r gormFollows) PseudonymizeEvents(ctx context.Context, userID uint) (int, error) {
+	changed := 0
+	for _, column := range []string{"follower_id", "followed_id"} {
+		result := r.db.WithContext(ctx).Model(&models.FollowEvent{}).Where(column+" = ?", userID).Update(column, models.ErasedUserID)
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		changed += int(result.RowsAffected)
+	}
+	return changed, nil
+}
+
+func (r gormFollows) ListByFollowed(ctx context.Context, userIDs []uint) ([]*models.Follower, error) {
+	return r.listBy(ctx, "followed_id", userIDs)
+}
+
+func (r gormFollows) ListByFollower(ctx context.Context, userIDs []uint) ([]*models.Follower, error) {
+	return r.listBy(ctx, "follower_id", userIDs)
+}
+
+func (r gormFollows) listBy(ctx context.Context, column string, userIDs []uint) ([]*models.Follower, error) {
+	edges := []*models.Follower{}
+	if len(userIDs) == 0 {
+		return edges, nil
+	}
+	err := r.db.WithContext(ctx).Where(column+" IN ?", userIDs).Order("id").Find(&edges).Error
+	return edges, err
+}
+
+func (r gormFollows) PageByFollowed(ctx context.Context, userID uint, w Window) ([]*models.Follower, int, error) {
+	return window[*models.Follower](r.db.WithContext(ctx).Model(&models.Follower{}).Where("followed_id = ?", userID), w)
+}
+
+func (r gormFollows) PageByFollower(ctx context.Context, userID uint, w Window) ([]*models.Follower, int, error) {
+	return window[*models.Follower](r.db.WithContext(ctx).Model(&models.Follower{}).Where("follower_id = ?", userID), w)
+}
+
+func (r gormFollows) Existing(ctx context.Context, pairs []FollowPair) ([]FollowPair, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	values := make([][]any, len(pairs))
+	for i, pair := range pairs {
+		values[i] = []any{pair.FollowerID, pair.FollowedID}
+	}
+
+	var edges []*models.Follower
+	if err := r.db.WithContext(ctx).
+		Select("follower_id", "followed_id").
+		Where("(follower_id, followed_id) IN ?", values).
+		Find(&edges).Error; err != nil {
+		return nil, err
+	}
+
+	existing := make([]FollowPair, len(edges))
+	for i, edge := range edges {
+		existing[i] = FollowPair{FollowerID: edge.FollowerID, FollowedID: edge.FollowedID}
+	}
+	return existing, nil
+}
+
+func (r gormFollows) FollowerIDs(ctx context.Context, userID, afterEdgeID uint, limit int) ([]uint, uint, error) {
+	query := r.db.WithContext(ctx).
+		Select("id", "follower_id").
+		Where("followed_id = ? AND id > ?", userID, afterEdgeID).
+		Order("id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var edges []models.Follower
+	if err := query.Find(&edges).Error; err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]uint, len(edges))
+	for i, edge := range edges {
+		ids[i] = edge.FollowerID
+	}
+	if len(edges) == 0 {
+		return ids, afterEdgeID, nil
+	}
+	return ids, edges[len(edges)-1].ID, nil
+}
+
+func (r gormFollows) CountByFollowed(ctx context.Context, userIDs []uint) (map[uint]int, error) {
+	return r.countBy(ctx, "followed_id", userIDs)
+}
+
+func (r gormFollows) CountByFollower(ctx context.Context, userIDs []uint) (map[uint]int, error) {
+	return r.countBy(ctx, "follower_id", userIDs)
+}
+
+// countBy counts follow edges grouped by column (follower_id or followed_id)
+func (r gormFollows) countBy(ctx context.Context, column string, userIDs []uint) (map[uint]int, error) {
+	counts := make(map[uint]int, len(userIDs))
+	if len(userIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		UserID uint
+		Count  int
+	}
+	if err := r.db.WithContext(ctx).Model(&models.Follower{}).
+		Select(column+" AS user_id, COUNT(*) AS count").
+		Where(column+" IN ?", userIDs).
+		Group(column).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.UserID] = row.Count
+	}
+	return counts, nil
+}
+
+type gormUserChanges struct {
+	db *gorm.DB
+}
+
+func (r gormUserChanges) Append(ctx context.Context, userID uint, action string) (*models.UserChange, error) {
+	change := &models.UserChange{UserID: userID, Action: action}
+
+	// The lock is held until the surrounding transaction ends
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", userChangesLock).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(change).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+func (r gormUserChanges) ListAfter(ctx context.Context, after uint64, limit int) ([]*models.UserChange, error) {
+	var changes []*models.UserChange
+	err := r.db.WithContext(ctx).Where("id > ?", after).Order("id").Limit(limit).Find(&changes).Error
+	return changes, err
+}
+
+func (r gormUserChanges) Latest(ctx context.Context) (uint64, error) {
+	var latest uint64
+	err := r.db.WithContext(ctx).Model(&models.UserChange{}).Select("COALESCE(MAX(id), 0)").Scan(&latest).Error
+	return latest, err
+}
+
+type gormIdempotencyKeys struct {
+	db *gorm.DB
+}
+
+func (r gormIdempotencyKeys) Create(ctx context.Context, record *models.IdempotencyKey) (bool, error) {
+	// The unique key index makes concurrent claims race-free
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "scope"}, {Name: "key"}},
+		DoNothing: true,
+	}).Create(record)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r gormIdempotencyKeys) Get(ctx context.Context, scope, key string) (*models.IdempotencyKey, error) {
+	var record models.IdempotencyKey
+	if err := first(r.db.WithContext(ctx).Where("scope = ? AND key = ?", scope, key), &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r gormIdempotencyKeys) SetResponse(ctx context.Context, id uint, response []byte, userID uint) error {
+	var about *uint
+	if userID != 0 {
+		about = &userID
+	}
+	return r.db.WithContext(ctx).Model(&models.IdempotencyKey{}).Where("id = ?", id).
+		Updates(map[string]any{"response": response, "user_id": about}).Error
+}
+
+func (r gormIdempotencyKeys) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.IdempotencyKey{}, id).Error
+}
+
+func (r gormIdempotencyKeys) Reclaim(ctx context.Context, id uint, claimedBefore, claimedAt time.Time) (bool, error) {
+	// The condition makes concurrent reclaims race-free
+	result := r.db.WithContext(ctx).Model(&models.IdempotencyKey{}).
+		Where("id = ? AND response IS NULL AND claimed_at < ?", id, claimedBefore).
+		Update("claimed_at", claimedAt)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r gormIdempotencyKeys) DeleteExpired(ctx context.Context, scope string, before time.Time) error {
+	return r.db.WithContext(ctx).
+		Where("scope = ? AND created_at < ?", scope, before).
+		Delete(&models.IdempotencyKey{}).Error
+}
+
+func (r gormIdempotencyKeys) DeleteAllExpired(ctx context.Context, before time.Time) (int, error) {
+	result := r.db.WithContext(ctx).Where("created_at < ?", before).Delete(&models.IdempotencyKey{})
+	return int(result.RowsAffected), result.Error
+}
+
+func (r gormIdempotencyKeys) DeleteByUser(ctx context.Context, userID uint, scope string) (int, error) {
+	result := r.db.WithContext(ctx).Where("user_id = ? OR scope = ?", userID, scope).Delete(&models.IdempotencyKey{})
+	return int(result.RowsAffected), result.Error
+}
+
+type gormAuditLog struct {
+	db *gorm.DB
+}
+
+func (r gormAuditLog) Append(ctx context.Context, entry *models.AuditEntry) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r gormAuditLog) ListByUser(ctx context.Context, userID uint) ([]*models.AuditEntry, error) {
+	entries := []*models.AuditEntry{}
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,703 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/antoniocfetngnu/users-api/models"
+)
+
+// ErrDuplicate is returned by the in-memory store where a database would
+// violate a unique index
+var ErrDuplicate = errors.New("duplicate key")
+
+// NewMemoryStore returns an empty Store kept in memory, for tests and local
+// runs without a database. It behaves like the GORM store: users are
+// soft-deleted, unique indexes are enforced and rows are returned as
+// copies.
+//
+// A transaction holds the store for itself until it ends, so fn must only
+// use the tx Store it is given.
+func NewMemoryStore() Store {
+	return &memoryStore{db: &memoryDB{data: &memoryData{
+		users:           map[uint]models.User{},
+		follows:         map[uint]models.Follower{},
+		idempotencyKeys: map[uint]models.IdempotencyKey{},
+	}}}
+}
+
+type memoryDB struct {
+	mu   sync.Mutex
+	data *memoryData
+}
+
+// memoryData holds the tables. Rows are stored by value and replaced, never
+// changed in place, so a shallow clone is a snapshot.
+type memoryData struct {
+	users           map[uint]models.User
+	follows         map[uint]models.Follower
+	followEvents    []models.FollowEvent
+	userChanges     []models.UserChange
+	idempotencyKeys map[uint]models.IdempotencyKey
+	auditEntries    []models.AuditEntry
+	lastID          struct{ user, follow, followEvent, idempotencyKey uint }
+}
+
+func (d *memoryData) clone() *memoryData {
+	c := *d
+	c.users = maps.Clone(d.users)
+	c.follows = maps.Clone(d.follows)
+	c.followEvents = slices.Clone(d.followEvents)
+	c.userChanges = slices.Clone(d.userChanges)
+	c.idempotencyKeys = maps.Clone(d.idempotencyKeys)
+	c.auditEntries = slices.Clone(d.auditEntries)
+	return &c
+}
+
+type memoryStore struct {
+	db   *memoryDB
+	inTx bool
+}
+
+func (s *memoryStore) Users() UserRepository                     { return memoryUsers{s} }
+func (s *memoryStore) Follows() FollowRepository                 { return memoryFollows{s} }
+func (s *memoryStore) UserChanges() UserChangeRepository         { return memoryUserChanges{s} }
+func (s *memoryStore) IdempotencyKeys() IdempotencyKeyRepository { return memoryIdempotencyKeys{s} }
+func (s *memoryStore) AuditLog() AuditRepository                 { return memoryAuditLog{s} }
+
+func (s *memoryStore) Transaction(ctx context.Context, fn func(tx Store) error) (err error) {
+	if !s.inTx {
+		s.db.mu.Lock()
+		defer s.db.mu.Unlock()
+	}
+
+	// Roll back to the snapshot unless fn succeeds, also when it panics
+	snapshot := s.db.data.clone()
+	committed := false
+	defer func() {
+		if !committed {
+			s.db.data = snapshot
+		}
+	}()
+
+	if err := fn(&memoryStore{db: s.db, inTx: true}); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// view runs fn with the tables, locking them outside of a transaction
+func (s *memoryStore) view(fn func(d *memoryData)) {
+	if !s.inTx {
+		s.db.mu.Lock()
+		defer s.db.mu.Unlock()
+	}
+	fn(s.db.data)
+}
+
+// windowOf applies w to rows sorted by ID
+func windowOf[T any](rows []T, id func(T) uint, w Window) []T {
+	windowed := make([]T, 0, len(rows))
+	for _, row := range rows {
+		if (w.AfterID == nil || id(row) > *w.AfterID) && (w.BeforeID == nil || id(row) < *w.BeforeID) {
+			windowed = append(windowed, row)
+		}
+	}
+	if w.Descending {
+		slices.Reverse(windowed)
+	}
+	if w.Limit > 0 && len(windowed) > w.Limit {
+		windowed = windowed[:w.Limit]
+	}
+	return windowed
+}
+
+// sortedValues returns the rows of a table ordered by ID
+func sortedValues[T any](table map[uint]T) []T {
+	ids := slices.Sorted(maps.Keys(table))
+	rows := make([]T, len(ids))
+	for i, id := range ids {
+		rows[i] = table[id]
+	}
+	return rows
+}
+
+type memoryUsers struct {
+	s *memoryStore
+}
+
+// liveUsers returns the users that aren't soft-deleted, ordered by ID
+func (d *memoryData) liveUsers() []models.User {
+	var users []models.User
+	for _, user := range sortedValues(d.users) {
+		if !user.DeletedAt.Valid {
+			users = append(users, user)
+		}
+	}
+	return users
+}
+
+// findUser returns a copy of the first live user matching match
+func (r memoryUsers) findUser(match func(models.User) bool) (*models.User, error) {
+	var found *models.User
+	r.s.view(func(d *memoryData) {
+		for _, user := range d.liveUsers() {
+			if match(user) {
+				found = &user
+				return
+			}
+		}
+	})
+	if found == nil {
+		return nil, ErrNotFound
+	}
+	return found, nil
+}
+
+func (r memoryUsers) Get(ctx context.Context, id uint) (*models.User, error) {
+	return r.findUser(func(u models.User) bool { return u.ID == id })
+}
+
+func (r memoryUsers) GetWithDeleted(ctx context.Context, id uint) (found *models.User, err error) {
+	r.s.view(func(d *memoryData) {
+		if user, ok := d.users[id]; ok {
+			found = &user
+		}
+	})
+	if found == nil {
+		return nil, ErrNotFound
+	}
+	return found, nil
+}
+
+func (r memoryUsers) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.findUser(func(u models.User) bool { return u.Username == username })
+}
+
+func (r memoryUsers) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.findUser(func(u models.User) bool { return u.Email == email })
+}
+
+// filterUsers returns copies of the live users matching match
+func (r memoryUsers) filterUsers(match func(models.User) bool) []*models.User {
+	users := []*models.User{}
+	r.s.view(func(d *memoryData) {
+		for _, user := range d.liveUsers() {
+			if match(user) {
+				users = append(users, &user)
+			}
+		}
+	})
+	return users
+}
+
+func (r memoryUsers) GetByIDs(ctx context.Context, ids []uint) ([]*models.User, error) {
+	return r.filterUsers(func(u models.User) bool { return slices.Contains(ids, u.ID) }), nil
+}
+
+func (r memoryUsers) GetByUsernames(ctx context.Context, usernames []string) ([]*models.User, error) {
+	return r.filterUsers(func(u models.User) bool { return slices.Contains(usernames, u.Username) }), nil
+}
+
+func (r memoryUsers) List(ctx context.Context, query string, w Window) ([]*models.User, int, error) {
+	query = strings.ToLower(query)
+	users := r.filterUsers(func(u models.User) bool {
+		return strings.Contains(strings.ToLower(u.FirstName), query) ||
+			strings.Contains(strings.ToLower(u.LastName), query) ||
+			strings.Contains(strings.ToLower(u.Username), query)
+	})
+	return windowOf(users, func(u *models.User) uint { return u.ID }, w), len(users), nil
+}
+
+func (r memoryUsers) Taken(ctx context.Context, username, email string, exceptID uint) (bool, error) {
+	users := r.filterUsers(func(u models.User) bool {
+		return (u.Username == username || u.Email == email) && u.ID != exceptID
+	})
+	return len(users) > 0, nil
+}
+
+// checkUnique enforces the unique username and email indexes, which only
+// cover live users
+func (d *memoryData) checkUnique(user *models.User) error {
+	for _, other := range d.liveUsers() {
+		if other.ID != user.ID && (other.Username == user.Username || other.Email == user.Email) {
+			return ErrDuplicate
+		}
+	}
+	return nil
+}
+
+func (r memoryUsers) Create(ctx context.Context, user *models.User) (err error) {
+	r.s.view(func(d *memoryData) {
+		if err = d.checkUnique(user); err != nil {
+			return
+		}
+
+		now := time.Now()
+		d.lastID.user++
+		user.ID = d.lastID.user
+		if user.Role == "" {
+			user.Role = models.RoleUser
+		}
+		if user.CreatedAt.IsZero() {
+			user.CreatedAt = now
+		}
+		user.UpdatedAt = now
+		d.users[user.ID] = *user
+	})
+	return err
+}
+
+func (r memoryUsers) Update(ctx context.Context, user *models.User) (err error) {
+	r.s.view(func(d *memoryData) {
+		if _, ok := d.users[user.ID]; !ok {
+			err = ErrNotFound
+			return
+		}
+		if err = d.checkUnique(user); err != nil {
+			return
+		}
+
+		user.UpdatedAt = time.Now()
+		d.users[user.ID] = *user
+	})
+	return err
+}
+
+func (r memoryUsers) Delete(ctx context.Context, id uint) error {
+	r.s.view(func(d *memoryData) {
+		if user, ok := d.users[id]; ok && !user.DeletedAt.Valid {
+			user.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+			d.users[id] = user
+		}
+	})
+	return nil
+}
+
+func (r memoryUsers) Restore(ctx context.Context, id uint) (err error) {
+	r.s.view(func(d *memoryData) {
+		user, ok := d.users[id]
+		if !ok || !user.DeletedAt.Valid {
+			return
+		}
+		if err = d.checkUnique(&user); err != nil {
+			return
+		}
+		user.DeletedAt = gorm.DeletedAt{}
+		d.users[id] = user
+	})
+	return err
+}
+
+func (r memoryUsers) ListDeletedBefore(ctx context.Context, before time.Time, limit int) ([]*models.User, error) {
+	users := []*models.User{}
+	r.s.view(func(d *memoryData) {
+		for _, user := range sortedValues(d.users) {
+			if user.DeletedAt.Valid && user.DeletedAt.Time.Before(before) && user.ErasedAt == nil {
+				users = append(users, &user)
+			}
+		}
+	})
+	slices.SortStableFunc(users, func(a, b *models.User) int { return a.DeletedAt.Time.Compare(b.DeletedAt.Time) })
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+func (r memoryUsers) Purge(ctx context.Context, id uint) (err error) {
+	r.s.view(func(d *memoryData) {
+		if _, ok := d.users[id]; !ok {
+			err = ErrNotFound
+			return
+		}
+		delete(d.users, id)
+	})
+	return err
+}
+
+func (r memoryUsers) Erase(ctx context.Context, user *models.User) (err error) {
+	r.s.view(func(d *memoryData) {
+		if _, ok := d.users[user.ID]; !ok {
+			err = ErrNotFound
+			return
+		}
+		user.UpdatedAt = time.Now()
+		d.users[user.ID] = *user
+	})
+	return err
+}
+
+type memoryFollows struct {
+	s *memoryStore
+}
+
+// findEdge returns a copy of the first edge matching match
+func (r memoryFollows) findEdge(match func(models.Follower) bool) (*models.Follower, error) {
+	var found *models.Follower
+	r.s.view(func(d *memoryData) {
+		for _, edge := range sortedValues(d.follows) {
+			if match(edge) {
+				found = &edge
+				return
+			}
+		}
+	})
+	if found == nil {
+		return nil, ErrNotFound
+	}
+	return found, nil
+}
+
+// filterEdges returns copies of the edges matching match, oldest first
+func (r memoryFollows) filterEdges(match func(models.Follower) bool) []*models.Follower {
+	edges := []*models.Follower{}
+	r.s.view(func(d *memoryData) {
+		for _, edge := range sortedValues(d.follows) {
+			if match(edge) {
+				edges = append(edges, &edge)
+			}
+		}
+	})
+	return edges
+}
+
+func (r memoryFollows) Get(ctx context.Context, id uint) (*models.Follower, error) {
+	return r.findEdge(func(e models.Follower) bool { return e.ID == id })
+}
+
+func (r memoryFollows) GetPair(ctx context.Context, pair FollowPair) (*models.Follower, error) {
+	return r.findEdge(func(e models.Follower) bool {
+		return e.FollowerID == pair.FollowerID && e.FollowedID == pair.FollowedID
+	})
+}
+
+func (r memoryFollows) Create(ctx context.Context, edge *models.Follower) (created bool, err error) {
+	r.s.view(func(d *memoryData) {
+		for _, other := range d.follows {
+			if other.FollowerID == edge.FollowerID && other.FollowedID == edge.FollowedID {
+				return
+			}
+		}
+
+		now := time.Now()
+		d.lastID.follow++
+		edge.ID = d.lastID.follow
+		edge.CreatedAt = now
+		edge.UpdatedAt = now
+
+		// Relations aren't stored, as in the database
+		stored := *edge
+		stored.Follower = models.User{}
+		stored.Followed = models.User{}
+		d.follows[edge.ID] = stored
+		created = true
+	})
+	return created, nil
+}
+
+func (r memoryFollows) Delete(ctx context.Context, pair FollowPair) (deleted *models.Follower, err error) {
+	r.s.view(func(d *memoryData) {
+		for id, edge := range d.follows {
+			if edge.FollowerID == pair.FollowerID && edge.FollowedID == pair.FollowedID {
+				delete(d.follows, id)
+				deleted = &edge
+				return
+			}
+		}
+	})
+	return deleted, nil
+}
+
+func (r memoryFollows) DeleteByUser(ctx context.Context, userID uint) ([]*models.Follower, error) {
+	return r.deleteEdges(func(d *memoryData, e models.Follower) bool {
+		return e.FollowerID == userID || e.FollowedID == userID
+	}), nil
+}
+
+func (r memoryFollows) DeleteDangling(ctx context.Context) ([]*models.Follower, error) {
+	return r.deleteEdges(func(d *memoryData, e models.Follower) bool {
+		follower, followed := d.users[e.FollowerID], d.users[e.FollowedID]
+		return follower.ID == 0 || follower.DeletedAt.Valid || followed.ID == 0 || followed.DeletedAt.Valid
+	}), nil
+}
+
+// deleteEdges removes the edges matching match and returns them, oldest
+// first
+func (r memoryFollows) deleteEdges(match func(d *memoryData, e models.Follower) bool) []*models.Follower {
+	edges := []*models.Follower{}
+	r.s.view(func(d *memoryData) {
+		for _, edge := range sortedValues(d.follows) {
+			if match(d, edge) {
+				delete(d.follows, edge.ID)
+				edges = append(edges, &edge)
+			}
+		}
+	})
+	return edges
+}
+
+func (r memoryFollows) RecordEvent(ctx context.Context, event *models.FollowEvent) error {
+	r.s.view(func(d *memoryData) {
+		d.lastID.followEvent++
+		event.ID = d.lastID.followEvent
+		event.CreatedAt = time.Now()
+		d.followEvents = append(d.followEvents, *event)
+	})
+	return nil
+}
+
+func (r memoryFollows) PseudonymizeEvents(ctx context.Context, userID uint) (changed int, err error) {
+	r.s.view(func(d *memoryData) {
+		for i := range d.followEvents {
+			event := &d.followEvents[i]
+			if event.FollowerID == userID {
+				event.FollowerID = models.ErasedUserID
+				changed++
+			}
+			if event.FollowedID == userID {
+				event.FollowedID = models.ErasedUserID
+				changed++
+			}
+		}
+	})
+	return changed, nil
+}
+
+func (r memoryFollows) ListByFollowed(ctx context.Context, userIDs []uint) ([]*models.Follower, error) {
+	return r.filterEdges(func(e models.Follower) bool { return slices.Contains(userIDs, e.FollowedID) }), nil
+}
+
+func (r memoryFollows) ListByFollower(ctx context.Context, userIDs []uint) ([]*models.Follower, error) {
+	return r.filterEdges(func(e models.Follower) bool { return slices.Contains(userIDs, e.FollowerID) }), nil
+}
+
+func (r memoryFollows) PageByFollowed(ctx context.Context, userID uint, w Window) ([]*models.Follower, int, error) {
+	edges := r.filterEdges(func(e models.Follower) bool { return e.FollowedID == userID })
+	return windowOf(edges, func(e *models.Follower) uint { return e.ID }, w), len(edges), nil
+}
+
+func (r memoryFollows) PageByFollower(ctx context.Context, userID uint, w Window) ([]*models.Follower, int, error) {
+	edges := r.filterEdges(func(e models.Follower) bool { return e.FollowerID == userID })
+	return windowOf(edges, func(e *models.Follower) uint { return e.ID }, w), len(edges), nil
+}
+
+func (r memoryFollows) Existing(ctx context.Context, pairs []FollowPair) ([]FollowPair, error) {
+	var existing []FollowPair
+	for _, edge := range r.filterEdges(func(e models.Follower) bool {
+		return slices.Contains(pairs, FollowPair{FollowerID: e.FollowerID, FollowedID: e.FollowedID})
+	}) {
+		existing = append(existing, FollowPair{FollowerID: edge.FollowerID, FollowedID: edge.FollowedID})
+	}
+	return existing, nil
+}
+
+func (r memoryFollows) FollowerIDs(ctx context.Context, userID, afterEdgeID uint, limit int) ([]uint, uint, error) {
+	edges := r.filterEdges(func(e models.Follower) bool { return e.FollowedID == userID && e.ID > afterEdgeID })
+	if limit > 0 && len(edges) > limit {
+		edges = edges[:limit]
+	}
+
+	ids := make([]uint, len(edges))
+	for i, edge := range edges {
+		ids[i] = edge.FollowerID
+	}
+	if len(edges) == 0 {
+		return ids, afterEdgeID, nil
+	}
+	return ids, edges[len(edges)-1].ID, nil
+}
+
+func (r memoryFollows) CountByFollowed(ctx context.Context, userIDs []uint) (map[uint]int, error) {
+	counts := make(map[uint]int, len(userIDs))
+	for _, edge := range r.filterEdges(func(e models.Follower) bool { return slices.Contains(userIDs, e.FollowedID) }) {
+		counts[edge.FollowedID]++
+	}
+	return counts, nil
+}
+
+func (r memoryFollows) CountByFollower(ctx context.Context, userIDs []uint) (map[uint]int, error) {
+	counts := make(map[uint]int, len(userIDs))
+	for _, edge := range r.filterEdges(func(e models.Follower) bool { return slices.Contains(userIDs, e.FollowerID) }) {
+		counts[edge.FollowerID]++
+	}
+	return counts, nil
+}
+
+type memoryUserChanges struct {
+	s *memoryStore
+}
+
+func (r memoryUserChanges) Append(ctx context.Context, userID uint, action string) (change *models.UserChange, err error) {
+	r.s.view(func(d *memoryData) {
+		change = &models.UserChange{
+			ID:        uint64(len(d.userChanges)) + 1,
+			UserID:    userID,
+			Action:    action,
+			CreatedAt: time.Now(),
+		}
+		d.userChanges = append(d.userChanges, *change)
+	})
+	return change, nil
+}
+
+func (r memoryUserChanges) ListAfter(ctx context.Context, after uint64, limit int) ([]*models.UserChange, error) {
+	var changes []*models.UserChange
+	r.s.view(func(d *memoryData) {
+		// Sequences are the positions in the log, starting at 1
+		for _, change := range d.userChanges[min(after, uint64(len(d.userChanges))):] {
+			if len(changes) == limit {
+				return
+			}
+			changes = append(changes, &change)
+		}
+	})
+	return changes, nil
+}
+
+func (r memoryUserChanges) Latest(ctx context.Context) (latest uint64, err error) {
+	r.s.view(func(d *memoryData) {
+		latest = uint64(len(d.userChanges))
+	})
+	return latest, nil
+}
+
+type memoryIdempotencyKeys struct {
+	s *memoryStore
+}
+
+func (r memoryIdempotencyKeys) Create(ctx context.Context, record *models.IdempotencyKey) (created bool, err error) {
+	r.s.view(func(d *memoryData) {
+		for _, other := range d.idempotencyKeys {
+			if other.Scope == record.Scope && other.Key == record.Key {
+				return
+			}
+		}
+
+		d.lastID.idempotencyKey++
+		record.ID = d.lastID.idempotencyKey
+		if record.CreatedAt.IsZero() {
+			record.CreatedAt = time.Now()
+		}
+		d.idempotencyKeys[record.ID] = *record
+		created = true
+	})
+	return created, nil
+}
+
+func (r memoryIdempotencyKeys) Get(ctx context.Context, scope, key string) (found *models.IdempotencyKey, err error) {
+	r.s.view(func(d *memoryData) {
+		for _, record := range d.idempotencyKeys {
+			if record.Scope == scope && record.Key == key {
+				found = &record
+				return
+			}
+		}
+	})
+	if found == nil {
+		return nil, ErrNotFound
+	}
+	return found, nil
+}
+
+func (r memoryIdempotencyKeys) SetResponse(ctx context.Context, id uint, response []byte, userID uint) error {
+	r.s.view(func(d *memoryData) {
+		if record, ok := d.idempotencyKeys[id]; ok {
+			record.Response = slices.Clone(response)
+			record.UserID = nil
+			if userID != 0 {
+				record.UserID = &userID
+			}
+			d.idempotencyKeys[id] = record
+		}
+	})
+	return nil
+}
+
+func (r memoryIdempotencyKeys) Delete(ctx context.Context, id uint) error {
+	r.s.view(func(d *memoryData) {
+		delete(d.idempotencyKeys, id)
+	})
+	return nil
+}
+
+func (r memoryIdempotencyKeys) Reclaim(ctx context.Context, id uint, claimedBefore, claimedAt time.Time) (reclaimed bool, err error) {
+	r.s.view(func(d *memoryData) {
+		record, ok := d.idempotencyKeys[id]
+		if ok && record.Response == nil && record.ClaimedAt.Before(claimedBefore) {
+			record.ClaimedAt = claimedAt
+			d.idempotencyKeys[id] = record
+			reclaimed = true
+		}
+	})
+	return reclaimed, nil
+}
+
+func (r memoryIdempotencyKeys) DeleteExpired(ctx context.Context, scope string, before time.Time) error {
+	r.s.view(func(d *memoryData) {
+		maps.DeleteFunc(d.idempotencyKeys, func(_ uint, record models.IdempotencyKey) bool {
+			return record.Scope == scope && record.CreatedAt.Before(before)
+		})
+	})
+	return nil
+}
+
+// deleteKeys removes the keys matching match and returns how many there were
+func (r memoryIdempotencyKeys) deleteKeys(match func(models.IdempotencyKey) bool) (deleted int) {
+	r.s.view(func(d *memoryData) {
+		maps.DeleteFunc(d.idempotencyKeys, func(_ uint, record models.IdempotencyKey) bool {
+			if match(record) {
+				deleted++
+				return true
+			}
+			return false
+		})
+	})
+	return deleted
+}
+
+func (r memoryIdempotencyKeys) DeleteAllExpired(ctx context.Context, before time.Time) (int, error) {
+	return r.deleteKeys(func(record models.IdempotencyKey) bool { return record.CreatedAt.Before(before) }), nil
+}
+
+func (r memoryIdempotencyKeys) DeleteByUser(ctx context.Context, userID uint, scope string) (int, error) {
+	return r.deleteKeys(func(record models.IdempotencyKey) bool {
+		return (record.UserID != nil && *record.UserID == userID) || record.Scope == scope
+	}), nil
+}
+
+type memoryAuditLog struct {
+	s *memoryStore
+}
+
+func (r memoryAuditLog) Append(ctx context.Context, entry *models.AuditEntry) error {
+	r.s.view(func(d *memoryData) {
+		entry.ID = uint(len(d.auditEntries)) + 1
+		if entry.Details == "" {
+			entry.Details = "{}"
+		}
+		entry.CreatedAt = time.Now()
+		d.auditEntries = append(d.auditEntries, *entry)
+	})
+	return nil
+}
+
+func (r memoryAuditLog) ListByUser(ctx context.Context, userID uint) ([]*models.AuditEntry, error) {
+	entries := []*models.AuditEntry{}
+	r.s.view(func(d *memoryData) {
+		for _, entry := range d.auditEntries {
+			if entry.UserID != nil && *entry.UserID == userID {
+				entries = append(entries, &entry)
+			}
+		}
+	})
+	return entries, nil
+}
@@ -0,0 +1,189 @@
+// Package repository is the persistence layer of the services: one
+// interface per table group, so the business rules in package services
+// don't depend on how (or where) rows are stored.
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/antoniocfetngnu/users-api/models"
+)
+
+// ErrNotFound is returned by single-row lookups that match nothing
+var ErrNotFound = errors.New("record not found")
+
+// Store gives access to every repository
+type Store interface {
+	Users() UserRepository
+	Follows() FollowRepository
+	UserChanges() UserChangeRepository
+	IdempotencyKeys() IdempotencyKeyRepository
+	AuditLog() AuditRepository
+
+	// Transaction runs fn with a Store whose writes are committed together
+	// if fn returns nil, and rolled back otherwise
+	Transaction(ctx context.Context, fn func(tx Store) error) error
+}
+
+// Window selects rows of a list ordered by ID (keyset pagination)
+type Window struct {
+	// Only rows with an ID strictly between AfterID and BeforeID (when set)
+	AfterID  *uint
+	BeforeID *uint
+	// At most Limit rows, 0 for all of them
+	Limit int
+	// Descending reads the newest rows first
+	Descending bool
+}
+
+// FollowPair identifies a (possible) follow edge
+type FollowPair struct {
+	FollowerID uint
+	FollowedID uint
+}
+
+// UserRepository stores users. Soft-deleted users are never returned, but
+// by GetWithDeleted.
+type UserRepository interface {
+	Get(ctx context.Context, id uint) (*models.User, error)
+	GetWithDeleted(ctx context.Context, id uint) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+
+	// GetByIDs and GetByUsernames return the matching users in no
+	// particular order, skipping keys without a user
+	GetByIDs(ctx context.Context, ids []uint) ([]*models.User, error)
+	GetByUsernames(ctx context.Context, usernames []string) ([]*models.User, error)
+
+	// List returns the users in window whose first name, last name or
+	// username contains query (case-insensitive, "" matches everyone), and
+	// how many users match query in total
+	List(ctx context.Context, query string, window Window) ([]*models.User, int, error)
+
+	// Taken reports whether a user other than exceptID already has the
+	// username or the email
+	Taken(ctx context.Context, username, email string, exceptID uint) (bool, error)
+
+	Create(ctx context.Context, user *models.User) error
+	Update(ctx context.Context, user *models.User) error
+	Delete(ctx context.Context, id uint) error
+
+	// Restore undoes the soft delete of a user. Usernames and emails are
+	// only unique among live users, so it can fail like Create.
+	Restore(ctx context.Context, id uint) error
+
+	// ListDeletedBefore returns up to limit users soft-deleted before the
+	// given time, longest deleted first. Erased users are left out: their
+	// tombstones are kept.
+	ListDeletedBefore(ctx context.Context, before time.Time, limit int) ([]*models.User, error)
+
+	// Purge removes the row of a user for good, deleted or not. It returns
+	// ErrNotFound when there is no row, also when a concurrent transaction
+	// purged it first.
+	Purge(ctx context.Context, id uint) error
+
+	// Erase saves a user scrubbed by models.User.Erase, deleted or not
+	Erase(ctx context.Context, user *models.User) error
+}
+
+// FollowRepository stores follow edges and their history
+type FollowRepository interface {
+	Get(ctx context.Context, id uint) (*models.Follower, error)
+	GetPair(ctx context.Context, pair FollowPair) (*models.Follower, error)
+
+	// Create inserts the edge unless its pair already exists, created
+	// reports which. It is safe against concurrent inserts of the same pair.
+	Create(ctx context.Context, edge *models.Follower) (created bool, err error)
+
+	// Delete removes the edge of pair and returns it, or nil if there was
+	// none
+	Delete(ctx context.Context, pair FollowPair) (*models.Follower, error)
+
+	// DeleteByUser removes every edge from or to userID and returns them
+	DeleteByUser(ctx context.Context, userID uint) ([]*models.Follower, error)
+
+	// DeleteDangling removes the edges from or to users that are deleted
+	// or gone, and returns them
+	DeleteDangling(ctx context.Context) ([]*models.Follower, error)
+
+	// RecordEvent appends to the follow history
+	RecordEvent(ctx context.Context, event *models.FollowEvent) error
+
+	// PseudonymizeEvents replaces userID with models.ErasedUserID in the
+	// follow history and returns how many events changed
+	PseudonymizeEvents(ctx context.Context, userID uint) (int, error)
+
+	// ListByFollowed and ListByFollower return the edges whose followed
+	// (following) user is one of userIDs, oldest first
+	ListByFollowed(ctx context.Context, userIDs []uint) ([]*models.Follower, error)
+	ListByFollower(ctx context.Context, userIDs []uint) ([]*models.Follower, error)
+
+	// PageByFollowed and PageByFollower return the edges of userID in
+	// window, and how many there are in total
+	PageByFollowed(ctx context.Context, userID uint, window Window) ([]*models.Follower, int, error)
+	PageByFollower(ctx context.Context, userID uint, window Window) ([]*models.Follower, int, error)
+
+	// Existing returns the pairs that are follow edges
+	Existing(ctx context.Context, pairs []FollowPair) ([]FollowPair, error)
+
+	// FollowerIDs returns the IDs of the users following userID through
+	// edges with an ID after afterEdgeID, oldest edge first, at most limit
+	// of them (0 for all), and the ID of the last edge read
+	FollowerIDs(ctx context.Context, userID, afterEdgeID uint, limit int) (ids []uint, lastEdgeID uint, err error)
+
+	// CountByFollowed and CountByFollower count the edges of each of
+	// userIDs (users without edges are left out)
+	CountByFollowed(ctx context.Context, userIDs []uint) (map[uint]int, error)
+	CountByFollower(ctx context.Context, userIDs []uint) (map[uint]int, error)
+}
+
+// UserChangeRepository stores the append-only log of user changes
+type UserChangeRepository interface {
+	// Append logs a change. Sequences must become visible in order, so
+	// implementations serialize concurrent appends.
+	Append(ctx context.Context, userID uint, action string) (*models.UserChange, error)
+
+	// ListAfter returns up to limit changes with a sequence after the given
+	// one, oldest first
+	ListAfter(ctx context.Context, after uint64, limit int) ([]*models.UserChange, error)
+
+	// Latest returns the sequence of the newest change, 0 if there is none
+	Latest(ctx context.Context) (uint64, error)
+}
+
+// IdempotencyKeyRepository stores the idempotency keys of write RPCs
+type IdempotencyKeyRepository interface {
+	// Create inserts the key unless (scope, key) already exists, created
+	// reports which
+	Create(ctx context.Context, record *models.IdempotencyKey) (created bool, err error)
+	Get(ctx context.Context, scope, key string) (*models.IdempotencyKey, error)
+	// SetResponse stores the response of a call, about userID (0 for no
+	// user)
+	SetResponse(ctx context.Context, id uint, response []byte, userID uint) error
+	Delete(ctx context.Context, id uint) error
+
+	// Reclaim moves ClaimedAt of a key without a response to claimedAt if
+	// it was claimed before claimedBefore, and reports whether it did
+	Reclaim(ctx context.Context, id uint, claimedBefore, claimedAt time.Time) (bool, error)
+
+	// DeleteExpired removes the keys of scope created before the given time
+	DeleteExpired(ctx context.Context, scope string, before time.Time) error
+
+	// DeleteAllExpired removes the keys of every scope created before the
+	// given time and returns how many there were
+	DeleteAllExpired(ctx context.Context, before time.Time) (int, error)
+
+	// DeleteByUser removes the keys whose response is about userID and the
+	// keys of scope, and returns how many there were
+	DeleteByUser(ctx context.Context, userID uint, scope string) (int, error)
+}
+
+// AuditRepository stores the append-only audit log of operator actions
+type AuditRepository interface {
+	Append(ctx context.Context, entry *models.AuditEntry) error
+
+	// ListByUser returns the entries about userID, oldest first
+	ListByUser(ctx context.Context, userID uint) ([]*models.AuditEntry, error)
+}
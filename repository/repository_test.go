@@ -0,0 +1,26 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/antoniocfetngnu/users-api/repository"
+	"github.com/antoniocfetngnu/users-api/repository/repositorytest"
+)
+
+func TestMemoryStore(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.Store {
+		return repository.NewMemoryStore()
+	})
+}
+
+func TestGormStoreOnSQLite(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.Store {
+		return repositorytest.NewSQLiteStore(t)
+	})
+}
+
+func TestGormStoreOnPostgres(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.Store {
+		return repositorytest.NewPostgresStore(t)
+	})
+}
@@ -0,0 +1,68 @@
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/antoniocfetngnu/users-api/database"
+	"github.com/antoniocfetngnu/users-api/repository"
+)
+
+// OpenPostgres returns the database at TEST_DATABASE_URL, in a schema of
+// its own built by the embedded SQL migrations and dropped when the test
+// ends. Tests using it are skipped without TEST_DATABASE_URL.
+func OpenPostgres(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	gormConfig := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+
+	admin, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		sqlDB, _ := admin.DB()
+		sqlDB.Close()
+	})
+
+	// public still resolves extensions installed there, such as pg_trgm
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	connConfig.RuntimeParams["search_path"] = schema + ", public"
+	sqlDB := stdlib.OpenDB(*connConfig)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), gormConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.MigrateUp(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// NewPostgresStore returns the GORM store over OpenPostgres(t)
+func NewPostgresStore(t testing.TB) repository.Store {
+	return repository.NewGormStore(OpenPostgres(t))
+}
@@ -0,0 +1,47 @@
+// Package repositorytest provides the conformance suite every
+// repository.Store must pass, and SQLite databases for hermetic tests.
+package repositorytest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/repository"
+)
+
+// OpenSQLite returns a fresh in-memory SQLite database, closed when the
+// test ends. Connections of the same test share it, so it also works from
+// other goroutines. The SQL migrations are Postgres-only, so the tables are
+// created from the models; database.TestMigrationsMatchModels keeps the
+// two in step, and OpenPostgres runs the migrations themselves.
+func OpenSQLite(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", name)), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Follower{}, &models.FollowEvent{}, &models.UserChange{}, &models.IdempotencyKey{}, &models.AuditEntry{}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	})
+	return db
+}
+
+// NewSQLiteStore returns the GORM store over OpenSQLite(t)
+func NewSQLiteStore(t testing.TB) repository.Store {
+	return repository.NewGormStore(OpenSQLite(t))
+}
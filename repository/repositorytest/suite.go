@@ -0,0 +1,707 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/repository"
+)
+
+// Run checks that the stores returned by newStore behave as the
+// repository interfaces document. Every subtest gets an empty store.
+func Run(t *testing.T, newStore func(t *testing.T) repository.Store) {
+	tests := map[string]func(t *testing.T, store repository.Store){
+		"Users":           testUsers,
+		"UserList":        testUserList,
+		"UserDelete":      testUserDelete,
+		"UserRestore":     testUserRestore,
+		"UserErase":       testUserErase,
+		"Follows":         testFollows,
+		"FollowLists":     testFollowLists,
+		"FollowCleanup":   testFollowCleanup,
+		"AuditLog":        testAuditLog,
+		"UserChanges":     testUserChanges,
+		"IdempotencyKeys": testIdempotencyKeys,
+		"Transaction":     testTransaction,
+	}
+	for _, name := range slices.Sorted(maps.Keys(tests)) {
+		t.Run(name, func(t *testing.T) {
+			tests[name](t, newStore(t))
+		})
+	}
+}
+
+// createUsers creates user1..userN and returns them
+func createUsers(t *testing.T, store repository.Store, n int) []*models.User {
+	t.Helper()
+
+	users := make([]*models.User, n)
+	for i := range users {
+		name := fmt.Sprintf("user%d", i+1)
+		users[i] = &models.User{FirstName: name, LastName: "Doe", Email: name + "@example.com", Username: name, Password: "x"}
+		if err := store.Users().Create(context.Background(), users[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return users
+}
+
+// follow creates the edges follower -> followed
+func follow(t *testing.T, store repository.Store, pairs ...repository.FollowPair) []*models.Follower {
+	t.Helper()
+
+	edges := make([]*models.Follower, len(pairs))
+	for i, pair := range pairs {
+		edges[i] = &models.Follower{FollowerID: pair.FollowerID, FollowedID: pair.FollowedID, FollowedSince: time.Now()}
+		if created, err := store.Follows().Create(context.Background(), edges[i]); err != nil || !created {
+			t.Fatalf("Create(%v) = %v, %v", pair, created, err)
+		}
+	}
+	return edges
+}
+
+func userIDs(users []*models.User) []uint {
+	ids := make([]uint, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	return ids
+}
+
+func edgeIDs(edges []*models.Follower) []uint {
+	ids := make([]uint, len(edges))
+	for i, edge := range edges {
+		ids[i] = edge.ID
+	}
+	return ids
+}
+
+func testUsers(t *testing.T, store repository.Store) {
+	ctx := context.Background()
+	users := store.Users()
+	created := createUsers(t, store, 2)
+
+	user := created[0]
+	if user.ID == 0 || user.CreatedAt.IsZero() || user.UpdatedAt.IsZero() || user.Role != models.RoleUser {
+		t.Fatalf("Create didn't fill in the defaults: %+v", user)
+	}
+
+	got, err := users.Get(ctx, user.ID)
+	if err != nil || got.Username != "user1" || got.Email != "user1@example.com" {
+		t.Fatalf("Get = %+v, %v", got, err)
+	}
+	if got, err := users.GetByUsername(ctx, "user2"); err != nil || got.ID != created[1].ID {
+		t.Fatalf("GetByUsername = %+v, %v", got, err)
+	}
+	if got, err := users.GetByEmail(ctx, "user2@example.com"); err != nil || got.ID != created[1].ID {
+		t.Fatalf("GetByEmail = %+v, %v", got, err)
+	}
+	for name, lookup := range map[string]func() (*models.User, error){
+		"Get":           func() (*models.User, error) { return users.Get(ctx, 999) },
+		"GetByUsername": func() (*models.User, error) { return users.GetByUsername(ctx, "nobody") },
+		"GetByEmail":    func() (*models.User, error) { return users.GetByEmail(ctx, "nobody@example.com") },
+	} {
+		if _, err := lookup(); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("%s of a missing user = %v, want ErrNotFound", name, err)
+		}
+	}
+
+	// Returned rows are copies
+	got.Email = "changed@example.com"
+	if again, _ := users.Get(ctx, user.ID); again.Email != "user1@example.com" {
+		t.Fatal("changing a returned user changed the stored one")
+	}
+
+	byIDs, err := users.GetByIDs(ctx, []uint{created[1].ID, 999, user.ID})
+	if err != nil || len(byIDs) != 2 {
+		t.Fatalf("GetByIDs = %v, %v", byIDs, err)
+	}
+	byNames, err := users.GetByUsernames(ctx, []string{"user2", "nobody"})
+	if err != nil || len(byNames) != 1 || byNames[0].ID != created[1].ID {
+		t.Fatalf("GetByUsernames = %v, %v", byNames, err)
+	}
+	if empty, err := users.GetByIDs(ctx, nil); err != nil || len(empty) != 0 {
+		t.Fatalf("GetByIDs(nil) = %v, %v", empty, err)
+	}
+
+	duplicate := &models.User{FirstName: "x", LastName: "x", Email: "other@example.com", Username: "user1", Password: "x"}
+	if err := users.Create(ctx, duplicate); err == nil {
+		t.Fatal("created a second user1")
+	}
+
+	for _, tt := range []struct {
+		username, email string
+		except          uint
+		want            bool
+	}{
+		{"user1", "new@example.com", 0, true},
+		{"new", "user1@example.com", 0, true},
+		{"user1", "user1@example.com", user.ID, false},
+		{"new", "new@example.com", 0, false},
+	} {
+		if taken, err := users.Taken(ctx, tt.username, tt.email, tt.except); err != nil || taken != tt.want {
+			t.Errorf("Taken(%s, %s, %d) = %v, %v", tt.username, tt.email, tt.except, taken, err)
+		}
+	}
+
+	user.FirstName = "Ada"
+	if err := users.Update(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := users.Get(ctx, user.ID); got.FirstName != "Ada" {
+		t.Fatalf("Update wasn't stored: %+v", got)
+	}
+}
+
+func testUserList(t *testing.T, store repository.Store) {
+	ctx := context.Background()
+	created := createUsers(t, store, 5)
+	ids := userIDs(created)
+
+	all, total, err := store.Users().List(ctx, "", repository.Window{})
+	if err != nil || total != 5 || !slices.Equal(userIDs(all), ids) {
+		t.Fatalf("List = %v, %d, %v", userIDs(all), total, err)
+	}
+
+	for name, tt := range map[string]struct {
+		window repository.Window
+		want   []uint
+	}{
+		"limit":      {repository.Window{Limit: 2}, ids[:2]},
+		"after":      {repository.Window{AfterID: &ids[1], Limit: 2}, ids[2:4]},
+		"before":     {repository.Window{BeforeID: &ids[3]}, ids[:3]},
+		"descending": {repository.Window{BeforeID: &ids[4], Limit: 2, Descending: true}, []uint{ids[3], ids[2]}},
+	} {
+		page, total, err := store.Users().List(ctx, "", tt.window)
+		if err != nil || total != 5 || !slices.Equal(userIDs(page), tt.want) {
+			t.Errorf("%s: List = %v, %d, %v, want %v", name, userIDs(page), total, err, tt.want)
+		}
+	}
+
+	created[2].LastName = "Lovelace"
+	if err := store.Users().Update(ctx, created[2]); err != nil {
+		t.Fatal(err)
+	}
+	for _, query := range []string{"LOVE", "user3", "ER3"} {
+		found, total, err := store.Users().List(ctx, query, repository.Window{})
+		if err != nil || total != 1 || len(found) != 1 || found[0].ID != ids[2] {
+			t.Errorf("List(%q) = %v, %d, %v", query, userIDs(found), total, err)
+		}
+	}
+}
+
+func testUserDelete(t *testing.T, store repository.Store) {
+	ctx := context.Background()
+	users := store.Users()
+	created := createUsers(t, store, 2)
+	deleted := created[0]
+
+	if err := users.Delete(ctx, deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := users.Get(ctx, deleted.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Get of a deleted user = %v, want ErrNotFound", err)
+	}
+	if _, err := users.GetByUsername(ctx, deleted.Username); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("GetByUsername of a deleted user = %v, want ErrNotFound", err)
+	}
+	if found, _ := users.GetByIDs(ctx, []uint{deleted.ID}); len(found) != 0 {
+		t.Error("GetByIDs returned a deleted user")
+	}
+	if all, total, _ := users.List(ctx, "", repository.Window{}); total != 1 || len(all) != 1 {
+		t.Errorf("List = %v, %d, want only the other user", userIDs(all), total)
+	}
+	if taken, _ := users.Taken(ctx, deleted.Username, deleted.Email, 0); taken {
+		t.Error("a deleted user's username is still taken")
+	}
+	if err := users.Delete(ctx, deleted.ID); err != nil {
+		t.Errorf("deleting twice = %v", err)
+	}
+	if got, err := users.GetWithDeleted(ctx, deleted.ID); err != nil || got.Username != deleted.Username || !got.DeletedAt.Valid {
+		t.Errorf("GetWithDeleted = %+v, %v", got, err)
+	}
+}
+
+func testUserRestore(t *testing.T, store repository.Store) {
+	ctx := context.Background()
+	users := store.Users()
+	created := createUsers(t, store, 3)
+
+	if err := users.Delete(ctx, created[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := users.Restore(ctx, created[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := users.Get(ctx, created[0].ID); err != nil || got.DeletedAt.Valid {
+		t.Fatalf("Get of a restored user = %+v, %v", got, err)
+	}
+
+	// Oldest deletion first
+	before := time.Now()
+	for _, user := range []*models.User{created[1], created[0]} {
+		if err := users.Delete(ctx, user.ID); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if deleted, err := users.ListDeletedBefore(ctx, time.Now(), 10); err != nil || !slices.Equal(userIDs(deleted), []uint{created[1].ID, created[0].ID}) {
+		t.Fatalf("ListDeletedBefore = %v, %v", userIDs(deleted), err)
+	}
+	if deleted, _ := users.ListDeletedBefore(ctx, time.Now(), 1); len(deleted) != 1 {
+		t.Fatalf("ListDeletedBefore with a limit = %v", userIDs(deleted))
+	}
+	if deleted, _ := users.ListDeletedBefore(ctx, before, 10); len(deleted) != 0 {
+		t.Fatalf("ListDeletedBefore an earlier time = %v", userIDs(deleted))
+	}
+
+	// Usernames and emails of deleted users can be taken again, and then
+	// the deleted user can't be restored
+	again := createUsers(t, store, 1)[0]
+	if again.Username != created[0].Username {
+		t.Fatalf("created %s", again.Username)
+	}
+	if err := users.Restore(ctx, created[0].ID); err == nil {
+		t.Fatal("restored a user whose username is taken")
+	}
+
+	// Purge works on live and deleted users alike
+	for _, user := range append(created, again) {
+		if err := users.Purge(ctx, user.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := users.GetWithDeleted(ctx, user.ID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("GetWithDeleted of a purged user = %v, want ErrNotFound", err)
+		}
+		if err := users.Purge(ctx, user.ID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Purge of a purged user = %v, want ErrNotFound", err)
+		}
+	}
+}
+
+func testUserErase(t *testing.T, store repository.Store) {
+	ctx := context.Background()
+	users := store.Users()
+	created := createUsers(t, store, 2)
+
+	// A live user and a deleted one
+	if err := users.Delete(ctx, created[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, user := range created {
+		user, err := users.GetWithDeleted(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		user.Erase(time.Now())
+		if err := users.Erase(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, user := range created {
+		if _, err := users.Get(ctx, user.ID); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Get of an erased user = %v, want ErrNotFound", err)
+		}
+		erased, err := users.GetWithDeleted(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if erased.ErasedAt == nil || !erased.DeletedAt.Valid || erased.Email != "" || erased.FirstName != "" || erased.Username != models.TombstoneUsername(user.ID) {
+			t.Errorf("erased user = %+v", erased)
+		}
+	}
+
+	// Tombstones are never purged by age, and free the username and email
+	if deleted, err := users.ListDeletedBefore(ctx, time.Now(), 10); err != nil || len(deleted) != 0 {
+		t.Fatalf("ListDeletedBefore = %v, %v", userIDs(deleted), err)
+	}
+	if again := createUsers(t, store, 1)[0]; again.Username != created[0].Username {
+		t.Fatalf("created %s", again.Username)
+	}
+	if err := users.Erase(ctx, &models.User{ID: 999}); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("Erase of a missing user = %v", err)
+	}
+}
+
+func testFollows(t *testing.T, store repository.Store) {
+	ctx := context.Background()
+	follows := store.Follows()
+	created := createUsers(t, store, 2)
+	pair := repository.FollowPair{FollowerID: created[0].ID, FollowedID: created[1].ID}
+
+	edge := follow(t, store, pair)[0]
+	if edge.ID == 0 || edge.CreatedAt.IsZero() {
+		t.Fatalf("Create didn't fill in the edge: %+v", edge)
+	}
+
+	again := &models.Follower{FollowerID: pair.FollowerID, FollowedID: pair.FollowedID, FollowedSince: time.Now()}
+	if created, err := follows.Create(ctx, again); err != nil || created {
+		t.Fatalf("creating the pair twice = %v, %v", created, err)
+	}
+
+	if got, err := follows.Get(ctx, edge.ID); err != nil || got.FollowerID != pair.FollowerID || got.FollowedID != pair.FollowedID {
+		t.Fatalf("Get = %+v, %v", got, err)
+	}
+	if got, err := follows.GetPair(ctx, pair); err != nil || got.ID != edge.ID {
+		t.Fatalf("GetPair = %+v, %v", got, err)
+	}
+	reverse := repository.FollowPair{FollowerID: pair.FollowedID, FollowedID: pair.FollowerID}
+	if _, err := follows.GetPair(ctx, reverse); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("GetPair of the reverse pair = %v, want ErrNotFound", err)
+	}
+	if _, err := follows.Get(ctx, 999); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("Get of a missing edge = %v, want ErrNotFound", err)
+	}
+
+	if err := follows.RecordEvent(ctx, &models.FollowEvent{FollowerID: pair.FollowerID, FollowedID: pair.FollowedID, Action: models.FollowActionFollow}); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := follows.Delete(ctx, pair)
+	if err != nil || removed == nil || removed.ID != edge.ID {
+		t.Fatalf("Delete = %+v, %v", removed, err)
+	}
+	if removed, err := follows.Delete(ctx, pair); err != nil || removed != nil {
+		t.Fatalf("deleting twice = %+v, %v", removed, err)
+	}
+	if _, err := follows.GetPair(ctx, pair); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("GetPair after Delete = %v, want ErrNotFound", err)
+	}
+
+	// The pair can follow again
+	follow(t, store, pair)
+}
+
+func testFollowLists(t *testing.T, store repository.Store) {
+	ctx := context.Background()
+	follows := store.Follows()
+	ids := userIDs(createUsers(t, store, 4))
+
+	// Everyone follows user1, user1 follows user2
+	edges := follow(t, store,
+		repository.FollowPair{FollowerID: ids[1], FollowedID: ids[0]},
+		repository.FollowPair{FollowerID: ids[2], FollowedID: ids[0]},
+		repository.FollowPair{FollowerID: ids[3], FollowedID: ids[0]},
+		repository.FollowPair{FollowerID: ids[0], FollowedID: ids[1]},
+	)
+	toUser1 := edgeIDs(edges[:3])
+
+	if got, err := follows.ListByFollowed(ctx, []uint{ids[0]}); err != nil || !slices.Equal(edgeIDs(got), toUser1) {
+		t.Errorf("ListByFollowed = %v, %v, want %v", edgeIDs(got), err, toUser1)
+	}
+	if got, err := follows.ListByFollower(ctx, []uint{ids[0], ids[1]}); err != nil || !slices.Equal(edgeIDs(got), []uint{edges[0].ID, edges[3].ID}) {
+		t.Errorf("ListByFollower = %v, %v", edgeIDs(got), err)
+	}
+
+	page, total, err := follows.PageByFollowed(ctx, ids[0], repository.Window{AfterID: &toUser1[0], Limit: 1})
+	if err != nil || total != 3 || !slices.Equal(edgeIDs(page), toUser1[1:2]) {
+		t.Errorf("PageByFollowed = %v, %d, %v", edgeIDs(page), total, err)
+	}
+	page, total, err = follows.PageByFollower(ctx, ids[0], repository.Window{})
+	if err != nil || total != 1 || !slices.Equal(edgeIDs(page), []uint{edges[3].ID}) {
+		t.Errorf("PageByFollower = %v, %d, %v", edgeIDs(page), total, err)
+	}
+
+	existing, err := follows.Existing(ctx, []repository.FollowPair{
+		{FollowerID: ids[1], FollowedID: ids[0]},
+		{FollowerID: ids[0], FollowedID: ids[2]},
+		{FollowerID: ids[0], FollowedID: ids[1]},
+	})
+	slices.SortFunc(existing, func(a, b repository.FollowPair) int { return int(a.FollowerID) - int(b.FollowerID) })
+	want := []repository.FollowPair{{FollowerID: ids[0], FollowedID: ids[1]}, {FollowerID: ids[1], FollowedID: ids[0]}}
+	if err != nil || !slices.Equal(existing, want) {
+		t.Errorf("Existing = %v, %v, want %v", existing, err, want)
+	}
+
+	all, last, err := follows.FollowerIDs(ctx, ids[0], 0, 0)
+	if err != nil || !slices.Equal(all, ids[1:]) || last != toUser1[2] {
+		t.Errorf("FollowerIDs = %v, %d, %v", all, last, err)
+	}
+	chunk, last, err := follows.FollowerIDs(ctx, ids[0], toUser1[0], 1)
+	if err != nil || !slices.Equal(chunk, ids[2:3]) || last != toUser1[1] {
+		t.Errorf("FollowerIDs after the first edge = %v, %d, %v", chunk, last, err)
+	}
+	if rest, last, err := follows.FollowerIDs(ctx, ids[0], toUser1[2], 10); err != nil || len(rest) != 0 || last != toUser1[2] {
+		t.Errorf("FollowerIDs after the last edge = %v, %d, %v", rest, last, err)
+	}
+
+	counts, err := follows.CountByFollowed(ctx, []uint{ids[0], ids[1], ids[2]})
+	if err != nil || counts[ids[0]] != 3 || counts[ids[1]] != 1 || counts[ids[2]] != 0 {
+		t.Errorf("CountByFollowed = %v, %v", counts, err)
+	}
+	counts, err = follows.CountByFollower(ctx, []uint{ids[0], ids[3]})
+	if err != nil || counts[ids[0]] != 1 || counts[ids[3]] != 1 {
+		t.Errorf("CountByFollower = %v, %v", counts, err)
+	}
+}
+
+func testFollowCleanup(t *testing.T, store repository.Store) {
+	ctx := context.Background()
+	follows := store.Follows()
+	created := createUsers(t, store, 3)
+	a, b, c := created[0].ID, created[1].ID, created[2].ID
+	edges := follow(t, store,
+		repository.FollowPair{FollowerID: a, FollowedID: b},
+		repository.FollowPair{FollowerID: b, FollowedID: c},
+		repository.FollowPair{FollowerID: c, FollowedID: a},
+		repository.FollowPair{FollowerID: b, FollowedID: a},
+	)
+
+	removed, err := follows.DeleteByUser(ctx, c)
+	if err != nil || !slices.Equal(edgeIDs(removed), []uint{edges[1].ID, edges[2].ID}) {
+		t.Fatalf("DeleteByUser = %v, %v", edgeIDs(removed), err)
+	}
+	if removed, err := follows.DeleteDangling(ctx); err != nil || len(removed) != 0 {
+		t.Fatalf("DeleteDangling without dangling edges = %v, %v", edgeIDs(removed), err)
+	}
+
+	if err := store.Users().Delete(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	removed, err = follows.DeleteDangling(ctx)
+	if err != nil || !slices.Equal(edgeIDs(removed), []uint{edges[0].ID, edges[3].ID}) {
+		t.Fatalf("DeleteDangling = %v, %v", edgeIDs(removed), err)
+	}
+	if left, _ := follows.ListByFollowed(ctx, []uint{a, b, c}); len(left) != 0 {
+		t.Fatalf("edges left = %v", edgeIDs(left))
+	}
+
+	// a follows b, then b follows a and unfollows
+	for _, event := range []models.FollowEvent{
+		{FollowerID: a, FollowedID: b, Action: models.FollowActionFollow},
+		{FollowerID: b, FollowedID: a, Action: models.FollowActionFollow},
+		{FollowerID: b, FollowedID: a, Action: models.FollowActionUnfollow},
+		{FollowerID: c, FollowedID: a, Action: models.FollowActionFollow},
+	} {
+		if err := follows.RecordEvent(ctx, &event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if changed, err := follows.PseudonymizeEvents(ctx, b); err != nil || changed != 3 {
+		t.Fatalf("PseudonymizeEvents = %d, %v", changed, err)
+	}
+	if changed, err := follows.PseudonymizeEvents(ctx, b); err != nil || changed != 0 {
+		t.Fatalf("PseudonymizeEvents again = %d, %v", changed, err)
+	}
+}
+
+func testAuditLog(t *testing.T, store repository.Store) {
+	ctx := context.Background()
+	audit := store.AuditLog()
+	userID := uint(7)
+
+	entries := []*models.AuditEntry{
+		{Actor: "service:tests", Action: models.AuditUserDisable, UserID: &userID, Details: `{"reason":"spam"}`},
+		{Actor: "service:tests", Action: models.AuditFollowersRebuild, Details: `{}`},
+		{Actor: "user:1", Action: models.AuditUserRestore, UserID: &userID, Details: `{}`},
+	}
+	for _, entry := range entries {
+		if err := audit.Append(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+		if entry.ID == 0 || entry.CreatedAt.IsZero() {
+			t.Fatalf("Append didn't fill in the entry: %+v", entry)
+		}
+	}
+
+	listed, err := audit.ListByUser(ctx, userID)
+	if err != nil || len(listed) != 2 {
+		t.Fatalf("ListByUser = %v, %v", listed, err)
+	}
+	if listed[0].Action != models.AuditUserDisable || listed[0].Details != `{"reason":"spam"}` || listed[1].Actor != "user:1" {
+		t.Fatalf("ListByUser = %+v, %+v", listed[0], listed[1])
+	}
+}
+
+func testUserChanges(t *testing.T, store repository.Store) {
+	ctx := context.Background()
+	changes := store.UserChanges()
+
+	if latest, err := changes.Latest(ctx); err != nil || latest != 0 {
+		t.Fatalf("Latest of an empty log = %d, %v", latest, err)
+	}
+
+	var sequences []uint64
+	for _, action := range []string{models.UserChangeCreated, models.UserChangeUpdated, models.UserChangeDeleted} {
+		change, err := changes.Append(ctx, 7, action)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sequences) > 0 && change.ID <= sequences[len(sequences)-1] {
+			t.Fatalf("sequence %d after %d", change.ID, sequences[len(sequences)-1])
+		}
+		sequences = append(sequences, change.ID)
+	}
+
+	if latest, err := changes.Latest(ctx); err != nil || latest != sequences[2] {
+		t.Fatalf("Latest = %d, %v, want %d", latest, err, sequences[2])
+	}
+
+	listed, err := changes.ListAfter(ctx, sequences[0], 1)
+	if err != nil || len(listed) != 1 || listed[0].ID != sequences[1] || listed[0].Action != models.UserChangeUpdated || listed[0].UserID != 7 {
+		t.Fatalf("ListAfter = %v, %v", listed, err)
+	}
+	if listed, err := changes.ListAfter(ctx, sequences[2], 10); err != nil || len(listed) != 0 {
+		t.Fatalf("ListAfter the latest = %v, %v", listed, err)
+	}
+}
+
+func testIdempotencyKeys(t *testing.T, store repository.Store) {
+	ctx := context.Background()
+	keys := store.IdempotencyKeys()
+
+	record := &models.IdempotencyKey{Scope: "user:1", Key: "k", Method: "/m", RequestHash: []byte{1}}
+	if created, err := keys.Create(ctx, record); err != nil || !created || record.ID == 0 {
+		t.Fatalf("Create = %v, %v", created, err)
+	}
+	if created, err := keys.Create(ctx, &models.IdempotencyKey{Scope: "user:1", Key: "k", Method: "/m", RequestHash: []byte{2}}); err != nil || created {
+		t.Fatalf("creating the key twice = %v, %v", created, err)
+	}
+	other := &models.IdempotencyKey{Scope: "user:2", Key: "k", Method: "/m", RequestHash: []byte{1}, ClaimedAt: time.Now()}
+	if created, err := keys.Create(ctx, other); err != nil || !created {
+		t.Fatalf("the same key in another scope = %v, %v", created, err)
+	}
+
+	// A claim without a response can be taken over once, after its lease
+	if reclaimed, err := keys.Reclaim(ctx, other.ID, other.ClaimedAt, time.Now()); err != nil || reclaimed {
+		t.Fatalf("Reclaim within the lease = %v, %v", reclaimed, err)
+	}
+	later := time.Now().Add(time.Minute)
+	if reclaimed, err := keys.Reclaim(ctx, other.ID, later, later); err != nil || !reclaimed {
+		t.Fatalf("Reclaim after the lease = %v, %v", reclaimed, err)
+	}
+	if reclaimed, err := keys.Reclaim(ctx, other.ID, later, later); err != nil || reclaimed {
+		t.Fatalf("Reclaim of a reclaimed key = %v, %v", reclaimed, err)
+	}
+
+	if err := keys.SetResponse(ctx, record.ID, []byte("done"), 7); err != nil {
+		t.Fatal(err)
+	}
+	if reclaimed, err := keys.Reclaim(ctx, record.ID, later, later); err != nil || reclaimed {
+		t.Fatalf("Reclaim of a completed key = %v, %v", reclaimed, err)
+	}
+	got, err := keys.Get(ctx, "user:1", "k")
+	if err != nil || got.ID != record.ID || string(got.Response) != "done" || got.Method != "/m" || got.UserID == nil || *got.UserID != 7 {
+		t.Fatalf("Get = %+v, %v", got, err)
+	}
+	if _, err := keys.Get(ctx, "user:3", "k"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("Get of a missing key = %v, want ErrNotFound", err)
+	}
+
+	// Only the expired keys of the scope go away
+	if err := keys.DeleteExpired(ctx, "user:1", time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Get(ctx, "user:1", "k"); err != nil {
+		t.Fatalf("a fresh key expired: %v", err)
+	}
+	if err := keys.DeleteExpired(ctx, "user:1", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Get(ctx, "user:1", "k"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("an expired key survived: %v", err)
+	}
+	if _, err := keys.Get(ctx, "user:2", "k"); err != nil {
+		t.Fatalf("a key of another scope expired: %v", err)
+	}
+
+	if err := keys.Delete(ctx, other.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Get(ctx, "user:2", "k"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("Get of a deleted key = %v, want ErrNotFound", err)
+	}
+
+	// Erasing a user removes the responses about them and their own keys
+	for i, record := range []*models.IdempotencyKey{
+		{Scope: "service:posts", Key: "about-7", Method: "/m", RequestHash: []byte{1}},
+		{Scope: "service:posts", Key: "about-8", Method: "/m", RequestHash: []byte{1}},
+		{Scope: "user:7", Key: "own", Method: "/m", RequestHash: []byte{1}},
+	} {
+		if _, err := keys.Create(ctx, record); err != nil {
+			t.Fatal(err)
+		}
+		if err := keys.SetResponse(ctx, record.ID, []byte("done"), uint(7+i%2)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if deleted, err := keys.DeleteByUser(ctx, 7, "user:7"); err != nil || deleted != 2 {
+		t.Fatalf("DeleteByUser = %d, %v", deleted, err)
+	}
+	if _, err := keys.Get(ctx, "service:posts", "about-8"); err != nil {
+		t.Fatalf("the response about another user went away: %v", err)
+	}
+
+	// Expired keys of every scope
+	if deleted, err := keys.DeleteAllExpired(ctx, time.Now().Add(-time.Hour)); err != nil || deleted != 0 {
+		t.Fatalf("DeleteAllExpired of fresh keys = %d, %v", deleted, err)
+	}
+	if deleted, err := keys.DeleteAllExpired(ctx, time.Now().Add(time.Hour)); err != nil || deleted != 1 {
+		t.Fatalf("DeleteAllExpired = %d, %v", deleted, err)
+	}
+}
+
+func testTransaction(t *testing.T, store repository.Store) {
+	ctx := context.Background()
+	errRollback := errors.New("rollback")
+
+	createIn := func(tx repository.Store, username string) error {
+		user := &models.User{FirstName: "x", LastName: "x", Email: username + "@example.com", Username: username, Password: "x"}
+		if err := tx.Users().Create(ctx, user); err != nil {
+			return err
+		}
+		_, err := tx.UserChanges().Append(ctx, user.ID, models.UserChangeCreated)
+		return err
+	}
+	exists := func(username string) bool {
+		_, err := store.Users().GetByUsername(ctx, username)
+		return err == nil
+	}
+
+	err := store.Transaction(ctx, func(tx repository.Store) error {
+		if err := createIn(tx, "rolledback"); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("Transaction = %v, want the error of fn", err)
+	}
+	if exists("rolledback") {
+		t.Fatal("the user of a failed transaction was kept")
+	}
+	if latest, _ := store.UserChanges().Latest(ctx); latest != 0 {
+		t.Fatal("the change of a failed transaction was kept")
+	}
+
+	// A failed nested transaction only undoes its own writes
+	err = store.Transaction(ctx, func(tx repository.Store) error {
+		if err := createIn(tx, "outer"); err != nil {
+			return err
+		}
+		nested := tx.Transaction(ctx, func(tx repository.Store) error {
+			if err := createIn(tx, "inner"); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if !errors.Is(nested, errRollback) {
+			t.Errorf("nested Transaction = %v, want the error of fn", nested)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists("outer") || exists("inner") {
+		t.Fatalf("outer kept = %v, inner kept = %v", exists("outer"), exists("inner"))
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/antoniocfetngnu/users-api/middleware"
+	"github.com/antoniocfetngnu/users-api/models"
+)
+
+type serviceCallerKey struct{}
+
+// AsService marks ctx as a call from a trusted internal caller (another
+// service over gRPC, an operator tool) that may act on any account
+func AsService(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, serviceCallerKey{}, name)
+}
+
+// ServiceFromContext returns the name given to AsService
+func ServiceFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(serviceCallerKey{}).(string)
+	return name, ok
+}
+
+// CanAccessUser reports whether the caller may change the account of
+// userID and read its private fields (the email): internal services,
+// admins and the user themselves
+func CanAccessUser(ctx context.Context, userID uint) bool {
+	if _, ok := ServiceFromContext(ctx); ok {
+		return true
+	}
+	principal, ok := middleware.PrincipalFromContext(ctx)
+	return ok && (principal.IsAdmin() || principal.UserID == userID)
+}
+
+// authorizeUser fails unless the caller may change the account of userID
+func authorizeUser(ctx context.Context, userID uint) error {
+	if CanAccessUser(ctx, userID) {
+		return nil
+	}
+	if _, ok := middleware.PrincipalFromContext(ctx); !ok {
+		return ErrUnauthenticated
+	}
+	return ErrForbidden
+}
+
+// authorizeAdmin fails unless the caller is an internal service or an admin
+func authorizeAdmin(ctx context.Context) error {
+	if _, ok := ServiceFromContext(ctx); ok {
+		return nil
+	}
+	principal, ok := middleware.PrincipalFromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	if !principal.IsAdmin() {
+		return ErrAdminOnly
+	}
+	return nil
+}
+
+// UserScope names an end user in the audit log and in idempotency key
+// scopes
+func UserScope(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// actorOf names the caller in the audit log
+func actorOf(ctx context.Context) string {
+	if name, ok := ServiceFromContext(ctx); ok {
+		return "service:" + name
+	}
+	if principal, ok := middleware.PrincipalFromContext(ctx); ok {
+		return UserScope(principal.UserID)
+	}
+	return "unknown"
+}
+
+// redact clears the private fields of the users the caller may not see.
+// The users are fresh copies from the repository, never shared.
+func redact(ctx context.Context, users ...*models.User) {
+	for _, user := range users {
+		if user != nil && !CanAccessUser(ctx, user.ID) {
+			user.Email = ""
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/antoniocfetngnu/users-api/events"
+	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/repository"
+	"github.com/antoniocfetngnu/users-api/utils"
+)
+
+type adminService struct {
+	store repository.Store
+	users *userService
+}
+
+// audit appends an entry about userID (0 for none) to the audit log
+func audit(ctx context.Context, tx repository.Store, action string, userID uint, details map[string]any) error {
+	_, err := appendAudit(ctx, tx, action, userID, details)
+	return err
+}
+
+// appendAudit is audit returning the entry
+func appendAudit(ctx context.Context, tx repository.Store, action string, userID uint, details map[string]any) (*models.AuditEntry, error) {
+	if details == nil {
+		details = map[string]any{}
+	}
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.AuditEntry{Actor: actorOf(ctx), Action: action, Details: string(raw)}
+	if userID != 0 {
+		entry.UserID = &userID
+	}
+	return entry, tx.AuditLog().Append(ctx, entry)
+}
+
+// validRole fails unless role is one of the models.Role* constants
+func validRole(role string) error {
+	if role != models.RoleUser && role != models.RoleAdmin {
+		return Invalid(fmt.Sprintf("Role must be %q or %q", models.RoleUser, models.RoleAdmin))
+	}
+	return nil
+}
+
+// CreateUser registers an account with the given role
+func (s *adminService) CreateUser(ctx context.Context, req models.RegisterRequest, role string) (*models.User, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if err := validRole(role); err != nil {
+		return nil, err
+	}
+
+	return s.users.create(ctx, req, role, func(tx repository.Store, user *models.User) error {
+		return audit(ctx, tx, models.AuditUserCreate, user.ID, map[string]any{"role": role})
+	})
+}
+
+// SetPassword replaces the password of a user without the current one
+func (s *adminService) SetPassword(ctx context.Context, id uint, password string) error {
+	if err := authorizeAdmin(ctx); err != nil {
+		return err
+	}
+	if err := validate(&models.SetPasswordRequest{Password: password}); err != nil {
+		return err
+	}
+
+	user, err := s.store.Users().Get(ctx, id)
+	if err != nil {
+		return notFound(err, ErrUserNotFound)
+	}
+	if user.Password, err = utils.HashPassword(password); err != nil {
+		return err
+	}
+
+	return s.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Users().Update(ctx, user); err != nil {
+			return err
+		}
+		return audit(ctx, tx, models.AuditUserSetPassword, user.ID, nil)
+	})
+}
+
+// SetRole changes the role of a user
+func (s *adminService) SetRole(ctx context.Context, id uint, role string) (*models.User, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if err := validRole(role); err != nil {
+		return nil, err
+	}
+
+	user, err := s.store.Users().Get(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+	if user.Role == role {
+		return user, nil
+	}
+	previous := user.Role
+	user.Role = role
+
+	var change *models.UserChange
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Users().Update(ctx, user); err != nil {
+			return err
+		}
+		if change, err = tx.UserChanges().Append(ctx, user.ID, models.UserChangeUpdated); err != nil {
+			return err
+		}
+		return audit(ctx, tx, models.AuditUserSetRole, user.ID, map[string]any{"role": role, "previousRole": previous})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	publishUserChange(events.UserUpdated, change, user)
+	return user, nil
+}
+
+// DisableUser stops a user from logging in until RestoreUser. Sessions
+// already open last until their token expires.
+func (s *adminService) DisableUser(ctx context.Context, id uint) (*models.User, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	user, err := s.store.Users().Get(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+	if user.DisabledAt != nil {
+		return user, nil
+	}
+	now := time.Now()
+	user.DisabledAt = &now
+
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Users().Update(ctx, user); err != nil {
+			return err
+		}
+		return audit(ctx, tx, models.AuditUserDisable, user.ID, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// RestoreUser brings back a deleted or disabled user, also after the
+// grace period as long as the account wasn't purged
+func (s *adminService) RestoreUser(ctx context.Context, id uint) (*models.User, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	user, err := s.store.Users().GetWithDeleted(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+	if user.ErasedAt != nil {
+		return nil, ErrAccountErased
+	}
+	wasDeleted, wasDisabled := user.DeletedAt.Valid, user.DisabledAt != nil
+	if !wasDeleted && !wasDisabled {
+		return user, nil
+	}
+
+	restore := func(tx repository.Store) error {
+		if wasDisabled {
+			// Update writes every column, the cleared deleted_at included
+			user.DeletedAt.Valid = false
+			user.DisabledAt = nil
+			if err := tx.Users().Update(ctx, user); err != nil {
+				return err
+			}
+		}
+		return audit(ctx, tx, models.AuditUserRestore, user.ID, map[string]any{"deleted": wasDeleted, "disabled": wasDisabled})
+	}
+	if wasDeleted {
+		err = s.users.undelete(ctx, user, restore)
+	} else {
+		err = s.store.Transaction(ctx, restore)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// PurgeUser removes a user and their follow edges for good. The follow
+// history keeps an unfollow event per edge.
+func (s *adminService) PurgeUser(ctx context.Context, id uint) error {
+	if err := authorizeAdmin(ctx); err != nil {
+		return err
+	}
+
+	user, err := s.store.Users().GetWithDeleted(ctx, id)
+	if err != nil {
+		return notFound(err, ErrUserNotFound)
+	}
+	// The tombstone keeps the ID from being resolved or reused
+	if user.ErasedAt != nil {
+		return ErrAccountErased
+	}
+	_, err = s.purge(ctx, user, nil)
+	return err
+}
+
+// EraseUser erases the personal data of an account for good, deleted or
+// not, see models.User.Erase
+func (s *adminService) EraseUser(ctx context.Context, id uint) (*models.ErasureReceipt, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	user, err := s.store.Users().GetWithDeleted(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+	return s.users.erase(ctx, user, false)
+}
+
+// purgeBatchSize is how many expired accounts PurgeDeletedUsers loads at
+// once
+const purgeBatchSize = 100
+
+// PurgeDeletedUsers purges the users deleted longer than the grace period
+// ago. Several replicas may run it at once: a user purged by another one
+// is skipped.
+func (s *adminService) PurgeDeletedUsers(ctx context.Context) (purged int, err error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return 0, err
+	}
+
+	before := time.Now().Add(-s.users.gracePeriod)
+	for {
+		users, err := s.store.Users().ListDeletedBefore(ctx, before, purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		for _, user := range users {
+			ok, err := s.purge(ctx, user, map[string]any{"gracePeriodExpired": true})
+			if err != nil {
+				return purged, err
+			}
+			if ok {
+				purged++
+			}
+		}
+		if len(users) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// errPurged rolls back a purge that another caller already made
+var errPurged = errors.New("user already purged")
+
+// purge removes user and their edges, adding details to the audit entry.
+// Nothing happens if the user is gone already, such as when several
+// replicas purge the same user at once; purged is false then.
+func (s *adminService) purge(ctx context.Context, user *models.User, details map[string]any) (purged bool, err error) {
+	var change *models.UserChange
+	var edges []*models.Follower
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		var err error
+		if edges, err = removeEdges(ctx, tx, func() ([]*models.Follower, error) { return tx.Follows().DeleteByUser(ctx, user.ID) }); err != nil {
+			return err
+		}
+		// The delete waits for a concurrent one to commit, and then
+		// affects no row
+		err = tx.Users().Purge(ctx, user.ID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return errPurged
+		}
+		if err != nil {
+			return err
+		}
+		// Consumers of the change log already saw deleted users go
+		if !user.DeletedAt.Valid {
+			if change, err = tx.UserChanges().Append(ctx, user.ID, models.UserChangeDeleted); err != nil {
+				return err
+			}
+		}
+
+		if details == nil {
+			details = map[string]any{}
+		}
+		details["followEdges"] = len(edges)
+		return audit(ctx, tx, models.AuditUserPurge, user.ID, details)
+	})
+	if errors.Is(err, errPurged) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if change != nil {
+		publishUserChange(events.UserDeleted, change, user)
+	}
+	publishFollowersRemoved(edges...)
+	return true, nil
+}
+
+// RebuildFollowerCounts removes the follow edges of deleted users. Follower
+// counts are counted from the edges, so these left them too high.
+func (s *adminService) RebuildFollowerCounts(ctx context.Context) (removed int, err error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return 0, err
+	}
+
+	var edges []*models.Follower
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		var err error
+		if edges, err = removeEdges(ctx, tx, func() ([]*models.Follower, error) { return tx.Follows().DeleteDangling(ctx) }); err != nil {
+			return err
+		}
+		return audit(ctx, tx, models.AuditFollowersRebuild, 0, map[string]any{"removedEdges": len(edges)})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	publishFollowersRemoved(edges...)
+	return len(edges), nil
+}
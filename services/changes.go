@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+
+	"github.com/antoniocfetngnu/users-api/events"
+	"github.com/antoniocfetngnu/users-api/models"
+)
+
+// publishUserChange publishes a committed change
+func publishUserChange(eventType events.Type, change *models.UserChange, user *models.User) {
+	events.Publish(context.Background(), events.Event{
+		Type:     eventType,
+		UserID:   change.UserID,
+		Sequence: change.ID,
+		User:     user,
+	})
+}
+
+// ListUserChanges returns up to limit changes with a sequence after the
+// given one, oldest first
+func (s *userService) ListUserChanges(ctx context.Context, after uint64, limit int) ([]*models.UserChange, error) {
+	return s.store.UserChanges().ListAfter(ctx, after, limit)
+}
+
+// LatestUserChange returns the sequence of the newest change, 0 if there
+// is none
+func (s *userService) LatestUserChange(ctx context.Context) (uint64, error) {
+	return s.store.UserChanges().Latest(ctx)
+}
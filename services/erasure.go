@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/antoniocfetngnu/users-api/events"
+	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/repository"
+	"github.com/antoniocfetngnu/users-api/utils"
+)
+
+// EraseAccount erases the personal data of an account for good, deleted or
+// not, once the owner confirms with the password. Admins use
+// AdminService.EraseUser instead.
+func (s *userService) EraseAccount(ctx context.Context, id uint, password string) (*models.ErasureReceipt, error) {
+	if err := authorizeUser(ctx, id); err != nil {
+		return nil, err
+	}
+
+	// The owner's session outlives a deletion, so deleted accounts count
+	user, err := s.store.Users().GetWithDeleted(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+	if user.ErasedAt != nil {
+		return nil, ErrAccountErased
+	}
+	if err := utils.CheckPassword(user.Password, password); err != nil {
+		return nil, ErrWrongPassword
+	}
+	return s.erase(ctx, user, true)
+}
+
+// erase scrubs user with models.User.Erase, removes their follow edges and
+// the stored idempotent responses about them, and pseudonymizes their
+// follow history. The audit entry is the receipt; selfService tells whether
+// the owner asked.
+func (s *userService) erase(ctx context.Context, user *models.User, selfService bool) (*models.ErasureReceipt, error) {
+	wasDeleted := user.DeletedAt.Valid
+	user.Erase(time.Now())
+
+	var change *models.UserChange
+	var edges []*models.Follower
+	var entry *models.AuditEntry
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		// Erased concurrently
+		current, err := tx.Users().GetWithDeleted(ctx, user.ID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if current.ErasedAt != nil {
+			return ErrAccountErased
+		}
+
+		if err := tx.Users().Erase(ctx, user); err != nil {
+			return err
+		}
+		// Consumers of the change log already saw deleted users go
+		if !wasDeleted {
+			if change, err = tx.UserChanges().Append(ctx, user.ID, models.UserChangeDeleted); err != nil {
+				return err
+			}
+		}
+		if edges, err = removeEdges(ctx, tx, func() ([]*models.Follower, error) { return tx.Follows().DeleteByUser(ctx, user.ID) }); err != nil {
+			return err
+		}
+		// After removeEdges, which records unfollows of the user
+		followEvents, err := tx.Follows().PseudonymizeEvents(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		idempotencyKeys, err := tx.IdempotencyKeys().DeleteByUser(ctx, user.ID, UserScope(user.ID))
+		if err != nil {
+			return err
+		}
+
+		entry, err = appendAudit(ctx, tx, models.AuditUserErase, user.ID, map[string]any{
+			"selfService":     selfService,
+			"wasDeleted":      wasDeleted,
+			"followEdges":     len(edges),
+			"followEvents":    followEvents,
+			"idempotencyKeys": idempotencyKeys,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if change != nil {
+		publishUserChange(events.UserDeleted, change, user)
+	}
+	publishFollowersRemoved(edges...)
+	// Carries the tombstone, for other services to scrub their copies
+	events.Publish(context.Background(), events.Event{Type: events.UserErased, UserID: user.ID, User: user})
+
+	return &models.ErasureReceipt{
+		ID:          entry.ID,
+		UserID:      user.ID,
+		Actor:       entry.Actor,
+		FollowEdges: len(edges),
+		ErasedAt:    *user.ErasedAt,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/antoniocfetngnu/users-api/models"
+)
+
+// Kind classifies domain errors so each transport can map them to its own
+// status codes (HTTP status, GraphQL extensions.code, ...)
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindUnauthenticated
+	KindForbidden
+	KindNotFound
+	KindConflict
+)
+
+// Error is a domain error whose message is safe to show to clients
+type Error struct {
+	Kind    Kind
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Domain errors shared by the REST, GraphQL and gRPC transports
+var (
+	ErrUnauthenticated    = &Error{Kind: KindUnauthenticated, Message: "Unauthorized"}
+	ErrForbidden          = &Error{Kind: KindForbidden, Message: "You can only change your own account"}
+	ErrAdminOnly          = &Error{Kind: KindForbidden, Message: "Admin access required"}
+	ErrAccountDisabled    = &Error{Kind: KindForbidden, Message: "Account is disabled"}
+	ErrNotDeleted         = &Error{Kind: KindConflict, Message: "Account is not deleted"}
+	ErrRestoreExpired     = &Error{Kind: KindForbidden, Message: "Account can no longer be restored"}
+	ErrAccountErased      = &Error{Kind: KindConflict, Message: "Account was erased"}
+	ErrInvalidCredentials = &Error{Kind: KindUnauthenticated, Message: "Invalid credentials"}
+	ErrWrongPassword      = &Error{Kind: KindInvalid, Message: "Current password is incorrect"}
+	ErrUserNotFound       = &Error{Kind: KindNotFound, Message: "User not found"}
+	ErrUserExists         = &Error{Kind: KindConflict, Message: "Username or email already exists"}
+	ErrReservedUsername   = &Error{Kind: KindInvalid, Message: "Usernames starting with \"" + models.TombstonePrefix + "\" are reserved"}
+	ErrFollowSelf         = &Error{Kind: KindInvalid, Message: "Cannot follow yourself"}
+	ErrFollowedNotFound   = &Error{Kind: KindNotFound, Message: "User to follow not found"}
+	ErrNotFollowing       = &Error{Kind: KindNotFound, Message: "Not following this user"}
+	ErrFollowerNotFound   = &Error{Kind: KindNotFound, Message: "Follow relationship not found"}
+)
+
+// Invalid wraps a validation failure
+func Invalid(message string) *Error {
+	return &Error{Kind: KindInvalid, Message: message}
+}
+
+// KindOf returns the Kind of a domain error, or KindInternal for anything else
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindInternal
+}
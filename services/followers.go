@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/antoniocfetngnu/users-api/events"
+	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/repository"
+)
+
+type followService struct {
+	store repository.Store
+	users UserService
+}
+
+// Follow makes followerID follow followedID. It is idempotent: following an
+// already followed user returns the existing edge with created=false.
+func (s *followService) Follow(ctx context.Context, followerID, followedID uint) (follower *models.Follower, created bool, err error) {
+	if err := authorizeUser(ctx, followerID); err != nil {
+		return nil, false, err
+	}
+
+	// Can't follow yourself
+	if followerID == followedID {
+		return nil, false, ErrFollowSelf
+	}
+
+	// A deleted user's session outlives the account
+	if _, err := s.store.Users().Get(ctx, followerID); err != nil {
+		return nil, false, notFound(err, ErrUserNotFound)
+	}
+
+	// Check if user to follow exists
+	if _, err := s.store.Users().Get(ctx, followedID); err != nil {
+		return nil, false, notFound(err, ErrFollowedNotFound)
+	}
+
+	follower = &models.Follower{
+		FollowerID:    followerID,
+		FollowedID:    followedID,
+		FollowedSince: time.Now(),
+	}
+
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		if created, err = tx.Follows().Create(ctx, follower); err != nil || !created {
+			return err
+		}
+		return tx.Follows().RecordEvent(ctx, &models.FollowEvent{
+			FollowerID: followerID,
+			FollowedID: followedID,
+			Action:     models.FollowActionFollow,
+		})
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Load the existing edge if nothing was inserted, and both users
+	if !created {
+		if follower, err = s.store.Follows().GetPair(ctx, FollowPair{FollowerID: followerID, FollowedID: followedID}); err != nil {
+			return nil, false, err
+		}
+	}
+	if err := s.AttachUsers(ctx, []*models.Follower{follower}); err != nil {
+		return nil, false, err
+	}
+
+	if created {
+		events.Publish(context.Background(), events.Event{
+			Type:     events.FollowerAdded,
+			UserID:   followedID,
+			Follower: follower,
+		})
+	}
+	return follower, created, nil
+}
+
+// Unfollow removes the edge between followerID and followedID. It is
+// idempotent: removed is false when there was nothing to remove.
+func (s *followService) Unfollow(ctx context.Context, followerID, followedID uint) (removed bool, err error) {
+	if err := authorizeUser(ctx, followerID); err != nil {
+		return false, err
+	}
+
+	// Hard delete, history goes to follow_events
+	var follower *models.Follower
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		follower, err = tx.Follows().Delete(ctx, FollowPair{FollowerID: followerID, FollowedID: followedID})
+		if err != nil || follower == nil {
+			return err
+		}
+		return tx.Follows().RecordEvent(ctx, &models.FollowEvent{
+			FollowerID: followerID,
+			FollowedID: followedID,
+			Action:     models.FollowActionUnfollow,
+		})
+	})
+	if err != nil || follower == nil {
+		return false, err
+	}
+
+	publishFollowersRemoved(follower)
+	return true, nil
+}
+
+// removeEdges runs remove and records an unfollow event for each edge it
+// removed
+func removeEdges(ctx context.Context, tx repository.Store, remove func() ([]*models.Follower, error)) ([]*models.Follower, error) {
+	edges, err := remove()
+	if err != nil {
+		return nil, err
+	}
+	for _, edge := range edges {
+		if err := tx.Follows().RecordEvent(ctx, &models.FollowEvent{
+			FollowerID: edge.FollowerID,
+			FollowedID: edge.FollowedID,
+			Action:     models.FollowActionUnfollow,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return edges, nil
+}
+
+// publishFollowersRemoved publishes the removal of committed edges
+func publishFollowersRemoved(edges ...*models.Follower) {
+	for _, edge := range edges {
+		events.Publish(context.Background(), events.Event{
+			Type:     events.FollowerRemoved,
+			UserID:   edge.FollowedID,
+			Follower: edge,
+		})
+	}
+}
+
+// ListFollowers returns the edges of users following userID. Use
+// AttachUsers to fill in the Follower/Followed relations.
+func (s *followService) ListFollowers(ctx context.Context, userID uint) ([]*models.Follower, error) {
+	return s.store.Follows().ListByFollowed(ctx, []uint{userID})
+}
+
+// ListFollowing returns the edges of users that userID follows. Use
+// AttachUsers to fill in the Follower/Followed relations.
+func (s *followService) ListFollowing(ctx context.Context, userID uint) ([]*models.Follower, error) {
+	return s.store.Follows().ListByFollower(ctx, []uint{userID})
+}
+
+// ListFollowersPage returns a page of the edges of users following userID
+func (s *followService) ListFollowersPage(ctx context.Context, userID uint, args PageArgs) (*Page[*models.Follower], error) {
+	return paginate(args, func(w repository.Window) ([]*models.Follower, int, error) {
+		return s.store.Follows().PageByFollowed(ctx, userID, w)
+	})
+}
+
+// ListFollowingPage returns a page of the edges of users that userID follows
+func (s *followService) ListFollowingPage(ctx context.Context, userID uint, args PageArgs) (*Page[*models.Follower], error) {
+	return paginate(args, func(w repository.Window) ([]*models.Follower, int, error) {
+		return s.store.Follows().PageByFollower(ctx, userID, w)
+	})
+}
+
+// AttachUsers fills the Follower and Followed relations of the edges with a
+// single query, with the same privacy rules as the user lookups
+func (s *followService) AttachUsers(ctx context.Context, edges []*models.Follower) error {
+	ids := make([]uint, 0, len(edges)*2)
+	for _, edge := range edges {
+		ids = append(ids, edge.FollowerID, edge.FollowedID)
+	}
+
+	users, err := s.users.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[uint]*models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+	for _, edge := range edges {
+		if user, ok := byID[edge.FollowerID]; ok {
+			edge.Follower = *user
+		}
+		if user, ok := byID[edge.FollowedID]; ok {
+			edge.Followed = *user
+		}
+	}
+	return nil
+}
+
+// GetFollower returns a follow edge by its ID
+func (s *followService) GetFollower(ctx context.Context, id uint) (*models.Follower, error) {
+	follower, err := s.store.Follows().Get(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrFollowerNotFound)
+	}
+	return follower, nil
+}
+
+// GetFollowRelationship returns the edge between followerID and followedID
+func (s *followService) GetFollowRelationship(ctx context.Context, followerID, followedID uint) (*models.Follower, error) {
+	follower, err := s.store.Follows().GetPair(ctx, FollowPair{FollowerID: followerID, FollowedID: followedID})
+	if err != nil {
+		return nil, notFound(err, ErrNotFollowing)
+	}
+	return follower, nil
+}
+
+// CheckFollows reports which of the pairs are existing follow edges
+func (s *followService) CheckFollows(ctx context.Context, pairs []FollowPair) (map[FollowPair]bool, error) {
+	existing, err := s.store.Follows().Existing(ctx, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	follows := make(map[FollowPair]bool, len(pairs))
+	for _, pair := range existing {
+		follows[pair] = true
+	}
+	return follows, nil
+}
+
+// FollowerIDs returns the IDs of all users following userID, oldest
+// follow first
+func (s *followService) FollowerIDs(ctx context.Context, userID uint) ([]uint, error) {
+	ids, _, err := s.store.Follows().FollowerIDs(ctx, userID, 0, 0)
+	return ids, err
+}
+
+// EachFollowerIDs calls fn with the IDs of the users following userID in
+// chunks of at most chunkSize, oldest follow first, so large follower sets
+// are never loaded at once. It stops at the first error returned by fn.
+func (s *followService) EachFollowerIDs(ctx context.Context, userID uint, chunkSize int, fn func(ids []uint) error) error {
+	var lastEdgeID uint
+	for {
+		ids, last, err := s.store.Follows().FollowerIDs(ctx, userID, lastEdgeID, chunkSize)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := fn(ids); err != nil {
+			return err
+		}
+		if len(ids) < chunkSize {
+			return nil
+		}
+		lastEdgeID = last
+	}
+}
+
+// CountFollowersByUsers returns the follower count of several users at once
+func (s *followService) CountFollowersByUsers(ctx context.Context, userIDs []uint) (map[uint]int, error) {
+	return s.store.Follows().CountByFollowed(ctx, userIDs)
+}
+
+// CountFollowingByUsers returns the following count of several users at once
+func (s *followService) CountFollowingByUsers(ctx context.Context, userIDs []uint) (map[uint]int, error) {
+	return s.store.Follows().CountByFollower(ctx, userIDs)
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/repository"
+)
+
+type idempotencyService struct {
+	store repository.Store
+}
+
+// Claim reserves key for a call. claimed is false when the key was already
+// used within ttl; record is then the earlier call (whose Response is empty
+// if it is still in progress). Keys older than ttl are forgotten, and a
+// call that didn't finish within lease (the process died holding it) gives
+// up the key to a retry of the same request; 0 never takes a key over.
+func (s *idempotencyService) Claim(ctx context.Context, scope, key, method string, requestHash []byte, ttl, lease time.Duration) (record *models.IdempotencyKey, claimed bool, err error) {
+	record, claimed, err = s.claim(ctx, scope, key, method, requestHash, ttl)
+	if errors.Is(err, repository.ErrNotFound) {
+		// The earlier call was released between both steps, claim it again
+		record, claimed, err = s.claim(ctx, scope, key, method, requestHash, ttl)
+	}
+	if err != nil || claimed || len(record.Response) > 0 {
+		return record, claimed, err
+	}
+	if lease <= 0 || record.Method != method || !bytes.Equal(record.RequestHash, requestHash) {
+		return record, false, nil
+	}
+
+	now := time.Now()
+	reclaimed, err := s.store.IdempotencyKeys().Reclaim(ctx, record.ID, now.Add(-lease), now)
+	if err != nil || !reclaimed {
+		return record, false, err
+	}
+	record.ClaimedAt = now
+	return record, true, nil
+}
+
+// claim creates the key, or returns the call that holds it
+func (s *idempotencyService) claim(ctx context.Context, scope, key, method string, requestHash []byte, ttl time.Duration) (record *models.IdempotencyKey, claimed bool, err error) {
+	now := time.Now()
+	record = &models.IdempotencyKey{Scope: scope, Key: key, Method: method, RequestHash: requestHash, ClaimedAt: now}
+
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.IdempotencyKeys().DeleteExpired(ctx, scope, now.Add(-ttl)); err != nil {
+			return err
+		}
+		claimed, err = tx.IdempotencyKeys().Create(ctx, record)
+		return err
+	})
+	if err != nil || claimed {
+		return record, claimed, err
+	}
+
+	record, err = s.store.IdempotencyKeys().Get(ctx, scope, key)
+	return record, false, err
+}
+
+// Complete stores the response of a claimed call. userID is the user the
+// response describes (0 for none), whose erasure removes it.
+func (s *idempotencyService) Complete(ctx context.Context, id uint, response []byte, userID uint) error {
+	return s.store.IdempotencyKeys().SetResponse(ctx, id, response, userID)
+}
+
+// Release forgets a claimed call that failed, so it can be retried with the
+// same key
+func (s *idempotencyService) Release(ctx context.Context, id uint) error {
+	return s.store.IdempotencyKeys().Delete(ctx, id)
+}
+
+// DeleteExpired forgets the keys of every caller older than ttl. Claim only
+// expires the keys of the caller claiming one, so callers that stopped
+// making calls need this to be run now and then.
+func (s *idempotencyService) DeleteExpired(ctx context.Context, ttl time.Duration) (int, error) {
+	return s.store.IdempotencyKeys().DeleteAllExpired(ctx, time.Now().Add(-ttl))
+}
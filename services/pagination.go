@@ -0,0 +1,87 @@
+package services
+
+import "github.com/antoniocfetngnu/users-api/repository"
+
+// Page size limits for paginated lists
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// PageArgs selects a window of a list ordered by ID, following the Relay
+// cursor connection semantics (first/after, last/before)
+type PageArgs struct {
+	First  *int
+	After  *uint
+	Last   *int
+	Before *uint
+}
+
+// Page is a window of a list plus what is needed to build page info
+type Page[T any] struct {
+	Items           []T
+	HasNextPage     bool
+	HasPreviousPage bool
+	TotalCount      int
+}
+
+// validate rejects arguments that can't be served
+func (a PageArgs) validate() error {
+	if a.First != nil && a.Last != nil {
+		return Invalid("Passing both first and last is not supported")
+	}
+	if (a.First != nil && *a.First < 0) || (a.Last != nil && *a.Last < 0) {
+		return Invalid("first and last must not be negative")
+	}
+	if (a.First != nil && *a.First > MaxPageSize) || (a.Last != nil && *a.Last > MaxPageSize) {
+		return Invalid("first and last must not exceed 100")
+	}
+	return nil
+}
+
+// paginate reads the window selected by args through list, which returns
+// the rows of a repository window and the total count
+func paginate[T any](args PageArgs, list func(repository.Window) ([]T, int, error)) (*Page[T], error) {
+	if err := args.validate(); err != nil {
+		return nil, err
+	}
+
+	// Paginating backwards reads the window in reverse and flips it back
+	backwards := args.Last != nil
+	limit := DefaultPageSize
+	switch {
+	case args.First != nil:
+		limit = *args.First
+	case args.Last != nil:
+		limit = *args.Last
+	}
+
+	// Fetch one extra row to know whether there is another page
+	items, total, err := list(repository.Window{
+		AfterID:    args.After,
+		BeforeID:   args.Before,
+		Limit:      limit + 1,
+		Descending: backwards,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	more := len(items) > limit
+	if more {
+		items = items[:limit]
+	}
+
+	page := &Page[T]{Items: items, TotalCount: total}
+	if backwards {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+		page.HasPreviousPage = more
+		page.HasNextPage = args.Before != nil
+	} else {
+		page.HasNextPage = more
+		page.HasPreviousPage = args.After != nil
+	}
+	return page, nil
+}
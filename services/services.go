@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/repository"
+)
+
+// UserService holds the rules for user accounts. REST, GraphQL and gRPC
+// only map their DTOs to these calls.
+type UserService interface {
+	Register(ctx context.Context, req models.RegisterRequest) (*models.User, error)
+	Login(ctx context.Context, req models.LoginRequest) (*models.User, string, error)
+
+	GetUser(ctx context.Context, id uint) (*models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	GetUsersByIDs(ctx context.Context, ids []uint) ([]*models.User, error)
+	GetUsersByUsernames(ctx context.Context, usernames []string) ([]*models.User, error)
+	ListUsers(ctx context.Context, args PageArgs) (*Page[*models.User], error)
+	ListAllUsers(ctx context.Context) ([]*models.User, error)
+	SearchUsers(ctx context.Context, query string, args PageArgs) (*Page[*models.User], error)
+
+	UpdateUser(ctx context.Context, id uint, req models.UpdateUserRequest) (*models.User, error)
+	ChangePassword(ctx context.Context, id uint, req models.ChangePasswordRequest) error
+	DeleteUser(ctx context.Context, id uint) error
+	RestoreAccount(ctx context.Context, id uint, password string) (*models.User, error)
+	EraseAccount(ctx context.Context, id uint, password string) (*models.ErasureReceipt, error)
+
+	ListUserChanges(ctx context.Context, after uint64, limit int) ([]*models.UserChange, error)
+	LatestUserChange(ctx context.Context) (uint64, error)
+}
+
+// FollowService holds the rules for the follow graph
+type FollowService interface {
+	Follow(ctx context.Context, followerID, followedID uint) (*models.Follower, bool, error)
+	Unfollow(ctx context.Context, followerID, followedID uint) (bool, error)
+
+	GetFollower(ctx context.Context, id uint) (*models.Follower, error)
+	GetFollowRelationship(ctx context.Context, followerID, followedID uint) (*models.Follower, error)
+	ListFollowers(ctx context.Context, userID uint) ([]*models.Follower, error)
+	ListFollowing(ctx context.Context, userID uint) ([]*models.Follower, error)
+	ListFollowersPage(ctx context.Context, userID uint, args PageArgs) (*Page[*models.Follower], error)
+	ListFollowingPage(ctx context.Context, userID uint, args PageArgs) (*Page[*models.Follower], error)
+	AttachUsers(ctx context.Context, edges []*models.Follower) error
+
+	CheckFollows(ctx context.Context, pairs []FollowPair) (map[FollowPair]bool, error)
+	FollowerIDs(ctx context.Context, userID uint) ([]uint, error)
+	EachFollowerIDs(ctx context.Context, userID uint, chunkSize int, fn func(ids []uint) error) error
+	CountFollowersByUsers(ctx context.Context, userIDs []uint) (map[uint]int, error)
+	CountFollowingByUsers(ctx context.Context, userIDs []uint) (map[uint]int, error)
+}
+
+// AdminService holds the operator actions on accounts. Every call needs an
+// admin or internal caller and is written to the audit log.
+type AdminService interface {
+	CreateUser(ctx context.Context, req models.RegisterRequest, role string) (*models.User, error)
+	SetPassword(ctx context.Context, id uint, password string) error
+	SetRole(ctx context.Context, id uint, role string) (*models.User, error)
+	DisableUser(ctx context.Context, id uint) (*models.User, error)
+	RestoreUser(ctx context.Context, id uint) (*models.User, error)
+	PurgeUser(ctx context.Context, id uint) error
+	EraseUser(ctx context.Context, id uint) (*models.ErasureReceipt, error)
+	PurgeDeletedUsers(ctx context.Context) (purged int, err error)
+	RebuildFollowerCounts(ctx context.Context) (removed int, err error)
+}
+
+// IdempotencyService remembers the responses of write RPCs made with an
+// idempotency key
+type IdempotencyService interface {
+	Claim(ctx context.Context, scope, key, method string, requestHash []byte, ttl, lease time.Duration) (*models.IdempotencyKey, bool, error)
+	Complete(ctx context.Context, id uint, response []byte, userID uint) error
+	Release(ctx context.Context, id uint) error
+	DeleteExpired(ctx context.Context, ttl time.Duration) (int, error)
+}
+
+// FollowPair identifies a (possible) follow edge
+type FollowPair = repository.FollowPair
+
+// Services is what the transports are built with
+type Services struct {
+	Users       UserService
+	Follows     FollowService
+	Admin       AdminService
+	Idempotency IdempotencyService
+}
+
+// DefaultDeletionGracePeriod is used when Config leaves it out
+const DefaultDeletionGracePeriod = 30 * 24 * time.Hour
+
+// Config tunes the business rules
+type Config struct {
+	// How long a deleted account can be restored by its owner before it
+	// is purged
+	DeletionGracePeriod time.Duration
+}
+
+// New returns the services over store
+func New(store repository.Store, cfg Config) *Services {
+	if cfg.DeletionGracePeriod == 0 {
+		cfg.DeletionGracePeriod = DefaultDeletionGracePeriod
+	}
+
+	users := &userService{store: store, gracePeriod: cfg.DeletionGracePeriod}
+	return &Services{
+		Users:       users,
+		Follows:     &followService{store: store, users: users},
+		Admin:       &adminService{store: store, users: users},
+		Idempotency: &idempotencyService{store: store},
+	}
+}
@@ -0,0 +1,386 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/antoniocfetngnu/users-api/events"
+	"github.com/antoniocfetngnu/users-api/models"
+	"github.com/antoniocfetngnu/users-api/repository"
+	"github.com/antoniocfetngnu/users-api/utils"
+)
+
+type userService struct {
+	store       repository.Store
+	gracePeriod time.Duration
+}
+
+// Register creates a new user account
+func (s *userService) Register(ctx context.Context, req models.RegisterRequest) (*models.User, error) {
+	return s.create(ctx, req, models.RoleUser, nil)
+}
+
+// create registers an account with the given role. inTx runs in the
+// transaction that creates it.
+func (s *userService) create(ctx context.Context, req models.RegisterRequest, role string, inTx func(tx repository.Store, user *models.User) error) (*models.User, error) {
+	if err := validate(&req); err != nil {
+		return nil, err
+	}
+	if reservedUsername(req.Username) {
+		return nil, ErrReservedUsername
+	}
+
+	// Check if user already exists
+	taken, err := s.store.Users().Taken(ctx, req.Username, req.Email, 0)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrUserExists
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := models.User{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Email:     req.Email,
+		Username:  req.Username,
+		Password:  hashedPassword,
+		Role:      role,
+	}
+
+	var change *models.UserChange
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Users().Create(ctx, &user); err != nil {
+			return err
+		}
+		if change, err = tx.UserChanges().Append(ctx, user.ID, models.UserChangeCreated); err != nil || inTx == nil {
+			return err
+		}
+		return inTx(tx, &user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	publishUserChange(events.UserCreated, change, &user)
+	return &user, nil
+}
+
+// Login verifies the credentials and returns the user with a signed JWT
+func (s *userService) Login(ctx context.Context, req models.LoginRequest) (*models.User, string, error) {
+	if err := validate(&req); err != nil {
+		return nil, "", err
+	}
+
+	user, err := s.store.Users().GetByUsername(ctx, req.Username)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, "", ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := utils.CheckPassword(user.Password, req.Password); err != nil {
+		return nil, "", ErrInvalidCredentials
+	}
+	if user.DisabledAt != nil {
+		return nil, "", ErrAccountDisabled
+	}
+
+	token, err := utils.GenerateJWT(user.ID, user.Username, user.Email, user.Role)
+	if err != nil {
+		return nil, "", err
+	}
+	return user, token, nil
+}
+
+// GetUser returns a user by ID
+func (s *userService) GetUser(ctx context.Context, id uint) (*models.User, error) {
+	user, err := s.store.Users().Get(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+	redact(ctx, user)
+	return user, nil
+}
+
+// GetUserByUsername returns a user by username
+func (s *userService) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	user, err := s.store.Users().GetByUsername(ctx, username)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+	redact(ctx, user)
+	return user, nil
+}
+
+// GetUserByEmail returns a user by email. Only callers who can see the
+// email may look it up, anyone else would learn it is registered.
+func (s *userService) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	user, err := s.store.Users().GetByEmail(ctx, email)
+	if err != nil || !CanAccessUser(ctx, user.ID) {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+	return user, nil
+}
+
+// GetUsersByIDs returns the users with the given IDs (in no particular
+// order, missing IDs are skipped)
+func (s *userService) GetUsersByIDs(ctx context.Context, ids []uint) ([]*models.User, error) {
+	users, err := s.store.Users().GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	redact(ctx, users...)
+	return users, nil
+}
+
+// GetUsersByUsernames returns the users with the given usernames (in no
+// particular order, unknown usernames are skipped)
+func (s *userService) GetUsersByUsernames(ctx context.Context, usernames []string) ([]*models.User, error) {
+	users, err := s.store.Users().GetByUsernames(ctx, usernames)
+	if err != nil {
+		return nil, err
+	}
+	redact(ctx, users...)
+	return users, nil
+}
+
+// ListUsers returns a page of all users
+func (s *userService) ListUsers(ctx context.Context, args PageArgs) (*Page[*models.User], error) {
+	return s.SearchUsers(ctx, "", args)
+}
+
+// ListAllUsers returns every user ordered by ID, for the REST list when no
+// page is asked for
+func (s *userService) ListAllUsers(ctx context.Context) ([]*models.User, error) {
+	users, _, err := s.store.Users().List(ctx, "", repository.Window{})
+	if err != nil {
+		return nil, err
+	}
+	redact(ctx, users...)
+	return users, nil
+}
+
+// SearchUsers returns a page of users whose name or username contains query
+// (case-insensitive)
+func (s *userService) SearchUsers(ctx context.Context, query string, args PageArgs) (*Page[*models.User], error) {
+	page, err := paginate(args, func(w repository.Window) ([]*models.User, int, error) {
+		return s.store.Users().List(ctx, query, w)
+	})
+	if err != nil {
+		return nil, err
+	}
+	redact(ctx, page.Items...)
+	return page, nil
+}
+
+// UpdateUser applies the non-nil fields of req to the user
+func (s *userService) UpdateUser(ctx context.Context, id uint, req models.UpdateUserRequest) (*models.User, error) {
+	if err := authorizeUser(ctx, id); err != nil {
+		return nil, err
+	}
+
+	user, err := s.store.Users().Get(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+
+	if req.FirstName != nil {
+		user.FirstName = *req.FirstName
+	}
+	if req.LastName != nil {
+		user.LastName = *req.LastName
+	}
+	if req.Email != nil {
+		user.Email = *req.Email
+	}
+	if req.Username != nil {
+		if reservedUsername(*req.Username) {
+			return nil, ErrReservedUsername
+		}
+		user.Username = *req.Username
+	}
+	if req.Password != nil {
+		hashedPassword, err := utils.HashPassword(*req.Password)
+		if err != nil {
+			return nil, err
+		}
+		user.Password = hashedPassword
+	}
+
+	// Username and email must stay unique
+	if req.Email != nil || req.Username != nil {
+		taken, err := s.store.Users().Taken(ctx, user.Username, user.Email, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, ErrUserExists
+		}
+	}
+
+	var change *models.UserChange
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Users().Update(ctx, user); err != nil {
+			return err
+		}
+		change, err = tx.UserChanges().Append(ctx, user.ID, models.UserChangeUpdated)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	publishUserChange(events.UserUpdated, change, user)
+	return user, nil
+}
+
+// ChangePassword replaces the password after checking the current one
+func (s *userService) ChangePassword(ctx context.Context, id uint, req models.ChangePasswordRequest) error {
+	if err := authorizeUser(ctx, id); err != nil {
+		return err
+	}
+	if err := validate(&req); err != nil {
+		return err
+	}
+
+	user, err := s.store.Users().Get(ctx, id)
+	if err != nil {
+		return notFound(err, ErrUserNotFound)
+	}
+
+	if err := utils.CheckPassword(user.Password, req.CurrentPassword); err != nil {
+		return ErrWrongPassword
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+	return s.store.Users().Update(ctx, user)
+}
+
+// DeleteUser soft-deletes a user and removes their follow edges. The owner
+// can restore the account during the grace period, without the edges;
+// PurgeDeletedUsers removes it for good afterwards.
+func (s *userService) DeleteUser(ctx context.Context, id uint) error {
+	if err := authorizeUser(ctx, id); err != nil {
+		return err
+	}
+
+	user, err := s.store.Users().Get(ctx, id)
+	if err != nil {
+		return notFound(err, ErrUserNotFound)
+	}
+
+	var change *models.UserChange
+	var edges []*models.Follower
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Users().Delete(ctx, user.ID); err != nil {
+			return err
+		}
+		if change, err = tx.UserChanges().Append(ctx, user.ID, models.UserChangeDeleted); err != nil {
+			return err
+		}
+		edges, err = removeEdges(ctx, tx, func() ([]*models.Follower, error) { return tx.Follows().DeleteByUser(ctx, user.ID) })
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	publishUserChange(events.UserDeleted, change, user)
+	publishFollowersRemoved(edges...)
+	return nil
+}
+
+// RestoreAccount undoes the deletion of an account during the grace
+// period. Callers without a session prove they own it with the password.
+func (s *userService) RestoreAccount(ctx context.Context, id uint, password string) (*models.User, error) {
+	user, err := s.store.Users().GetWithDeleted(ctx, id)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	// Don't tell strangers which accounts exist
+	if !CanAccessUser(ctx, id) {
+		if password == "" {
+			return nil, ErrUnauthenticated
+		}
+		if user == nil || utils.CheckPassword(user.Password, password) != nil {
+			return nil, ErrInvalidCredentials
+		}
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if !user.DeletedAt.Valid {
+		return nil, ErrNotDeleted
+	}
+	if user.ErasedAt != nil {
+		return nil, ErrAccountErased
+	}
+	if time.Since(user.DeletedAt.Time) > s.gracePeriod {
+		return nil, ErrRestoreExpired
+	}
+	if err := s.undelete(ctx, user, nil); err != nil {
+		return nil, err
+	}
+	redact(ctx, user)
+	return user, nil
+}
+
+// undelete restores a soft-deleted user, who shows up in the change log as
+// created again. inTx runs in the same transaction.
+func (s *userService) undelete(ctx context.Context, user *models.User, inTx func(tx repository.Store) error) error {
+	// Someone may have taken the username or email in the meantime
+	taken, err := s.store.Users().Taken(ctx, user.Username, user.Email, user.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrUserExists
+	}
+
+	var change *models.UserChange
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Users().Restore(ctx, user.ID); err != nil {
+			return err
+		}
+		if change, err = tx.UserChanges().Append(ctx, user.ID, models.UserChangeCreated); err != nil || inTx == nil {
+			return err
+		}
+		return inTx(tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	user.DeletedAt.Valid = false
+	publishUserChange(events.UserCreated, change, user)
+	return nil
+}
+
+// notFound maps repository.ErrNotFound to the given domain error
+func notFound(err error, domainErr *Error) error {
+	if err == nil || errors.Is(err, repository.ErrNotFound) {
+		return domainErr
+	}
+	return err
+}
+
+// reservedUsername tells whether username looks like the tombstone of an
+// erased account, see models.TombstoneUsername
+func reservedUsername(username string) bool {
+	return strings.HasPrefix(strings.ToLower(username), models.TombstonePrefix)
+}
@@ -0,0 +1,13 @@
+package services
+
+import "github.com/gin-gonic/gin/binding"
+
+// validate checks the `binding` tags of a request DTO with the same
+// validator gin uses for ShouldBindJSON, so GraphQL and gRPC inputs are held
+// to the same rules as REST bodies
+func validate(req any) error {
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return Invalid(err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthCookieName is the cookie carrying the JWT (validated upstream by Kong)
+const AuthCookieName = "auth_token"
+
+// SetAuthCookie sets the HTTP-only auth cookie (for login)
+func SetAuthCookie(c *gin.Context, token string) {
+	// SameSite must be set before the cookie is written
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(
+		AuthCookieName, // name
+		token,          // value
+		86400,          // maxAge (24 hours in seconds)
+		"/",            // path
+		"",             // domain
+		false,          // secure (true in production with HTTPS)
+		true,           // httpOnly
+	)
+}
+
+// ClearAuthCookie deletes the auth cookie (for logout)
+func ClearAuthCookie(c *gin.Context) {
+	c.SetCookie(
+		AuthCookieName,
+		"",
+		-1, // maxAge -1 deletes the cookie
+		"/",
+		"",
+		false,
+		true,
+	)
+}
@@ -15,6 +15,7 @@ type JWTClaims struct {
 	UserID   uint   `json:"sub"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	Role     string `json:"role"`
 	jwt.RegisteredClaims
 }
 
@@ -25,11 +26,12 @@ func InitJWT(c *config.Config) {
 }
 
 // GenerateJWT generates a new JWT token (for login)
-func GenerateJWT(userID uint, username, email string) (string, error) {
+func GenerateJWT(userID uint, username, email, role string) (string, error) {
 	claims := JWTClaims{
 		UserID:   userID,
 		Username: username,
 		Email:    email,
+		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   fmt.Sprintf("%d", userID), // CRITICAL: Kong uses this
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
@@ -48,16 +50,16 @@ func GenerateJWT(userID uint, username, email string) (string, error) {
 
 // DecodeJWTPayload extracts user info from JWT WITHOUT verifying signature
 // (Kong already validated it, we just need to read the payload)
-func DecodeJWTPayload(tokenString string) (userID uint, username, email string, err error) {
+func DecodeJWTPayload(tokenString string) (userID uint, username, email, role string, err error) {
 	parts := strings.Split(tokenString, ".")
 	if len(parts) != 3 {
-		return 0, "", "", fmt.Errorf("invalid token format")
+		return 0, "", "", "", fmt.Errorf("invalid token format")
 	}
 
 	// Decode payload (second part)
 	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
 	if err != nil {
-		return 0, "", "", fmt.Errorf("failed to decode payload: %w", err)
+		return 0, "", "", "", fmt.Errorf("failed to decode payload: %w", err)
 	}
 
 	// Parse JSON
@@ -65,22 +67,32 @@ func DecodeJWTPayload(tokenString string) (userID uint, username, email string,
 		Sub      json.Number `json:"sub"`
 		Username string      `json:"username"`
 		Email    string      `json:"email"`
+		Role     string      `json:"role"`
 	}
 
 	if err := json.Unmarshal(payload, &claims); err != nil {
-		return 0, "", "", fmt.Errorf("failed to parse payload: %w", err)
+		return 0, "", "", "", fmt.Errorf("failed to parse payload: %w", err)
 	}
 
 	// Extract user ID from 'sub' claim
 	subStr := claims.Sub.String()
 	if subStr == "" {
-		return 0, "", "", fmt.Errorf("missing sub claim")
+		return 0, "", "", "", fmt.Errorf("missing sub claim")
 	}
 
 	var uid uint64
 	if _, err := fmt.Sscanf(subStr, "%d", &uid); err != nil {
-		return 0, "", "", fmt.Errorf("invalid sub claim: %w", err)
+		return 0, "", "", "", fmt.Errorf("invalid sub claim: %w", err)
 	}
 
-	return uint(uid), claims.Username, claims.Email, nil
+	return uint(uid), claims.Username, claims.Email, claims.Role, nil
+}
+
+// VerifyJWT checks the signature and expiry of a token issued by
+// GenerateJWT, for callers that do not go through Kong (gRPC)
+func VerifyJWT(tokenString string) error {
+	_, err := jwt.Parse(tokenString, func(*jwt.Token) (any, error) {
+		return []byte(cfg.JWTSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	return err
 }